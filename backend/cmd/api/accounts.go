@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+// ============================================
+// ADMIN ACCOUNT MANAGEMENT
+// ============================================
+
+// listAdmins returns every admin account, active or not
+func (app *application) listAdmins(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	admins, err := app.models.Admins.GetAll(r.Context(), app.institutionScope(r, claims))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"admins": admins,
+		"roles":  auth.AdminRoles(),
+	}, nil)
+}
+
+// inviteAdminInput is the body of POST /api/v1/admin/admins
+type inviteAdminInput struct {
+	Name        string  `json:"name"`
+	Email       string  `json:"email"`
+	Phone       *string `json:"phone"`
+	Designation string  `json:"designation"`
+	Role        string  `json:"role"`
+	Department  *string `json:"department"`
+}
+
+// inviteAdmin pre-registers a new admin who can then sign in with OAuth
+func (app *application) inviteAdmin(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	var input inviteAdminInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	if input.Designation == "" {
+		input.Designation = "Placement Coordinator"
+	}
+	if input.Role == "" {
+		input.Role = string(auth.RoleFacultyViewer)
+	}
+
+	v := validator.New()
+	if validateInviteAdmin(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+	if !canAssignAdminRole(claims, input.Role) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	institutionID, err := app.targetInstitution(r, claims)
+	if err != nil {
+		if errors.Is(err, errUnknownInstitution) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	admin := &models.Admin{
+		InstitutionID: institutionID,
+		Name:          input.Name,
+		Email:         input.Email,
+		Phone:         input.Phone,
+		Designation:   input.Designation,
+		Role:          input.Role,
+		Department:    input.Department,
+	}
+
+	if err := app.models.Admins.Insert(r.Context(), admin); err != nil {
+		if errors.Is(err, models.ErrDuplicateEmail) {
+			app.errorResponse(w, r, http.StatusConflict, codeDuplicateEmail, "an admin with this email already exists")
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "admin.invited", "admin", admin.ID, map[string]interface{}{
+		"email":       admin.Email,
+		"role":        admin.Role,
+		"designation": admin.Designation,
+		"department":  admin.Department,
+	})
+
+	app.writeJSON(w, http.StatusCreated, envelope{"admin": admin}, nil)
+}
+
+// updateAdminInput changes only the fields that are present
+type updateAdminInput struct {
+	Name        *string `json:"name"`
+	Phone       *string `json:"phone"`
+	Designation *string `json:"designation"`
+	Role        *string `json:"role"`
+	Department  *string `json:"department"`
+}
+
+// updateAdmin edits an admin's profile, designation, role or department
+func (app *application) updateAdmin(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	admin, err := app.manageableAdmin(r.Context(), claims, id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input updateAdminInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if validateUpdateAdmin(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	changes := map[string]interface{}{}
+
+	if input.Name != nil && *input.Name != admin.Name {
+		changes["name"] = fieldChange(admin.Name, *input.Name)
+		admin.Name = *input.Name
+	}
+	if input.Phone != nil && *input.Phone != ptrToString(admin.Phone) {
+		changes["phone"] = fieldChange(admin.Phone, *input.Phone)
+		admin.Phone = input.Phone
+	}
+	if input.Designation != nil && *input.Designation != admin.Designation {
+		changes["designation"] = fieldChange(admin.Designation, *input.Designation)
+		admin.Designation = *input.Designation
+	}
+	if input.Role != nil && *input.Role != admin.Role {
+		changes["role"] = fieldChange(admin.Role, *input.Role)
+		admin.Role = *input.Role
+	}
+	if input.Department != nil && *input.Department != ptrToString(admin.Department) {
+		changes["department"] = fieldChange(admin.Department, *input.Department)
+		admin.Department = input.Department
+	}
+
+	// Nothing to save or audit when the request repeats the current values
+	if len(changes) == 0 {
+		app.writeJSON(w, http.StatusOK, envelope{"admin": admin}, nil)
+		return
+	}
+
+	// Checked on the result, so a role change and its department can arrive separately
+	if validateAdminRole(v, admin.Role, admin.Department); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+	if !canAssignAdminRole(claims, admin.Role) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	if err := app.models.Admins.Update(r.Context(), admin); err != nil {
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			app.errorResponse(w, r, http.StatusConflict, codeLastSuperAdmin, err.Error())
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	// Role and department are baked into access tokens, so the admin must
+	// sign in again rather than keep the old permissions until expiry
+	_, roleChanged := changes["role"]
+	_, departmentChanged := changes["department"]
+	if roleChanged || departmentChanged {
+		if _, err := app.models.Sessions.RevokeAllForUser(r.Context(), "admin", admin.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.logActivity(r, claims, "admin.updated", "admin", admin.ID, changes)
+
+	app.writeJSON(w, http.StatusOK, envelope{"admin": admin}, nil)
+}
+
+// deactivateAdmin blocks an admin from signing in and ends their sessions
+func (app *application) deactivateAdmin(w http.ResponseWriter, r *http.Request) {
+	app.setAdminActive(w, r, false)
+}
+
+// reactivateAdmin restores a deactivated admin
+func (app *application) reactivateAdmin(w http.ResponseWriter, r *http.Request) {
+	app.setAdminActive(w, r, true)
+}
+
+func (app *application) setAdminActive(w http.ResponseWriter, r *http.Request, active bool) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	admin, err := app.manageableAdmin(r.Context(), claims, id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if admin.IsActive == active {
+		app.writeJSON(w, http.StatusOK, envelope{"admin": admin}, nil)
+		return
+	}
+
+	admin.IsActive = active
+	if err := app.models.Admins.Update(r.Context(), admin); err != nil {
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			app.errorResponse(w, r, http.StatusConflict, codeLastSuperAdmin, err.Error())
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	action := "admin.reactivated"
+	if !active {
+		action = "admin.deactivated"
+
+		// A deactivated admin must lose access right away, not when their token expires
+		if _, err := app.models.Sessions.RevokeAllForUser(r.Context(), "admin", admin.ID); err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	app.logActivity(r, claims, action, "admin", admin.ID, map[string]interface{}{
+		"is_active": fieldChange(!active, active),
+	})
+
+	app.writeJSON(w, http.StatusOK, envelope{"admin": admin}, nil)
+}
+
+// getAdminAuditLog returns recent changes made to admin accounts
+func (app *application) getAdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	limit := app.readInt(r.URL.Query(), "limit", 50)
+	logs, err := app.models.Activity.GetByEntityType(r.Context(), app.institutionScope(r, claims), "admin", limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"audit_log": logs}, nil)
+}
+
+// manageableAdmin loads an admin account the caller may manage. Admins of other
+// institutions, and group admins for anyone but another group admin, are
+// reported as not found.
+func (app *application) manageableAdmin(ctx context.Context, claims *auth.Claims, id int64) (*models.Admin, error) {
+	admin, err := app.models.Admins.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !app.inInstitution(claims, admin.InstitutionID) || !canAssignAdminRole(claims, admin.Role) {
+		return nil, models.ErrRecordNotFound
+	}
+	return admin, nil
+}
+
+// canAssignAdminRole reports whether the caller may grant a role; only group
+// admins can create other group admins
+func canAssignAdminRole(claims *auth.Claims, role string) bool {
+	return auth.AdminRole(role) != auth.RoleGroupAdmin || claims.Can(auth.PermInstitutionsAll)
+}
+
+// fieldChange describes a single field edit for the audit log
+func fieldChange(from, to interface{}) map[string]interface{} {
+	return map[string]interface{}{"from": from, "to": to}
+}
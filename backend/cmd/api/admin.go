@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
 	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
 )
 
 // ============================================
@@ -16,17 +21,20 @@ import (
 
 // getDashboard returns admin dashboard data
 func (app *application) getDashboard(w http.ResponseWriter, r *http.Request) {
-	claims, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	// Get admin info
-	admin, err := app.models.Admins.GetByID(claims.UserID)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
+	// Get admin info; API keys have no admin account behind them
+	var admin *models.Admin
+	if claims.Role == "admin" {
+		admin, err = app.models.Admins.GetByID(r.Context(), claims.UserID)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
 	}
 
 	// Parse optional batch filter
@@ -39,66 +47,70 @@ func (app *application) getDashboard(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get dashboard stats
-	stats, err := app.models.Analytics.GetDashboardStats(batchYear)
+	// Get dashboard stats; department coordinators see their own department only
+	institution := app.institutionScope(r, claims)
+	department := app.departmentScope(claims)
+
+	stats, err := app.models.Analytics.GetDashboardStats(r.Context(), institution, department, batchYear)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Get recent activity
-	activity, _ := app.models.Analytics.GetRecentActivity(10)
+	activity, _ := app.models.Analytics.GetRecentActivity(r.Context(), institution, department, 10)
 
 	// Get batches
-	batches, _ := app.models.Analytics.GetBatches()
+	batches, _ := app.models.Analytics.GetBatches(r.Context(), institution)
 
-	app.writeJSON(w, http.StatusOK, envelope{
+	// Tagged on the whole body, so new activity also counts as a change
+	app.writeJSONWithETag(w, r, envelope{
 		"admin":    admin,
 		"stats":    stats,
 		"activity": activity,
 		"batches":  batches,
-	}, nil)
+	})
 }
 
 // getBatchStats returns batch-wise statistics
 func (app *application) getBatchStats(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	stats, err := app.models.Analytics.GetBatchWiseStats()
+	stats, err := app.models.Analytics.GetBatchWiseStats(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims))
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{"batch_stats": stats}, nil)
+	app.writeJSONWithETag(w, r, envelope{"batch_stats": stats})
 }
 
 // getSkillStats returns skill distribution statistics
 func (app *application) getSkillStats(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	stats, err := app.models.Analytics.GetSkillStats()
+	stats, err := app.models.Analytics.GetSkillStats(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims))
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{"skill_stats": stats}, nil)
+	app.writeJSONWithETag(w, r, envelope{"skill_stats": stats})
 }
 
 // getCGPADistribution returns CGPA distribution
 func (app *application) getCGPADistribution(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -111,20 +123,20 @@ func (app *application) getCGPADistribution(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	stats, err := app.models.Analytics.GetCGPADistribution(batchYear)
+	stats, err := app.models.Analytics.GetCGPADistribution(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims), batchYear)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{"cgpa_distribution": stats}, nil)
+	app.writeJSONWithETag(w, r, envelope{"cgpa_distribution": stats})
 }
 
 // getCompanyStats returns placement statistics by company
 func (app *application) getCompanyStats(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -137,25 +149,25 @@ func (app *application) getCompanyStats(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	stats, err := app.models.Analytics.GetCompanyStats(batchYear)
+	stats, err := app.models.Analytics.GetCompanyStats(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims), batchYear)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{"company_stats": stats}, nil)
+	app.writeJSONWithETag(w, r, envelope{"company_stats": stats})
 }
 
 // getRecentActivity returns recent activities
 func (app *application) getRecentActivity(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
 	limit := app.readInt(r.URL.Query(), "limit", 20)
-	activities, err := app.models.Analytics.GetRecentActivity(limit)
+	activities, err := app.models.Analytics.GetRecentActivity(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims), limit)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -170,18 +182,19 @@ func (app *application) getRecentActivity(w http.ResponseWriter, r *http.Request
 
 // listStudents returns paginated list of students with filters
 func (app *application) listStudents(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
 	qs := r.URL.Query()
 
 	filter := models.StudentFilter{
-		Search:   app.readString(qs, "search", ""),
-		Page:     app.readInt(qs, "page", 1),
-		PageSize: app.readInt(qs, "page_size", 20),
+		InstitutionID: app.institutionScope(r, claims),
+		Search:        app.readString(qs, "search", ""),
+		Page:          app.readInt(qs, "page", 1),
+		PageSize:      app.readInt(qs, "page_size", 20),
 	}
 
 	// Batch filter
@@ -209,7 +222,15 @@ func (app *application) listStudents(w http.ResponseWriter, r *http.Request) {
 	// Backlog filter
 	filter.HasBacklogs = app.readBool(qs, "has_backlogs")
 
-	result, err := app.models.Students.List(filter)
+	// Department filter - scoped roles are always limited to their own department
+	if department := qs.Get("department"); department != "" {
+		filter.Department = &department
+	}
+	if scope := app.departmentScope(claims); scope != nil {
+		filter.Department = scope
+	}
+
+	result, err := app.models.Students.List(r.Context(), filter)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -226,9 +247,9 @@ func (app *application) listStudents(w http.ResponseWriter, r *http.Request) {
 
 // getStudentByID returns full profile of a student
 func (app *application) getStudentByID(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -238,7 +259,7 @@ func (app *application) getStudentByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	profile, err := app.models.Students.GetFullProfile(id)
+	profile, err := app.models.Students.GetFullProfile(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.notFoundResponse(w, r)
@@ -248,8 +269,13 @@ func (app *application) getStudentByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !app.canAccessStudent(claims, &profile.Student) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
 	// Get placement info
-	placement, _ := app.models.Placements.GetByStudentID(id)
+	placement, _ := app.models.Placements.GetByStudentID(r.Context(), id)
 	profile.Placement = placement
 
 	app.writeJSON(w, http.StatusOK, envelope{"profile": profile}, nil)
@@ -257,9 +283,9 @@ func (app *application) getStudentByID(w http.ResponseWriter, r *http.Request) {
 
 // getStudentByRollNo returns student by roll number
 func (app *application) getStudentByRollNo(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -269,7 +295,7 @@ func (app *application) getStudentByRollNo(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	student, err := app.models.Students.GetByRollNo(rollNo)
+	student, err := app.models.Students.GetByRollNo(r.Context(), app.institutionScope(r, claims), rollNo)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.notFoundResponse(w, r)
@@ -279,24 +305,36 @@ func (app *application) getStudentByRollNo(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	profile, err := app.models.Students.GetFullProfile(student.ID)
+	if !app.canAccessStudent(claims, student) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	profile, err := app.models.Students.GetFullProfile(r.Context(), student.ID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Get placement info
-	placement, _ := app.models.Placements.GetByStudentID(student.ID)
+	placement, _ := app.models.Placements.GetByStudentID(r.Context(), student.ID)
 	profile.Placement = placement
 
 	app.writeJSON(w, http.StatusOK, envelope{"profile": profile}, nil)
 }
 
+// studentStatusInput accepts the status as either status or placement_status
+type studentStatusInput struct {
+	Status                 string `json:"status"`
+	PlacementStatus        string `json:"placement_status"`
+	IsEligibleForPlacement *bool  `json:"is_eligible_for_placement"`
+}
+
 // updateStudentStatus updates student placement status
 func (app *application) updateStudentStatus(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermStudentsWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -306,16 +344,33 @@ func (app *application) updateStudentStatus(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	var input struct {
-		Status                 string `json:"status"`
-		PlacementStatus        string `json:"placement_status"`
-		IsEligibleForPlacement *bool  `json:"is_eligible_for_placement"`
+	student, err := app.models.Students.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.canAccessStudent(claims, student) {
+		app.notFoundResponse(w, r)
+		return
 	}
+
+	var input studentStatusInput
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
+	v := validator.New()
+	if validateStudentStatus(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
 	// Accept both "status" and "placement_status" fields
 	statusStr := input.Status
 	if statusStr == "" {
@@ -324,7 +379,7 @@ func (app *application) updateStudentStatus(w http.ResponseWriter, r *http.Reque
 
 	if statusStr != "" {
 		status := models.PlacementStatus(statusStr)
-		if err := app.models.Students.UpdatePlacementStatus(id, status); err != nil {
+		if err := app.models.Students.UpdatePlacementStatus(r.Context(), id, status); err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
@@ -333,18 +388,107 @@ func (app *application) updateStudentStatus(w http.ResponseWriter, r *http.Reque
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Student status updated"}, nil)
 }
 
+// studentDepartmentInput is the body of PUT /api/v1/admin/students/{id}/department
+type studentDepartmentInput struct {
+	Department string `json:"department"`
+}
+
+// updateStudentDepartment moves a student to another department. Students
+// cannot set their own department, since it decides which department
+// coordinators see them, and department coordinators cannot move students
+// out of their own view.
+func (app *application) updateStudentDepartment(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermStudentsWrite)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+	if app.departmentScope(claims) != nil {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	student, err := app.models.Students.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.canAccessStudent(claims, student) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var input studentDepartmentInput
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if validateStudentDepartment(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	department := strings.TrimSpace(input.Department)
+	previous := student.Department
+	student.Department = &department
+
+	if err := app.models.Students.UpdateBasicInfo(r.Context(), student); err != nil {
+		if errors.Is(err, models.ErrEditConflict) {
+			app.editConflictResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "student.department_changed", "student", student.ID, map[string]interface{}{
+		"department": fieldChange(previous, department),
+	})
+
+	app.writeJSON(w, http.StatusOK, envelope{"student": student}, nil)
+}
+
 // exportStudentsCSV exports student data as CSV
 func (app *application) exportStudentsCSV(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	result, err := app.models.Students.Export(r.Context(), app.exportFilter(r, claims))
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	// Set CSV headers
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=students_export.csv")
+
+	if err := writeStudentsCSV(w, result.Students); err != nil {
+		app.logger.ErrorContext(r.Context(), "writing CSV export failed", "error", err)
+	}
+}
+
+// exportFilter reads the export's query string filters within the caller's scope
+func (app *application) exportFilter(r *http.Request, claims *auth.Claims) models.StudentFilter {
 	qs := r.URL.Query()
 	filter := models.StudentFilter{
-		Page:     1,
-		PageSize: 10000, // Export all
+		InstitutionID: app.institutionScope(r, claims),
 	}
 
 	// Apply filters
@@ -358,35 +502,32 @@ func (app *application) exportStudentsCSV(w http.ResponseWriter, r *http.Request
 		ps := models.PlacementStatus(status)
 		filter.PlacementStatus = &ps
 	}
-
-	result, err := app.models.Students.List(filter)
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
+	if scope := app.departmentScope(claims); scope != nil {
+		filter.Department = scope
 	}
+	return filter
+}
 
-	// Set CSV headers
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment; filename=students_export.csv")
-
+// writeStudentsCSV writes the export's header and one row per student
+func writeStudentsCSV(w io.Writer, students []models.StudentListItem) error {
 	writer := csv.NewWriter(w)
-	defer writer.Flush()
 
 	// Write header row
 	header := []string{
-		"ID", "Name", "Email", "Roll No", "Batch", "Profile Completed",
+		"ID", "Name", "Email", "Roll No", "Batch", "Department", "Profile Completed",
 		"Placement Status", "CGPA", "Mobile", "Placed Company", "Package (LPA)",
 	}
 	writer.Write(header)
 
 	// Write data rows
-	for _, s := range result.Students {
+	for _, s := range students {
 		row := []string{
 			fmt.Sprintf("%d", s.ID),
 			s.Name,
 			s.OfficialEmail,
 			ptrToString(s.RollNo),
 			ptrIntToString(s.BatchYear),
+			ptrToString(s.Department),
 			fmt.Sprintf("%t", s.IsProfileCompleted),
 			string(s.PlacementStatus),
 			ptrFloatToString(s.CGPAOverall),
@@ -396,67 +537,203 @@ func (app *application) exportStudentsCSV(w http.ResponseWriter, r *http.Request
 		}
 		writer.Write(row)
 	}
+
+	writer.Flush()
+	return writer.Error()
 }
 
 // ============================================
-// PLACEMENT MANAGEMENT
+// SESSION MANAGEMENT
 // ============================================
 
-// listPlacements returns all placement records
-func (app *application) listPlacements(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+// revokeStudentSessions signs a student out of every device
+func (app *application) revokeStudentSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermStudentsWrite)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	placements, err := app.models.Placements.GetAll()
+	student, err := app.models.Students.GetByID(r.Context(), id)
 	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{"placements": placements}, nil)
+	if !app.canAccessStudent(claims, student) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	app.revokeUserSessions(w, r, claims, "student", id)
 }
 
-// createPlacement creates a new placement record
-func (app *application) createPlacement(w http.ResponseWriter, r *http.Request) {
-	claims, err := app.authenticateAdmin(r)
+// impersonateStudent mints a short-lived, read-only token that lets an admin
+// see the app exactly as the student does. Every request made with it is
+// recorded in activity_logs under the admin.
+func (app *application) impersonateStudent(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermStudentsImpersonate)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	var input struct {
-		StudentID   int64    `json:"student_id"`
-		CompanyID   *int64   `json:"company_id"`
-		CompanyName string   `json:"company_name"`
-		JobRole     *string  `json:"job_role"`
-		PackageLPA  *float64 `json:"package_lpa"`
-		PackageCTC  *string  `json:"package_ctc"`
-		JoiningDate *string  `json:"joining_date"`
-		OfferDate   *string  `json:"offer_date"`
-		OfferType   *string  `json:"offer_type"`
-		JobLocation *string  `json:"job_location"`
-		Remarks     *string  `json:"remarks"`
+	// The token is tied to the admin's own session, which API keys do not have
+	if claims.Role != "admin" {
+		app.forbiddenResponse(w, r)
+		return
 	}
 
-	if err := app.readJSON(w, r, &input); err != nil {
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	// Validate student exists
-	_, err = app.models.Students.GetByID(input.StudentID)
+	student, err := app.models.Students.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
-			app.badRequestResponse(w, r, errors.New("student not found"))
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if !app.canAccessStudent(claims, student) {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	actor := auth.Actor{
+		Subject: fmt.Sprintf("admin:%d", claims.UserID),
+		AdminID: claims.UserID,
+		Email:   claims.Email,
+	}
+
+	token, err := app.jwtService.GenerateImpersonationToken(studentTokenUser(student), actor, claims.SessionID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "student.impersonated", "student", student.ID, map[string]interface{}{
+		"expires_in": int(app.jwtService.ImpersonationTokenTTL.Seconds()),
+	})
+
+	app.writeJSON(w, http.StatusCreated, envelope{
+		"access_token":  token,
+		"token_type":    "Bearer",
+		"expires_in":    int(app.jwtService.ImpersonationTokenTTL.Seconds()),
+		"read_only":     true,
+		"impersonating": student,
+	}, nil)
+}
+
+// revokeAdminSessions signs an admin out of every device
+func (app *application) revokeAdminSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if _, err := app.manageableAdmin(r.Context(), claims, id); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
 			return
 		}
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
+	app.revokeUserSessions(w, r, claims, "admin", id)
+}
+
+func (app *application) revokeUserSessions(w http.ResponseWriter, r *http.Request, claims *auth.Claims, userType string, id int64) {
+	revoked, err := app.models.Sessions.RevokeAllForUser(r.Context(), userType, id)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "sessions.revoked", userType, id, map[string]interface{}{
+		"revoked_sessions": revoked,
+	})
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"message":          "Sessions revoked",
+		"revoked_sessions": revoked,
+	}, nil)
+}
+
+// ============================================
+// PLACEMENT MANAGEMENT
+// ============================================
+
+// listPlacements returns all placement records
+func (app *application) listPlacements(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermPlacementsRead)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	placements, err := app.models.Placements.GetAll(r.Context(), app.institutionScope(r, claims), app.departmentScope(claims))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"placements": placements}, nil)
+}
+
+// createPlacementInput is the body of POST /api/v1/admin/placements
+type createPlacementInput struct {
+	StudentID   int64    `json:"student_id"`
+	CompanyID   *int64   `json:"company_id"`
+	CompanyName string   `json:"company_name"`
+	JobRole     *string  `json:"job_role"`
+	PackageLPA  *float64 `json:"package_lpa"`
+	PackageCTC  *string  `json:"package_ctc"`
+	JoiningDate *string  `json:"joining_date"`
+	OfferDate   *string  `json:"offer_date"`
+	OfferType   *string  `json:"offer_type"`
+	JobLocation *string  `json:"job_location"`
+	Remarks     *string  `json:"remarks"`
+}
+
+// createPlacement creates a new placement record
+func (app *application) createPlacement(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermPlacementsWrite)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	var input createPlacementInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
 	placement := &models.PlacementRecord{
 		StudentID:   input.StudentID,
 		CompanyID:   input.CompanyID,
@@ -464,33 +741,82 @@ func (app *application) createPlacement(w http.ResponseWriter, r *http.Request)
 		JobRole:     input.JobRole,
 		PackageLPA:  input.PackageLPA,
 		PackageCTC:  input.PackageCTC,
-		JoiningDate: input.JoiningDate,
-		OfferDate:   input.OfferDate,
+		JoiningDate: nilIfBlank(input.JoiningDate),
+		OfferDate:   nilIfBlank(input.OfferDate),
 		OfferType:   input.OfferType,
 		JobLocation: input.JobLocation,
 		Remarks:     input.Remarks,
 		IsAccepted:  true,
-		VerifiedBy:  &claims.UserID,
 	}
 
-	if err := app.models.Placements.Insert(placement); err != nil {
+	v := validator.New()
+	v.Check(input.StudentID > 0, "student_id", "must be provided")
+	if validatePlacement(v, placement); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	// Validate student exists
+	student, err := app.models.Students.GetByID(r.Context(), input.StudentID)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.badRequestResponse(w, r, errors.New("student not found"))
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	if !app.canAccessStudent(claims, student) {
+		app.badRequestResponse(w, r, errors.New("student not found"))
+		return
+	}
+
+	if err := app.checkPlacementCompany(r.Context(), input.CompanyID, student.InstitutionID); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.badRequestResponse(w, r, errors.New("company not found"))
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if claims.Role == "admin" {
+		placement.VerifiedBy = &claims.UserID
+	}
+
+	if err := app.models.Placements.Insert(r.Context(), placement); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Update student status
-	if err := app.models.Students.UpdatePlacementStatus(input.StudentID, models.PlacementStatusPlaced); err != nil {
-		app.logger.Printf("Warning: Failed to update student placement status: %v", err)
+	if err := app.models.Students.UpdatePlacementStatus(r.Context(), input.StudentID, models.PlacementStatusPlaced); err != nil {
+		app.logger.WarnContext(r.Context(), "failed to update student placement status", "student_id", input.StudentID, "error", err)
 	}
 
 	app.writeJSON(w, http.StatusCreated, envelope{"placement": placement}, nil)
 }
 
+// updatePlacementInput is the body of PUT /api/v1/admin/placements/{id}
+type updatePlacementInput struct {
+	CompanyID   *int64   `json:"company_id"`
+	CompanyName string   `json:"company_name"`
+	JobRole     *string  `json:"job_role"`
+	PackageLPA  *float64 `json:"package_lpa"`
+	PackageCTC  *string  `json:"package_ctc"`
+	JoiningDate *string  `json:"joining_date"`
+	OfferDate   *string  `json:"offer_date"`
+	OfferType   *string  `json:"offer_type"`
+	JobLocation *string  `json:"job_location"`
+	IsAccepted  bool     `json:"is_accepted"`
+	Remarks     *string  `json:"remarks"`
+}
+
 // updatePlacement updates a placement record
 func (app *application) updatePlacement(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermPlacementsWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -500,20 +826,18 @@ func (app *application) updatePlacement(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var input struct {
-		CompanyID   *int64   `json:"company_id"`
-		CompanyName string   `json:"company_name"`
-		JobRole     *string  `json:"job_role"`
-		PackageLPA  *float64 `json:"package_lpa"`
-		PackageCTC  *string  `json:"package_ctc"`
-		JoiningDate *string  `json:"joining_date"`
-		OfferDate   *string  `json:"offer_date"`
-		OfferType   *string  `json:"offer_type"`
-		JobLocation *string  `json:"job_location"`
-		IsAccepted  bool     `json:"is_accepted"`
-		Remarks     *string  `json:"remarks"`
+	student, err := app.placementStudent(r.Context(), claims, id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
 	}
 
+	var input updatePlacementInput
+
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -526,15 +850,30 @@ func (app *application) updatePlacement(w http.ResponseWriter, r *http.Request)
 		JobRole:     input.JobRole,
 		PackageLPA:  input.PackageLPA,
 		PackageCTC:  input.PackageCTC,
-		JoiningDate: input.JoiningDate,
-		OfferDate:   input.OfferDate,
+		JoiningDate: nilIfBlank(input.JoiningDate),
+		OfferDate:   nilIfBlank(input.OfferDate),
 		OfferType:   input.OfferType,
 		JobLocation: input.JobLocation,
 		IsAccepted:  input.IsAccepted,
 		Remarks:     input.Remarks,
 	}
 
-	if err := app.models.Placements.Update(placement); err != nil {
+	v := validator.New()
+	if validatePlacement(v, placement); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.checkPlacementCompany(r.Context(), input.CompanyID, student.InstitutionID); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.badRequestResponse(w, r, errors.New("company not found"))
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Placements.Update(r.Context(), placement); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -544,9 +883,9 @@ func (app *application) updatePlacement(w http.ResponseWriter, r *http.Request)
 
 // deletePlacement deletes a placement record
 func (app *application) deletePlacement(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermPlacementsWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -556,7 +895,16 @@ func (app *application) deletePlacement(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := app.models.Placements.Delete(id); err != nil {
+	if _, err := app.placementStudent(r.Context(), claims, id); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Placements.Delete(r.Context(), id); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -564,19 +912,55 @@ func (app *application) deletePlacement(w http.ResponseWriter, r *http.Request)
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Placement record deleted"}, nil)
 }
 
+// placementStudent loads the student a placement belongs to. Placements of
+// students the caller may not see are reported as not found.
+func (app *application) placementStudent(ctx context.Context, claims *auth.Claims, placementID int64) (*models.Student, error) {
+	studentID, err := app.models.Placements.GetStudentID(ctx, placementID)
+	if err != nil {
+		return nil, err
+	}
+
+	student, err := app.models.Students.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !app.canAccessStudent(claims, student) {
+		return nil, models.ErrRecordNotFound
+	}
+	return student, nil
+}
+
+// checkPlacementCompany makes sure a placement only links to a company of the
+// student's own institution
+func (app *application) checkPlacementCompany(ctx context.Context, companyID *int64, institutionID int64) error {
+	if companyID == nil {
+		return nil
+	}
+
+	company, err := app.models.Companies.GetByID(ctx, *companyID)
+	if err != nil {
+		return err
+	}
+	if company.InstitutionID != institutionID {
+		return models.ErrRecordNotFound
+	}
+	return nil
+}
+
 // ============================================
 // COMPANY MANAGEMENT
 // ============================================
 
 // listCompanies returns all companies
 func (app *application) listCompanies(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermCompaniesRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	companies, err := app.models.Companies.GetAll()
+	companies, err := app.models.Companies.GetAll(r.Context(), app.institutionScope(r, claims))
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -587,9 +971,9 @@ func (app *application) listCompanies(w http.ResponseWriter, r *http.Request) {
 
 // createCompany creates a new company
 func (app *application) createCompany(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermCompaniesWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -599,12 +983,23 @@ func (app *application) createCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if input.Name == "" {
-		app.badRequestResponse(w, r, errors.New("company name is required"))
+	v := validator.New()
+	if validateCompany(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
 
-	if err := app.models.Companies.Insert(&input); err != nil {
+	input.InstitutionID, err = app.targetInstitution(r, claims)
+	if err != nil {
+		if errors.Is(err, errUnknownInstitution) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Companies.Insert(r.Context(), &input); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -612,11 +1007,25 @@ func (app *application) createCompany(w http.ResponseWriter, r *http.Request) {
 	app.writeJSON(w, http.StatusCreated, envelope{"company": input}, nil)
 }
 
+// updateCompanyInput changes only the fields that are present
+type updateCompanyInput struct {
+	Name         *string `json:"name"`
+	Website      *string `json:"website"`
+	Industry     *string `json:"industry"`
+	CompanyType  *string `json:"company_type"`
+	Description  *string `json:"description"`
+	HRName       *string `json:"hr_name"`
+	HREmail      *string `json:"hr_email"`
+	HRPhone      *string `json:"hr_phone"`
+	Headquarters *string `json:"headquarters"`
+	IsActive     *bool   `json:"is_active"`
+}
+
 // updateCompany updates a company
 func (app *application) updateCompany(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermCompaniesWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -626,7 +1035,7 @@ func (app *application) updateCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	company, err := app.models.Companies.GetByID(id)
+	company, err := app.models.Companies.GetByID(r.Context(), id)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.notFoundResponse(w, r)
@@ -635,20 +1044,13 @@ func (app *application) updateCompany(w http.ResponseWriter, r *http.Request) {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
-
-	var input struct {
-		Name         *string `json:"name"`
-		Website      *string `json:"website"`
-		Industry     *string `json:"industry"`
-		CompanyType  *string `json:"company_type"`
-		Description  *string `json:"description"`
-		HRName       *string `json:"hr_name"`
-		HREmail      *string `json:"hr_email"`
-		HRPhone      *string `json:"hr_phone"`
-		Headquarters *string `json:"headquarters"`
-		IsActive     *bool   `json:"is_active"`
+	if !app.inInstitution(claims, company.InstitutionID) {
+		app.notFoundResponse(w, r)
+		return
 	}
 
+	var input updateCompanyInput
+
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
@@ -685,7 +1087,13 @@ func (app *application) updateCompany(w http.ResponseWriter, r *http.Request) {
 		company.IsActive = *input.IsActive
 	}
 
-	if err := app.models.Companies.Update(company); err != nil {
+	v := validator.New()
+	if validateCompany(v, company); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	if err := app.models.Companies.Update(r.Context(), company); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -695,9 +1103,9 @@ func (app *application) updateCompany(w http.ResponseWriter, r *http.Request) {
 
 // searchCompanies searches companies by name
 func (app *application) searchCompanies(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermCompaniesRead)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -707,7 +1115,7 @@ func (app *application) searchCompanies(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	companies, err := app.models.Companies.Search(query)
+	companies, err := app.models.Companies.Search(r.Context(), app.institutionScope(r, claims), query)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -718,9 +1126,9 @@ func (app *application) searchCompanies(w http.ResponseWriter, r *http.Request)
 
 // deleteCompany deletes a company
 func (app *application) deleteCompany(w http.ResponseWriter, r *http.Request) {
-	_, err := app.authenticateAdmin(r)
+	claims, err := app.requirePermission(r, auth.PermCompaniesWrite)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
@@ -730,7 +1138,7 @@ func (app *application) deleteCompany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = app.models.Companies.Delete(id)
+	err = app.models.Companies.Delete(r.Context(), app.institutionScope(r, claims), id)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.notFoundResponse(w, r)
@@ -754,6 +1162,15 @@ func ptrToString(s *string) string {
 	return *s
 }
 
+// nilIfBlank turns the "" a form sends for an untouched input into NULL, for
+// columns such as enums and dates where an empty string is not a valid value
+func nilIfBlank(s *string) *string {
+	if validator.Blank(s) {
+		return nil
+	}
+	return s
+}
+
 func ptrIntToString(i *int) string {
 	if i == nil {
 		return ""
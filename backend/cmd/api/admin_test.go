@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+	"github.com/gorilla/mux"
+)
+
+func TestDashboardETag(t *testing.T) {
+	app := newTaskApplication(t)
+	app.models, _ = models.WithAnalyticsCache(app.models, time.Hour)
+	ctx := t.Context()
+
+	admin := &models.Admin{InstitutionID: memstore.DefaultInstitutionID, Name: "Priya", Email: "priya@kct.ac.in", Role: string(auth.RolePlacementCoordinator)}
+	if err := app.models.Admins.Insert(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: "asha@kct.ac.in", Name: "Asha"}
+	if err := app.models.Students.Insert(ctx, student); err != nil {
+		t.Fatal(err)
+	}
+	claims := &auth.Claims{UserID: admin.ID, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := app.contextSetClaims(httptest.NewRequest(http.MethodGet, "/api/v1/admin/dashboard", nil), claims)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		app.getDashboard(rr, r)
+		return rr
+	}
+
+	rr := get("")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first load: got %d with ETag %q", rr.Code, etag)
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "private, no-cache" {
+		t.Errorf("Cache-Control: %q", cc)
+	}
+
+	for _, header := range []string{etag, "W/" + etag, `"stale", ` + etag, "*"} {
+		rr := get(header)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: got %d with %d byte body, want an empty 304", header, rr.Code, rr.Body.Len())
+		}
+		if rr.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: 304 carries ETag %q, want %q", header, rr.Header().Get("ETag"), etag)
+		}
+	}
+	if rr := get(`"stale"`); rr.Code != http.StatusOK {
+		t.Errorf("stale If-None-Match: got %d, want 200", rr.Code)
+	}
+
+	// A placement changes the stats, so the cached dashboard must not be served
+	if err := app.models.Students.UpdatePlacementStatus(ctx, student.ID, models.PlacementStatusPlaced); err != nil {
+		t.Fatal(err)
+	}
+	rr = get(etag)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("after a placement: got %d, want 200", rr.Code)
+	}
+	if rr.Header().Get("ETag") == etag {
+		t.Error("after a placement: ETag did not change")
+	}
+}
+
+func TestUpdateAdminAuditsOnlyChanges(t *testing.T) {
+	app := newTaskApplication(t)
+	ctx := t.Context()
+
+	phone := "9840012345"
+	admin := &models.Admin{InstitutionID: memstore.DefaultInstitutionID, Name: "Priya", Email: "priya@kct.ac.in", Phone: &phone, Designation: "Officer", Role: string(auth.RolePlacementCoordinator)}
+	if err := app.models.Admins.Insert(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	claims := &auth.Claims{UserID: 1, Role: "admin", AdminRole: auth.RoleSuperAdmin, InstitutionID: memstore.DefaultInstitutionID}
+
+	update := func(body string) {
+		t.Helper()
+		r := app.contextSetClaims(httptest.NewRequest(http.MethodPut, "/api/v1/admin/admins/1", strings.NewReader(body)), claims)
+		r = mux.SetURLVars(r, map[string]string{"id": strconv.FormatInt(admin.ID, 10)})
+		rr := httptest.NewRecorder()
+		app.updateAdmin(rr, r)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("PUT %s: got %d: %s", body, rr.Code, rr.Body)
+		}
+	}
+
+	update(`{"name": "Priya", "phone": "9840012345", "department": ""}`)
+	logs, err := app.models.Activity.GetByEntityType(ctx, nil, "admin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 0 {
+		t.Fatalf("resending the current values logged %+v", logs)
+	}
+
+	update(`{"phone": "9840099999", "designation": "Officer"}`)
+	logs, err = app.models.Activity.GetByEntityType(ctx, nil, "admin", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || len(logs[0].Details) != 1 || logs[0].Details["phone"] == nil {
+		t.Fatalf("changing the phone logged %+v", logs)
+	}
+}
+
+func TestSyncAcademicsLogsCaller(t *testing.T) {
+	app := newTaskApplication(t)
+	ctx := t.Context()
+
+	callers := map[string]*auth.Claims{
+		"admin":   {UserID: 7, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID},
+		"api_key": {UserID: 9, Role: auth.RoleService, Scopes: []auth.Permission{auth.PermAcademicsWrite}, InstitutionID: memstore.DefaultInstitutionID},
+	}
+	for entityType, claims := range callers {
+		body := strings.NewReader(`{"records": [{"email": "nobody@kct.ac.in"}]}`)
+		r := app.contextSetClaims(httptest.NewRequest(http.MethodPost, "/api/v1/admin/academics/sync", body), claims)
+		rr := httptest.NewRecorder()
+		app.syncAcademics(rr, r)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: got %d: %s", entityType, rr.Code, rr.Body)
+		}
+
+		logs, err := app.models.Activity.GetByEntityType(ctx, nil, entityType, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(logs) != 1 || logs[0].Action != "academics.synced" || *logs[0].EntityID != claims.UserID {
+			t.Errorf("%s sync logged %+v", entityType, logs)
+		}
+	}
+}
+
+func TestDemotionEndsSessions(t *testing.T) {
+	app := newTaskApplication(t)
+	app.jwtService = auth.NewJWTService("test-secret")
+	app.rateLimiter = ratelimit.NewMemoryLimiter()
+	handler := app.routes()
+	ctx := t.Context()
+
+	admin := &models.Admin{InstitutionID: memstore.DefaultInstitutionID, Name: "Priya", Email: "priya@kct.ac.in", Role: string(auth.RolePlacementCoordinator), IsActive: true}
+	if err := app.models.Admins.Insert(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	signIn := func() string {
+		t.Helper()
+		tokens, err := app.createSession(httptest.NewRequest(http.MethodGet, "/", nil), adminTokenUser(admin))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tokens.AccessToken
+	}
+	createCompany := func(token string) int {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/admin/companies", strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr.Code
+	}
+
+	old := signIn()
+	if code := createCompany(old); code == http.StatusUnauthorized || code == http.StatusForbidden {
+		t.Fatalf("coordinator creating a company: got %d", code)
+	}
+
+	claims := &auth.Claims{UserID: 1, Role: "admin", AdminRole: auth.RoleSuperAdmin, InstitutionID: memstore.DefaultInstitutionID}
+	r := app.contextSetClaims(httptest.NewRequest(http.MethodPut, "/api/v1/admin/admins/1", strings.NewReader(`{"role": "faculty_viewer"}`)), claims)
+	r = mux.SetURLVars(r, map[string]string{"id": strconv.FormatInt(admin.ID, 10)})
+	rr := httptest.NewRecorder()
+	app.updateAdmin(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("demotion: got %d: %s", rr.Code, rr.Body)
+	}
+
+	// The token minted before the demotion no longer works at all, and a
+	// fresh sign-in carries the reduced role
+	if code := createCompany(old); code != http.StatusUnauthorized {
+		t.Errorf("token from before the demotion: got %d, want 401", code)
+	}
+	admin.Role = string(auth.RoleFacultyViewer)
+	if code := createCompany(signIn()); code != http.StatusForbidden {
+		t.Errorf("token after the demotion: got %d, want 403", code)
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+const (
+	defaultAPIKeyRateLimit = 60
+	maxAPIKeyRateLimit     = 6000
+	maxSyncRecords         = 1000
+)
+
+// ============================================
+// API KEY MANAGEMENT
+// ============================================
+
+// listAPIKeys returns every API key; the secrets themselves are never stored
+func (app *application) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	keys, err := app.models.APIKeys.GetAll(r.Context(), app.institutionScope(r, claims))
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"api_keys": keys,
+		"scopes":   auth.APIKeyScopes(),
+	}, nil)
+}
+
+// createAPIKeyInput is the body of POST /api/v1/admin/api-keys
+type createAPIKeyInput struct {
+	Name               string     `json:"name"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute *int       `json:"rate_limit_per_minute"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// createAPIKey issues a key for a service account. The key is only shown in
+// this response.
+func (app *application) createAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	// Keys must be created by a person, never by another key
+	if claims.Role != "admin" {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	var input createAPIKeyInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+
+	v := validator.New()
+	if validateAPIKey(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	rateLimit := defaultAPIKeyRateLimit
+	if input.RateLimitPerMinute != nil {
+		rateLimit = *input.RateLimitPerMinute
+	}
+
+	institutionID, err := app.targetInstitution(r, claims)
+	if err != nil {
+		if errors.Is(err, errUnknownInstitution) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	apiKey := &models.APIKey{
+		InstitutionID:      institutionID,
+		Name:               input.Name,
+		Prefix:             prefix,
+		Scopes:             input.Scopes,
+		RateLimitPerMinute: rateLimit,
+		ExpiresAt:          input.ExpiresAt,
+		CreatedBy:          &claims.UserID,
+	}
+
+	if err := app.models.APIKeys.Insert(r.Context(), apiKey, hash); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "api_key.created", "api_key", apiKey.ID, map[string]interface{}{
+		"name":   apiKey.Name,
+		"scopes": apiKey.Scopes,
+	})
+
+	app.writeJSON(w, http.StatusCreated, envelope{
+		"api_key": apiKey,
+		"key":     key,
+	}, nil)
+}
+
+// revokeAPIKey disables a key immediately
+func (app *application) revokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAdminsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.APIKeys.Revoke(r.Context(), app.institutionScope(r, claims), id); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "api_key.revoked", "api_key", id, nil)
+
+	app.writeJSON(w, http.StatusOK, envelope{"message": "API key revoked"}, nil)
+}
+
+// ============================================
+// ERP INTEGRATION
+// ============================================
+
+// academicSyncInput is a batch of ERP records for POST /api/v1/admin/academics/sync
+type academicSyncInput struct {
+	Records []academicSyncRecord `json:"records"`
+}
+
+type academicSyncRecord struct {
+	Email           string   `json:"email"`
+	RollNo          *string  `json:"roll_no"`
+	Department      *string  `json:"department"`
+	CGPASem1        *float64 `json:"cgpa_sem1"`
+	CGPASem2        *float64 `json:"cgpa_sem2"`
+	CGPASem3        *float64 `json:"cgpa_sem3"`
+	CGPASem4        *float64 `json:"cgpa_sem4"`
+	CGPASem5        *float64 `json:"cgpa_sem5"`
+	CGPASem6        *float64 `json:"cgpa_sem6"`
+	CGPASem7        *float64 `json:"cgpa_sem7"`
+	CGPASem8        *float64 `json:"cgpa_sem8"`
+	CGPAOverall     *float64 `json:"cgpa_overall"`
+	CurrentBacklogs *int     `json:"current_backlogs"`
+}
+
+// academicSyncError reports a record that was not applied
+type academicSyncError struct {
+	Index int    `json:"index"`
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// syncAcademics applies a batch of academic records from the college ERP.
+// Each record is matched on the student's official email within the key's
+// institution and applied on its own, so one bad record does not reject the batch.
+func (app *application) syncAcademics(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAcademicsWrite)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	var input academicSyncInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	v := validator.New()
+	if validateAcademicSyncBatch(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	institution := app.institutionScope(r, claims)
+	updated := 0
+	failures := []academicSyncError{}
+
+	for i, record := range input.Records {
+		rec := &models.AcademicSync{
+			InstitutionID: institution,
+			Email:         strings.ToLower(strings.TrimSpace(record.Email)),
+			RollNo:        record.RollNo,
+			Department:    record.Department,
+			CGPASems: [8]*float64{
+				record.CGPASem1, record.CGPASem2, record.CGPASem3, record.CGPASem4,
+				record.CGPASem5, record.CGPASem6, record.CGPASem7, record.CGPASem8,
+			},
+			CGPAOverall:     record.CGPAOverall,
+			CurrentBacklogs: record.CurrentBacklogs,
+		}
+
+		if err := validateAcademicSync(rec); err != nil {
+			failures = append(failures, academicSyncError{Index: i, Email: record.Email, Error: err.Error()})
+			continue
+		}
+
+		_, err := app.models.Students.SyncAcademics(r.Context(), rec)
+		switch {
+		case err == nil:
+			updated++
+		case errors.Is(err, models.ErrRecordNotFound):
+			failures = append(failures, academicSyncError{Index: i, Email: record.Email, Error: "student not found"})
+		case errors.Is(err, models.ErrDuplicateRollNo):
+			failures = append(failures, academicSyncError{Index: i, Email: record.Email, Error: "roll number belongs to another student"})
+		default:
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	// Admins with academics:write can run the sync too, so the entry names
+	// whichever kind of caller it was
+	entityType := "api_key"
+	if claims.Role != auth.RoleService {
+		entityType = claims.Role
+	}
+	app.logActivity(r, claims, "academics.synced", entityType, claims.UserID, map[string]interface{}{
+		"records": len(input.Records),
+		"updated": updated,
+		"failed":  len(failures),
+	})
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"updated": updated,
+		"errors":  failures,
+	}, nil)
+}
+
+// validateAcademicSync checks an ERP record before it is applied
+func validateAcademicSync(rec *models.AcademicSync) error {
+	if rec.Email == "" {
+		return errors.New("email is required")
+	}
+	if rec.RollNo != nil && strings.TrimSpace(*rec.RollNo) == "" {
+		return errors.New("roll_no must not be blank")
+	}
+	if rec.Department != nil && (strings.TrimSpace(*rec.Department) == "" || len(*rec.Department) > 100) {
+		return errors.New("department must be 1 to 100 characters")
+	}
+	for i, cgpa := range rec.CGPASems {
+		if cgpa != nil && (*cgpa < 0 || *cgpa > 10) {
+			return fmt.Errorf("cgpa_sem%d must be between 0 and 10", i+1)
+		}
+	}
+	if rec.CGPAOverall != nil && (*rec.CGPAOverall < 0 || *rec.CGPAOverall > 10) {
+		return errors.New("cgpa_overall must be between 0 and 10")
+	}
+	if rec.CurrentBacklogs != nil && *rec.CurrentBacklogs < 0 {
+		return errors.New("current_backlogs must not be negative")
+	}
+	return nil
+}
@@ -1,45 +1,67 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
 	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/gorilla/mux"
 )
 
-// loginHandler initiates Microsoft OAuth flow
+// loginHandler initiates the OAuth flow with the provider named in the URL,
+// defaulting to Microsoft for the provider-less /auth/login route
 func (app *application) loginHandler(w http.ResponseWriter, r *http.Request) {
-	// Generate random state for CSRF protection
-	b := make([]byte, 32)
-	_, err := rand.Read(b)
+	provider, ok := app.identityProvider(r)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	// Random state guards the callback against CSRF, the nonce binds the id_token to this login
+	state, err := randomString(32)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
-	state := base64.URLEncoding.EncodeToString(b)
 
-	// Store state in cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    state,
-		MaxAge:   300, // 5 minutes
-		HttpOnly: true,
-		Secure:   app.config.env == "production",
-		SameSite: http.SameSiteLaxMode,
-		Path:     "/",
-	})
+	nonce, err := randomString(32)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.setOAuthCookie(w, "oauth_state", state, 300) // 5 minutes
+	app.setOAuthCookie(w, "oauth_nonce", nonce, 300)
+
+	url, err := provider.AuthCodeURL(r.Context(), state, nonce)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), "oauth discovery failed", "provider", provider.Name(), "error", err)
+		app.errorRedirect(w, r, "Sign-in is temporarily unavailable. Please try again later.")
+		return
+	}
 
-	// Redirect to Microsoft OAuth
-	url := app.msOAuth.GetAuthURL(state)
 	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
 }
 
-// callbackHandler handles Microsoft OAuth callback
+// callbackHandler handles the OAuth callback for any configured provider
 func (app *application) callbackHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := app.identityProvider(r)
+	if !ok {
+		app.notFoundResponse(w, r)
+		return
+	}
+
 	// Verify state
 	stateCookie, err := r.Cookie("oauth_state")
 	if err != nil {
@@ -53,19 +75,20 @@ func (app *application) callbackHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Clear state cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     "oauth_state",
-		Value:    "",
-		MaxAge:   -1,
-		HttpOnly: true,
-		Path:     "/",
-	})
+	nonceCookie, err := r.Cookie("oauth_nonce")
+	if err != nil {
+		app.errorRedirect(w, r, "Session expired. Please try logging in again.")
+		return
+	}
+
+	// Clear the one-shot cookies
+	app.setOAuthCookie(w, "oauth_state", "", -1)
+	app.setOAuthCookie(w, "oauth_nonce", "", -1)
 
 	// Check for OAuth errors
 	if errMsg := r.URL.Query().Get("error"); errMsg != "" {
 		errDesc := r.URL.Query().Get("error_description")
-		app.logger.Printf("OAuth error: %s - %s", errMsg, errDesc)
+		app.logger.WarnContext(r.Context(), "oauth provider returned an error", "error", errMsg, "description", errDesc)
 		app.errorRedirect(w, r, "Authentication failed. Please try again.")
 		return
 	}
@@ -77,59 +100,76 @@ func (app *application) callbackHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	token, err := app.msOAuth.Exchange(r.Context(), code)
+	// Exchange the code and verify the id_token against the provider's signing keys
+	identity, err := provider.Authenticate(r.Context(), code, nonceCookie.Value)
 	if err != nil {
-		app.logger.Printf("Token exchange error: %v", err)
+		app.logger.WarnContext(r.Context(), "oauth authentication failed", "provider", provider.Name(), "error", err)
 		app.errorRedirect(w, r, "Failed to authenticate. Please try again.")
 		return
 	}
 
-	// Get user info from Microsoft
-	userInfo, err := app.msOAuth.GetUserInfo(r.Context(), token)
-	if err != nil {
-		app.logger.Printf("User info error: %v", err)
+	email := identity.Email
+	if email == "" {
 		app.errorRedirect(w, r, "Failed to get user information.")
 		return
 	}
 
-	app.logger.Printf("OAuth callback for user: %s (%s)", userInfo.DisplayName, userInfo.Mail)
+	app.logger.InfoContext(r.Context(), "oauth callback", "provider", provider.Name(), "email", email)
 
-	// Get email
-	email := userInfo.Mail
-	if email == "" {
-		email = userInfo.UserPrincipalName
-	}
-
-	// Validate email domain for students
-	emailLower := strings.ToLower(email)
-	isAllowedDomain := strings.HasSuffix(emailLower, "@"+app.config.allowedDomain)
+	// A provider only vouches for addresses in its own domains
+	isAllowedDomain := auth.EmailInDomains(email, provider.AllowedDomains())
 
 	// Check if user is an admin (pre-registered)
-	admin, err := app.models.Admins.GetByEmail(email)
+	admin, err := app.models.Admins.GetByEmail(r.Context(), email)
 	if err == nil && admin != nil {
-		// User is an admin
-		app.logger.Printf("Admin login: %s", admin.Email)
+		// Our own Microsoft tenant may sign in any pre-registered admin; other
+		// providers must not be able to assert an admin's address outside their domains
+		if !isAllowedDomain && provider.Name() != auth.MicrosoftProviderName {
+			app.logger.WarnContext(r.Context(), "admin rejected from provider", "email", email, "provider", provider.Name())
+			app.errorRedirect(w, r, "This account cannot sign in with the selected provider.")
+			return
+		}
 
-		jwtToken, err := app.jwtService.GenerateToken(admin.ID, admin.Email, "admin")
+		// Admins of a deactivated institution lose access; group admins keep theirs
+		institution, err := app.models.Institutions.GetByID(r.Context(), admin.InstitutionID)
 		if err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
+		if !institution.IsActive && !auth.AdminRole(admin.Role).Can(auth.PermInstitutionsAll) {
+			app.logger.WarnContext(r.Context(), "admin rejected: institution inactive", "email", email, "institution", institution.Code)
+			app.errorRedirect(w, r, "Your institution's account is not active.")
+			return
+		}
+
+		app.logger.InfoContext(r.Context(), "admin login", "email", admin.Email, "provider", provider.Name())
 
-		redirectURL := fmt.Sprintf("%s/callback.html?token=%s&role=admin", app.config.frontend.url, jwtToken)
-		http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
+		app.redirectWithAuthCode(w, r, "admin", admin.ID)
 		return
 	}
 
 	// Not an admin - must be a student
 	if !isAllowedDomain {
-		app.logger.Printf("Unauthorized domain: %s", email)
-		app.errorRedirect(w, r, fmt.Sprintf("Only @%s email addresses are allowed for students.", app.config.allowedDomain))
+		app.logger.WarnContext(r.Context(), "unauthorized domain", "email", email)
+		app.errorRedirect(w, r, fmt.Sprintf("Only @%s email addresses are allowed for students.",
+			strings.Join(provider.AllowedDomains(), ", @")))
+		return
+	}
+
+	// The email domain decides which institution a student belongs to
+	institution, err := app.models.Institutions.GetByDomain(r.Context(), auth.EmailDomain(email))
+	if err != nil {
+		if !errors.Is(err, models.ErrRecordNotFound) {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+		app.logger.WarnContext(r.Context(), "no active institution for domain", "email", email)
+		app.errorRedirect(w, r, "Your institution is not registered for placements.")
 		return
 	}
 
 	// Check if student exists
-	student, err := app.models.Students.GetByEmail(email)
+	student, err := app.models.Students.GetByEmail(r.Context(), email)
 	if err != nil {
 		if !errors.Is(err, models.ErrRecordNotFound) {
 			app.serverErrorResponse(w, r, err)
@@ -138,45 +178,173 @@ func (app *application) callbackHandler(w http.ResponseWriter, r *http.Request)
 
 		// Create new student
 		student = &models.Student{
-			Name:          userInfo.DisplayName,
+			InstitutionID: institution.ID,
+			Name:          identity.Name,
 			OfficialEmail: email,
 		}
 
-		err = app.models.Students.Insert(student)
+		err = app.models.Students.Insert(r.Context(), student)
 		if err != nil {
 			app.serverErrorResponse(w, r, err)
 			return
 		}
 
-		app.logger.Printf("New student created: %s", email)
+		app.logger.InfoContext(r.Context(), "student created", "email", email)
+	}
+
+	// A domain moved to another institution must not carry its students' data along
+	if student.InstitutionID != institution.ID {
+		app.logger.WarnContext(r.Context(), "student institution mismatch", "email", email, "student_institution", student.InstitutionID, "domain_institution", institution.ID)
+		app.errorRedirect(w, r, "Your account belongs to a different institution. Please contact the placement office.")
+		return
 	}
 
 	// Update last login
-	_ = app.models.Students.UpdateLastLogin(student.ID)
+	_ = app.models.Students.UpdateLastLogin(r.Context(), student.ID)
+
+	app.logger.InfoContext(r.Context(), "student login", "email", email)
+
+	app.redirectWithAuthCode(w, r, "student", student.ID)
+}
 
-	// Generate JWT token
-	jwtToken, err := app.jwtService.GenerateToken(student.ID, student.OfficialEmail, "student")
+// redirectWithAuthCode sends the browser back to the frontend with a one-time
+// code. Tokens never go in the URL, where they would end up in browser history,
+// proxy logs and Referer headers.
+func (app *application) redirectWithAuthCode(w http.ResponseWriter, r *http.Request, userType string, userID int64) {
+	code, hash, err := auth.GenerateAuthCode()
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	app.logger.Printf("Student login: %s", email)
+	err = app.models.AuthCodes.Insert(r.Context(), hash, &models.AuthCode{
+		UserType:  userType,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(app.config.Tokens.AuthCodeTTL),
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
 
-	redirectURL := fmt.Sprintf("%s/callback.html?token=%s&role=student", app.config.frontend.url, jwtToken)
+	redirectURL := fmt.Sprintf("%s/callback.html?code=%s", app.config.Frontend.URL, url.QueryEscape(code))
 	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 }
 
+// exchangeInput is the body of POST /api/v1/auth/exchange
+type exchangeInput struct {
+	Code string `json:"code"`
+	Mode string `json:"mode"`
+}
+
+// exchangeHandler trades a one-time login code for a session. Browsers may ask
+// for "cookie" mode to keep the tokens in HttpOnly cookies instead of the body.
+func (app *application) exchangeHandler(w http.ResponseWriter, r *http.Request) {
+	var input exchangeInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Code == "" {
+		app.badRequestResponse(w, r, errors.New("code is required"))
+		return
+	}
+	if input.Mode != "" && input.Mode != sessionModeBearer && input.Mode != sessionModeCookie {
+		app.badRequestResponse(w, r, errors.New("mode must be 'bearer' or 'cookie'"))
+		return
+	}
+
+	code, err := app.models.AuthCodes.Consume(r.Context(), auth.HashRefreshToken(input.Code))
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.errorResponse(w, r, http.StatusUnauthorized, codeInvalidLoginCode, "login code is invalid or has expired")
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	user, err := app.accountTokenUser(r.Context(), code.UserType, code.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tokens, err := app.createSession(r, *user)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeSessionResponse(w, r, input.Mode == sessionModeCookie, tokens, user.Role)
+}
+
+// listProvidersHandler tells the login page which sign-in options exist
+func (app *application) listProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(app.providers))
+	for name := range app.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		providers = append(providers, map[string]interface{}{
+			"name":            name,
+			"login_url":       apiV1 + "/auth/login/" + name,
+			"allowed_domains": app.providers[name].AllowedDomains(),
+		})
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"providers": providers}, nil)
+}
+
+// jwksHandler publishes the public keys other services use to verify our access tokens
+func (app *application) jwksHandler(w http.ResponseWriter, r *http.Request) {
+	js, err := json.Marshal(app.jwtService.JWKS())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jwk-set+json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(js)
+}
+
 // getCurrentUser returns the current user's info from JWT
 func (app *application) getCurrentUser(w http.ResponseWriter, r *http.Request) {
-	claims, err := app.extractAndValidateToken(r)
+	claims := app.contextGetClaims(r)
+
+	// The institution carries the branding the frontend shows
+	institution, err := app.models.Institutions.GetByID(r.Context(), claims.InstitutionID)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.unauthorizedResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	if claims.Role == auth.RoleService {
+		app.writeJSON(w, http.StatusOK, envelope{
+			"api_key":     envelope{"id": claims.UserID, "name": claims.Email},
+			"role":        auth.RoleService,
+			"permissions": claims.Scopes,
+			"institution": institution,
+		}, nil)
 		return
 	}
 
 	if claims.Role == "admin" {
-		admin, err := app.models.Admins.GetByID(claims.UserID)
+		admin, err := app.models.Admins.GetByID(r.Context(), claims.UserID)
 		if err != nil {
 			if errors.Is(err, models.ErrRecordNotFound) {
 				app.unauthorizedResponse(w, r)
@@ -187,14 +355,16 @@ func (app *application) getCurrentUser(w http.ResponseWriter, r *http.Request) {
 		}
 
 		app.writeJSON(w, http.StatusOK, envelope{
-			"user": admin,
-			"role": "admin",
+			"user":        admin,
+			"role":        "admin",
+			"permissions": auth.AdminRole(admin.Role).Permissions(),
+			"institution": institution,
 		}, nil)
 		return
 	}
 
 	// Student
-	student, err := app.models.Students.GetByID(claims.UserID)
+	student, err := app.models.Students.GetByID(r.Context(), claims.UserID)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.unauthorizedResponse(w, r)
@@ -204,14 +374,348 @@ func (app *application) getCurrentUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	app.writeJSON(w, http.StatusOK, envelope{
-		"user": student,
-		"role": "student",
-	}, nil)
+	env := envelope{
+		"user":        student,
+		"role":        "student",
+		"institution": institution,
+	}
+	if claims.IsImpersonation() {
+		env["impersonated_by"] = claims.Actor
+	}
+
+	app.writeJSON(w, http.StatusOK, env, nil)
+}
+
+// refreshInput is the body of POST /api/v1/auth/refresh; cookie-mode browsers send an empty object
+type refreshInput struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// refreshHandler exchanges a refresh token for a new access token and rotates the refresh token
+func (app *application) refreshHandler(w http.ResponseWriter, r *http.Request) {
+	var input refreshInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// Cookie-mode browsers send the refresh token as a cookie instead of in the body
+	cookieMode := false
+	if input.RefreshToken == "" {
+		if cookie, err := r.Cookie(refreshCookieName); err == nil && cookie.Value != "" {
+			if err := verifyCSRF(r); err != nil {
+				app.authErrorResponse(w, r, err)
+				return
+			}
+			input.RefreshToken = cookie.Value
+			cookieMode = true
+		}
+	}
+
+	if input.RefreshToken == "" {
+		app.badRequestResponse(w, r, errors.New("refresh_token is required"))
+		return
+	}
+
+	refreshToken, newHash, err := app.jwtService.GenerateRefreshToken()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(app.jwtService.RefreshTokenTTL)
+	session, err := app.models.Sessions.Rotate(r.Context(), auth.HashRefreshToken(input.RefreshToken), newHash, expiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrRefreshTokenReuse):
+			app.logger.WarnContext(r.Context(), "refresh token reuse detected, session revoked")
+			app.invalidAuthenticationTokenResponse(w, r)
+		case errors.Is(err, models.ErrRecordNotFound):
+			app.invalidAuthenticationTokenResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	// The account may have been removed or deactivated since the session started,
+	// and its role may have changed - always rebuild the token from the database
+	user, err := app.accountTokenUser(r.Context(), session.UserType, session.UserID)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			_ = app.models.Sessions.Revoke(r.Context(), session.ID)
+			app.invalidAuthenticationTokenResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	accessToken, err := app.jwtService.GenerateToken(*user, session.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	tokens := &sessionTokens{AccessToken: accessToken, RefreshToken: refreshToken}
+	app.writeSessionResponse(w, r, cookieMode, tokens, session.UserType)
+}
+
+// logoutHandler revokes the session behind the current access token
+func (app *application) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.extractAndValidateToken(r)
+	if err != nil {
+		// Still drop stale cookies so the browser is logged out either way
+		app.clearSessionCookies(w)
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	// API keys have no session; they are revoked through the admin API
+	if claims.Role == auth.RoleService {
+		app.badRequestResponse(w, r, errors.New("api keys cannot log out; revoke the key instead"))
+		return
+	}
+
+	if err := app.models.Sessions.Revoke(r.Context(), claims.SessionID); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.clearSessionCookies(w)
+	app.writeJSON(w, http.StatusOK, envelope{"message": "Logged out"}, nil)
+}
+
+// sessionTokens is the token pair handed out when a session starts or refreshes
+type sessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// createSession records a new session and issues its access and refresh tokens
+func (app *application) createSession(r *http.Request, user auth.TokenUser) (*sessionTokens, error) {
+	refreshToken, hash, err := app.jwtService.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	userAgent := r.UserAgent()
+	session := &models.Session{
+		UserType:  user.Role,
+		UserID:    user.ID,
+		UserAgent: &userAgent,
+		ExpiresAt: time.Now().Add(app.jwtService.RefreshTokenTTL),
+	}
+	if ip := clientIP(r); ip != "" {
+		session.IPAddress = &ip
+	}
+
+	if err := app.models.Sessions.Insert(r.Context(), session, hash); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := app.jwtService.GenerateToken(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sessionTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// accountTokenUser loads a session owner, failing if the account is gone or inactive
+func (app *application) accountTokenUser(ctx context.Context, userType string, userID int64) (*auth.TokenUser, error) {
+	if userType == "admin" {
+		admin, err := app.models.Admins.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin.IsActive {
+			return nil, models.ErrRecordNotFound
+		}
+		user := adminTokenUser(admin)
+		return &user, nil
+	}
+
+	student, err := app.models.Students.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user := studentTokenUser(student)
+	return &user, nil
+}
+
+func adminTokenUser(admin *models.Admin) auth.TokenUser {
+	user := auth.TokenUser{
+		ID:            admin.ID,
+		Email:         admin.Email,
+		Role:          "admin",
+		AdminRole:     auth.AdminRole(admin.Role),
+		InstitutionID: admin.InstitutionID,
+	}
+	if admin.Department != nil {
+		user.Department = *admin.Department
+	}
+	return user
+}
+
+func studentTokenUser(student *models.Student) auth.TokenUser {
+	user := auth.TokenUser{
+		ID:            student.ID,
+		Email:         student.OfficialEmail,
+		Role:          "student",
+		InstitutionID: student.InstitutionID,
+	}
+	if student.Department != nil {
+		user.Department = *student.Department
+	}
+	return user
 }
 
 // errorRedirect redirects to frontend with error message
 func (app *application) errorRedirect(w http.ResponseWriter, r *http.Request, message string) {
-	redirectURL := fmt.Sprintf("%s/callback.html?error=%s", app.config.frontend.url, message)
+	redirectURL := fmt.Sprintf("%s/callback.html?error=%s", app.config.Frontend.URL, message)
 	http.Redirect(w, r, redirectURL, http.StatusTemporaryRedirect)
 }
+
+// identityProvider resolves the {provider} route variable, defaulting to Microsoft
+func (app *application) identityProvider(r *http.Request) (auth.IdentityProvider, bool) {
+	name := mux.Vars(r)["provider"]
+	if name == "" {
+		name = auth.MicrosoftProviderName
+	}
+
+	provider, ok := app.providers[strings.ToLower(name)]
+	return provider, ok
+}
+
+// ============================================
+// COOKIE SESSIONS
+// ============================================
+
+const (
+	sessionModeBearer = "bearer"
+	sessionModeCookie = "cookie"
+
+	accessCookieName  = "pps_access"
+	refreshCookieName = "pps_refresh"
+	csrfCookieName    = "pps_csrf"
+	csrfHeaderName    = "X-CSRF-Token"
+)
+
+// errInvalidCSRFToken is returned when a cookie-authenticated request lacks a matching CSRF header
+var errInvalidCSRFToken = errors.New("missing or invalid CSRF token")
+
+// writeSessionResponse returns a new token pair in the body, or in cookie mode
+// sets it as HttpOnly cookies and only returns the CSRF token
+func (app *application) writeSessionResponse(w http.ResponseWriter, r *http.Request, cookieMode bool, tokens *sessionTokens, role string) {
+	expiresIn := int(app.jwtService.AccessTokenTTL.Seconds())
+
+	if !cookieMode {
+		app.writeJSON(w, http.StatusOK, envelope{
+			"token":         tokens.AccessToken,
+			"refresh_token": tokens.RefreshToken,
+			"expires_in":    expiresIn,
+			"role":          role,
+		}, nil)
+		return
+	}
+
+	csrfToken, err := randomString(32)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	refreshMaxAge := int(app.jwtService.RefreshTokenTTL.Seconds())
+	app.setSessionCookie(w, accessCookieName, tokens.AccessToken, "/", expiresIn, true)
+	// The refresh token is only ever needed by the refresh and logout routes
+	app.setSessionCookie(w, refreshCookieName, tokens.RefreshToken, refreshCookiePath(r), refreshMaxAge, true)
+	// The frontend echoes the token from the response body in the
+	// X-CSRF-Token header; the cookie is what the header is checked against
+	app.setSessionCookie(w, csrfCookieName, csrfToken, "/", refreshMaxAge, false)
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"csrf_token": csrfToken,
+		"expires_in": expiresIn,
+		"role":       role,
+	}, nil)
+}
+
+// clearSessionCookies removes any cookie-mode session from the browser
+func (app *application) clearSessionCookies(w http.ResponseWriter) {
+	app.setSessionCookie(w, accessCookieName, "", "/", -1, true)
+	app.setSessionCookie(w, refreshCookieName, "", apiV1+"/auth", -1, true)
+	app.setSessionCookie(w, refreshCookieName, "", "/auth", -1, true)
+	app.setSessionCookie(w, csrfCookieName, "", "/", -1, false)
+}
+
+// refreshCookiePath scopes the refresh cookie to the auth routes of the API
+// version the session was created through
+func refreshCookiePath(r *http.Request) string {
+	if contextIsLegacy(r) {
+		return "/auth"
+	}
+	return apiV1 + "/auth"
+}
+
+// setSessionCookie sets a cookie-mode session cookie. The frontend and API
+// are usually on different sites (Netlify and Railway), and browsers only
+// attach cookies to cross-site fetches when they are SameSite=None, which in
+// turn requires Secure. Cross-site forgery is stopped by the CSRF check
+// instead. Browsers treat http://localhost as secure, so local development
+// still works.
+func (app *application) setSessionCookie(w http.ResponseWriter, name, value, path string, maxAge int, httpOnly bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: httpOnly,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+}
+
+// verifyCSRF applies the double-submit check to unsafe methods: the header
+// must match the CSRF cookie, which another site cannot read
+func verifyCSRF(r *http.Request) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+
+	cookie, err := r.Cookie(csrfCookieName)
+	if err != nil || cookie.Value == "" {
+		return errInvalidCSRFToken
+	}
+
+	header := r.Header.Get(csrfHeaderName)
+	if subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) != 1 {
+		return errInvalidCSRFToken
+	}
+
+	return nil
+}
+
+// setOAuthCookie stores short-lived login state; a negative maxAge deletes it
+func (app *application) setOAuthCookie(w http.ResponseWriter, name, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   app.config.Env == "production",
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+	})
+}
+
+// randomString returns n random bytes, URL-safe base64 encoded
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
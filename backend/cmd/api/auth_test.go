@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+)
+
+func TestCookieSessionWorksCrossSite(t *testing.T) {
+	app := newTestApplication()
+	app.jwtService = auth.NewJWTService("test-secret")
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/exchange", nil)
+	app.writeSessionResponse(rr, r, true, &sessionTokens{AccessToken: "access", RefreshToken: "refresh"}, "student")
+
+	var body struct {
+		CSRFToken string `json:"csrf_token"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 3 {
+		t.Fatalf("got %d cookies, want access, refresh and CSRF", len(cookies))
+	}
+	for _, c := range cookies {
+		// The frontend is on another site, so Lax cookies would never be sent
+		if c.SameSite != http.SameSiteNoneMode || !c.Secure {
+			t.Errorf("%s: SameSite %v Secure %v, want SameSite=None; Secure", c.Name, c.SameSite, c.Secure)
+		}
+		if c.Name == csrfCookieName && (c.Value == "" || c.Value != body.CSRFToken) {
+			t.Errorf("CSRF cookie %q does not match the csrf_token in the body %q", c.Value, body.CSRFToken)
+		}
+	}
+}
+
+func TestImpersonationCanOnlyReadAndLogOut(t *testing.T) {
+	app := newTaskApplication(t)
+	app.jwtService = auth.NewJWTService("test-secret")
+	app.rateLimiter = ratelimit.NewMemoryLimiter()
+	handler := app.routes()
+	ctx := t.Context()
+
+	admin := &models.Admin{InstitutionID: memstore.DefaultInstitutionID, Name: "Priya", Email: "priya@kct.ac.in", Role: string(auth.RolePlacementCoordinator), IsActive: true}
+	if err := app.models.Admins.Insert(ctx, admin); err != nil {
+		t.Fatal(err)
+	}
+	student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: "asha@kct.ac.in", Name: "Asha"}
+	if err := app.models.Students.Insert(ctx, student); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := app.createSession(httptest.NewRequest(http.MethodGet, "/", nil), adminTokenUser(admin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminClaims, err := app.jwtService.ValidateToken(tokens.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actor := auth.Actor{Subject: "admin:1", AdminID: admin.ID, Email: admin.Email}
+	token, err := app.jwtService.GenerateImpersonationToken(studentTokenUser(student), actor, adminClaims.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		r := httptest.NewRequest(method, path, strings.NewReader(`{}`))
+		r.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	if rr := send(http.MethodPut, "/api/v1/student/profile"); rr.Code != http.StatusForbidden {
+		t.Errorf("profile update while impersonating: got %d, want 403", rr.Code)
+	}
+
+	// Logging out ends the impersonation along with the session it is bound to
+	if rr := send(http.MethodPost, "/api/v1/auth/logout"); rr.Code != http.StatusOK {
+		t.Fatalf("logout while impersonating: got %d: %s", rr.Code, rr.Body)
+	}
+	if active, _ := app.models.Sessions.IsActive(ctx, adminClaims.SessionID); active {
+		t.Error("session still active after logout")
+	}
+	if rr := send(http.MethodGet, "/api/v1/student/profile"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("impersonation token after logout: got %d, want 401", rr.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+)
+
+type contextKey string
+
+const (
+	claimsContextKey  = contextKey("claims")
+	requestContextKey = contextKey("request")
+)
+
+// requestInfo is shared by every middleware handling one request, so outer
+// middleware such as the access log can see what inner middleware learned
+type requestInfo struct {
+	id     string
+	route  string // path template of the matched route, e.g. /api/admin/students/{id}
+	claims *auth.Claims
+	legacy bool // requested on an unversioned path, see legacyPaths
+}
+
+// contextSetRequestInfo returns a copy of the request carrying info
+func contextSetRequestInfo(r *http.Request, info *requestInfo) *http.Request {
+	ctx := context.WithValue(r.Context(), requestContextKey, info)
+	return r.WithContext(ctx)
+}
+
+func requestInfoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestContextKey).(*requestInfo)
+	return info
+}
+
+// contextGetRequestID returns the request ID, or "" outside the requestID middleware
+func contextGetRequestID(r *http.Request) string {
+	if info := requestInfoFromContext(r.Context()); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// contextIsLegacy reports whether the request came in on an unversioned path
+func contextIsLegacy(r *http.Request) bool {
+	info := requestInfoFromContext(r.Context())
+	return info != nil && info.legacy
+}
+
+// contextSetClaims returns a copy of the request carrying the caller's claims
+func (app *application) contextSetClaims(r *http.Request, claims *auth.Claims) *http.Request {
+	if info := requestInfoFromContext(r.Context()); info != nil {
+		info.claims = claims
+	}
+
+	ctx := context.WithValue(r.Context(), claimsContextKey, claims)
+	return r.WithContext(ctx)
+}
+
+// contextGetClaims returns the claims stored by the authenticate middleware,
+// or nil when the request was not authenticated
+func (app *application) contextGetClaims(r *http.Request) *auth.Claims {
+	claims, ok := r.Context().Value(claimsContextKey).(*auth.Claims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
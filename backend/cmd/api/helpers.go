@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
 	"github.com/gorilla/mux"
 )
 
@@ -83,46 +92,177 @@ func (app *application) writeJSON(w http.ResponseWriter, status int, data envelo
 	return nil
 }
 
+// writeJSONWithETag writes a 200 response tagged with a hash of its body, or
+// 304 Not Modified with no body when the client's If-None-Match already holds
+// that tag. no-cache makes browsers revalidate on every load rather than show
+// a stale dashboard, so an unchanged one costs a round trip but no download.
+func (app *application) writeJSONWithETag(w http.ResponseWriter, r *http.Request, data envelope) error {
+	js, err := json.MarshalIndent(data, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	js = append(js, '\n')
+
+	sum := sha256.Sum256(js)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(js)
+
+	return nil
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. The
+// comparison is weak, as RFC 9110 requires for If-None-Match, so a W/ prefix
+// added by a proxy still matches.
+func etagMatches(header, etag string) bool {
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
 // ============================================
 // ERROR RESPONSES
 // ============================================
 
-func (app *application) errorResponse(w http.ResponseWriter, _ *http.Request, status int, message interface{}) {
-	env := envelope{"error": message}
+// Error codes are part of the API contract: clients branch on them instead
+// of matching messages. Never rename or reuse one; add a new code instead, and
+// list it in errorCodes so it appears in the OpenAPI document.
+const (
+	codeBadRequest            = "BAD_REQUEST"
+	codeValidationFailed      = "VALIDATION_FAILED"
+	codeAuthenticationNeeded  = "AUTHENTICATION_REQUIRED"
+	codeInvalidToken          = "INVALID_TOKEN"
+	codeInvalidLoginCode      = "INVALID_LOGIN_CODE"
+	codeForbidden             = "FORBIDDEN"
+	codeInvalidCSRFToken      = "INVALID_CSRF_TOKEN"
+	codeImpersonationReadOnly = "IMPERSONATION_READ_ONLY"
+	codeNotFound              = "NOT_FOUND"
+	codeMethodNotAllowed      = "METHOD_NOT_ALLOWED"
+	codeEndpointRetired       = "ENDPOINT_RETIRED"
+	codeEditConflict          = "EDIT_CONFLICT"
+	codeDuplicateEmail        = "DUPLICATE_EMAIL"
+	codeDuplicateCode         = "DUPLICATE_CODE"
+	codeDuplicateDomain       = "DUPLICATE_DOMAIN"
+	codeLastSuperAdmin        = "LAST_SUPER_ADMIN"
+	codeJobNotRetryable       = "JOB_NOT_RETRYABLE"
+	codeJobNotFinished        = "JOB_NOT_FINISHED"
+	codePayloadTooLarge       = "PAYLOAD_TOO_LARGE"
+	codeRateLimited           = "RATE_LIMITED"
+	codeInternalError         = "INTERNAL_ERROR"
+)
+
+var errorCodes = []string{
+	codeBadRequest, codeValidationFailed, codeAuthenticationNeeded, codeInvalidToken,
+	codeInvalidLoginCode, codeForbidden, codeInvalidCSRFToken, codeImpersonationReadOnly,
+	codeNotFound, codeMethodNotAllowed, codeEndpointRetired, codeEditConflict,
+	codeDuplicateEmail, codeDuplicateCode, codeDuplicateDomain, codeLastSuperAdmin,
+	codeJobNotRetryable, codeJobNotFinished, codePayloadTooLarge, codeRateLimited, codeInternalError,
+}
+
+// apiError is the body of the error envelope: {"error": {...}}
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Details maps field names to what is wrong with them
+	Details   map[string]string `json:"details,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// errorResponse sends an error envelope. The request ID is included so users
+// can quote it when reporting a problem.
+func (app *application) errorResponse(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	app.sendError(w, r, status, apiError{Code: code, Message: message})
+}
+
+func (app *application) sendError(w http.ResponseWriter, r *http.Request, status int, apiErr apiError) {
+	apiErr.RequestID = contextGetRequestID(r)
+
+	env := envelope{"error": apiErr}
+	if contextIsLegacy(r) {
+		// Unversioned paths keep the shape clients were built against: the
+		// message, or the field errors, as "error"
+		env = envelope{"error": apiErr.Message, "code": apiErr.Code}
+		if apiErr.Details != nil {
+			env["error"] = apiErr.Details
+		}
+		if apiErr.RequestID != "" {
+			env["request_id"] = apiErr.RequestID
+		}
+	}
+
 	err := app.writeJSON(w, status, env, nil)
 	if err != nil {
-		app.logger.Print(err)
+		app.logger.ErrorContext(r.Context(), "failed to write error response", "error", err)
 		w.WriteHeader(500)
 	}
 }
 
+// serverErrorResponse logs err and sends a generic 500. A nil err means the
+// caller has already logged the failure.
 func (app *application) serverErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.logger.Printf("ERROR: %v", err)
+	if err != nil {
+		app.logger.ErrorContext(r.Context(), "server error", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
 	message := "The server encountered a problem and could not process your request"
-	app.errorResponse(w, r, http.StatusInternalServerError, message)
+	app.errorResponse(w, r, http.StatusInternalServerError, codeInternalError, message)
 }
 
 func (app *application) badRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
-	app.errorResponse(w, r, http.StatusBadRequest, err.Error())
+	app.errorResponse(w, r, http.StatusBadRequest, codeBadRequest, err.Error())
 }
 
 func (app *application) notFoundResponse(w http.ResponseWriter, r *http.Request) {
 	message := "The requested resource could not be found"
-	app.errorResponse(w, r, http.StatusNotFound, message)
+	app.errorResponse(w, r, http.StatusNotFound, codeNotFound, message)
+}
+
+func (app *application) methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
+	message := fmt.Sprintf("The %s method is not supported for this resource", r.Method)
+	app.errorResponse(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, message)
 }
 
 func (app *application) unauthorizedResponse(w http.ResponseWriter, r *http.Request) {
 	message := "You must be authenticated to access this resource"
-	app.errorResponse(w, r, http.StatusUnauthorized, message)
+	app.errorResponse(w, r, http.StatusUnauthorized, codeAuthenticationNeeded, message)
 }
 
 func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request) {
 	message := "You don't have permission to access this resource"
-	app.errorResponse(w, r, http.StatusForbidden, message)
+	app.errorResponse(w, r, http.StatusForbidden, codeForbidden, message)
+}
+
+func (app *application) editConflictResponse(w http.ResponseWriter, r *http.Request) {
+	message := "The record was modified by another request; reload it and try again"
+	app.errorResponse(w, r, http.StatusConflict, codeEditConflict, message)
+}
+
+// rateLimitExceededResponse sends 429 with the number of seconds to wait
+func (app *application) rateLimitExceededResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	app.errorResponse(w, r, http.StatusTooManyRequests, codeRateLimited, "rate limit exceeded")
 }
 
 func (app *application) validationErrorResponse(w http.ResponseWriter, r *http.Request, errors map[string]string) {
-	app.errorResponse(w, r, http.StatusUnprocessableEntity, errors)
+	app.sendError(w, r, http.StatusUnprocessableEntity, apiError{
+		Code:    codeValidationFailed,
+		Message: "One or more fields are invalid",
+		Details: errors,
+	})
 }
 
 // ============================================
@@ -143,8 +283,49 @@ func (app *application) extractTokenFromHeader(r *http.Request) (string, error)
 	return parts[1], nil
 }
 
+// extractToken reads the access token from the Authorization header, falling
+// back to the session cookie for browsers using cookie mode
+func (app *application) extractToken(r *http.Request) (string, error) {
+	if r.Header.Get("Authorization") != "" {
+		return app.extractTokenFromHeader(r)
+	}
+
+	cookie, err := r.Cookie(accessCookieName)
+	if err != nil || cookie.Value == "" {
+		return "", errors.New("authorization header is required")
+	}
+
+	// Browsers attach cookies on their own, so state-changing requests must
+	// also prove they came from our frontend
+	if err := verifyCSRF(r); err != nil {
+		return "", err
+	}
+
+	return cookie.Value, nil
+}
+
+// apiKeyFromRequest returns the API key sent in X-API-Key or as
+// "Authorization: ApiKey {key}", or "" when the request has none
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) == 2 && strings.EqualFold(parts[0], "apikey") {
+		return parts[1]
+	}
+	return ""
+}
+
+// extractAndValidateToken authenticates the request with either an API key
+// or an access token bound to a live session
 func (app *application) extractAndValidateToken(r *http.Request) (*auth.Claims, error) {
-	tokenString, err := app.extractTokenFromHeader(r)
+	if key := apiKeyFromRequest(r); key != "" {
+		return app.authenticateAPIKey(r, key)
+	}
+
+	tokenString, err := app.extractToken(r)
 	if err != nil {
 		return nil, err
 	}
@@ -154,14 +335,119 @@ func (app *application) extractAndValidateToken(r *http.Request) (*auth.Claims,
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
+	// Every access token must belong to a live session so logout and
+	// revocation take effect immediately
+	if claims.SessionID == 0 {
+		return nil, errors.New("invalid token: no session")
+	}
+
+	active, err := app.models.Sessions.IsActive(r.Context(), claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errors.New("session has been revoked or has expired")
+	}
+
+	if claims.IsImpersonation() {
+		if err := app.auditImpersonatedRequest(r, claims); err != nil {
+			return nil, err
+		}
+	}
+
 	return claims, nil
 }
 
-func (app *application) authenticateStudent(r *http.Request) (*auth.Claims, error) {
-	claims, err := app.extractAndValidateToken(r)
+// errImpersonationReadOnly is returned when an impersonation token is used to change data
+var errImpersonationReadOnly = errors.New("impersonation tokens are read-only")
+
+// impersonationWrites are the only unsafe requests an impersonation token may
+// make. Logging out revokes the admin session the token is bound to, which is
+// how an impersonation is ended for good.
+var impersonationWrites = map[string]bool{
+	apiV1 + "/auth/logout":   true,
+	apiV1 + "/auth/exchange": true,
+}
+
+// auditImpersonatedRequest records a request made under impersonation against
+// the real admin, and refuses anything but reads and impersonationWrites
+func (app *application) auditImpersonatedRequest(r *http.Request, claims *auth.Claims) error {
+	readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead ||
+		(r.Method == http.MethodPost && impersonationWrites[r.URL.Path])
+
+	institutionID := claims.InstitutionID
+	app.recordActivity(r, &institutionID, "admin", claims.Actor.AdminID, "impersonation.request", claims.Role, claims.UserID, map[string]interface{}{
+		"method":  r.Method,
+		"path":    r.URL.Path,
+		"blocked": !readOnly,
+	})
+
+	if !readOnly {
+		return errImpersonationReadOnly
+	}
+	return nil
+}
+
+// authenticateAPIKey resolves an API key to service claims carrying its scopes
+// and applies the key's rate limit
+func (app *application) authenticateAPIKey(r *http.Request, key string) (*auth.Claims, error) {
+	if !auth.LooksLikeAPIKey(key) {
+		return nil, errors.New("invalid api key")
+	}
+
+	apiKey, err := app.models.APIKeys.GetActiveByHash(r.Context(), auth.HashAPIKey(key))
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			return nil, errors.New("invalid api key")
+		}
+		return nil, err
+	}
+
+	result, err := app.rateLimiter.Allow(r.Context(), fmt.Sprintf("apikey:%d", apiKey.ID), ratelimit.PerMinute(apiKey.RateLimitPerMinute))
 	if err != nil {
 		return nil, err
 	}
+	if !result.Allowed {
+		return nil, &rateLimitError{retryAfter: result.RetryAfter}
+	}
+
+	if err := app.models.APIKeys.TouchLastUsed(r.Context(), apiKey.ID, clientIP(r)); err != nil {
+		app.logger.WarnContext(r.Context(), "failed to record api key use", "api_key_id", apiKey.ID, "error", err)
+	}
+
+	scopes := make([]auth.Permission, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = auth.Permission(scope)
+	}
+
+	return &auth.Claims{
+		UserID:        apiKey.ID,
+		Email:         apiKey.Name,
+		Role:          auth.RoleService,
+		InstitutionID: apiKey.InstitutionID,
+		Scopes:        scopes,
+	}, nil
+}
+
+// rateLimitError is returned when a caller has used up its request budget
+type rateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return "rate limit exceeded"
+}
+
+// errNotAuthenticated is returned when a handler runs without claims in its
+// context, i.e. its route was registered without an authentication guard
+var errNotAuthenticated = errors.New("authentication required")
+
+// authenticateStudent returns the claims of the signed-in student
+func (app *application) authenticateStudent(r *http.Request) (*auth.Claims, error) {
+	claims := app.contextGetClaims(r)
+	if claims == nil {
+		return nil, errNotAuthenticated
+	}
 
 	if claims.Role != "student" {
 		return nil, errors.New("student access required")
@@ -170,19 +456,225 @@ func (app *application) authenticateStudent(r *http.Request) (*auth.Claims, erro
 	return claims, nil
 }
 
-func (app *application) authenticateAdmin(r *http.Request) (*auth.Claims, error) {
-	claims, err := app.extractAndValidateToken(r)
-	if err != nil {
-		return nil, err
+// errPermissionDenied is returned when an authenticated admin lacks a permission
+var errPermissionDenied = errors.New("permission denied")
+
+// requirePermission checks that the authenticated admin or API key has the
+// permission through its role or scopes
+func (app *application) requirePermission(r *http.Request, permission auth.Permission) (*auth.Claims, error) {
+	claims := app.contextGetClaims(r)
+	if claims == nil {
+		return nil, errNotAuthenticated
 	}
 
-	if claims.Role != "admin" {
+	if claims.Role != "admin" && claims.Role != auth.RoleService {
 		return nil, errors.New("admin access required")
 	}
 
+	if !claims.Can(permission) {
+		return nil, errPermissionDenied
+	}
+
 	return claims, nil
 }
 
+// authErrorResponse sends 403 for missing permissions, 429 for exhausted rate
+// limits and 401 for everything else
+func (app *application) authErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var limited *rateLimitError
+	if errors.As(err, &limited) {
+		app.rateLimitExceededResponse(w, r, limited.retryAfter)
+		return
+	}
+	if errors.Is(err, errPermissionDenied) {
+		app.forbiddenResponse(w, r)
+		return
+	}
+	if errors.Is(err, errInvalidCSRFToken) {
+		app.errorResponse(w, r, http.StatusForbidden, codeInvalidCSRFToken, err.Error())
+		return
+	}
+	if errors.Is(err, errImpersonationReadOnly) {
+		app.errorResponse(w, r, http.StatusForbidden, codeImpersonationReadOnly, err.Error())
+		return
+	}
+	app.unauthorizedResponse(w, r)
+}
+
+// departmentScope returns the department an admin is limited to, or nil for unrestricted roles
+func (app *application) departmentScope(claims *auth.Claims) *string {
+	if !claims.AdminRole.IsDepartmentScoped() {
+		return nil
+	}
+	department := claims.Department
+	return &department
+}
+
+// institutionScope returns the institution a request is limited to, or nil
+// when a group admin works across every institution. Group admins can narrow
+// it to one institution with ?institution={id}.
+func (app *application) institutionScope(r *http.Request, claims *auth.Claims) *int64 {
+	if claims.Can(auth.PermInstitutionsAll) {
+		id, err := strconv.ParseInt(r.URL.Query().Get("institution"), 10, 64)
+		if err != nil || id < 1 {
+			return nil
+		}
+		return &id
+	}
+
+	id := claims.InstitutionID
+	return &id
+}
+
+var errUnknownInstitution = errors.New("institution not found")
+
+// targetInstitution returns the institution new records are created in: the
+// caller's own, or for group admins the one picked with ?institution={id}
+func (app *application) targetInstitution(r *http.Request, claims *auth.Claims) (int64, error) {
+	scope := app.institutionScope(r, claims)
+	if scope == nil || *scope == claims.InstitutionID {
+		return claims.InstitutionID, nil
+	}
+
+	if _, err := app.models.Institutions.GetByID(r.Context(), *scope); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			return 0, errUnknownInstitution
+		}
+		return 0, err
+	}
+	return *scope, nil
+}
+
+// inInstitution reports whether records of an institution are visible to the caller
+func (app *application) inInstitution(claims *auth.Claims, institutionID int64) bool {
+	return claims.Can(auth.PermInstitutionsAll) || claims.InstitutionID == institutionID
+}
+
+// canAccessStudent reports whether the admin may see the given student
+func (app *application) canAccessStudent(claims *auth.Claims, student *models.Student) bool {
+	if !app.inInstitution(claims, student.InstitutionID) {
+		return false
+	}
+
+	scope := app.departmentScope(claims)
+	if scope == nil {
+		return true
+	}
+	return *scope != "" && student.Department != nil && *student.Department == *scope
+}
+
+// ============================================
+// AUDIT HELPERS
+// ============================================
+
+// logActivity records an admin action in activity_logs. Failures are logged
+// but never fail the request that triggered them.
+// Actions by group admins are recorded as group-level, without an institution.
+func (app *application) logActivity(r *http.Request, claims *auth.Claims, action, entityType string, entityID int64, details map[string]interface{}) {
+	var institutionID *int64
+	if !claims.Can(auth.PermInstitutionsAll) {
+		institutionID = &claims.InstitutionID
+	}
+	app.recordActivity(r, institutionID, claims.Role, claims.UserID, action, entityType, entityID, details)
+}
+
+// recordActivity writes an activity_logs entry for an explicit user
+func (app *application) recordActivity(r *http.Request, institutionID *int64, userType string, userID int64, action, entityType string, entityID int64, details map[string]interface{}) {
+	entry := &models.ActivityLog{
+		InstitutionID: institutionID,
+		UserType:      userType,
+		UserID:        userID,
+		Action:        action,
+		EntityType:    &entityType,
+		EntityID:      &entityID,
+		Details:       details,
+	}
+
+	if ip := clientIP(r); ip != "" {
+		entry.IPAddress = &ip
+	}
+
+	// The change being audited has already been made, so the entry is written
+	// even if the client disconnects now
+	if err := app.models.Activity.Insert(context.WithoutCancel(r.Context()), entry); err != nil {
+		app.logger.ErrorContext(r.Context(), "failed to record activity", "action", action, "error", err)
+	}
+}
+
+// newRequestID returns a random 128-bit hex ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts IDs from upstream proxies that are short and safe to log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// clientIP returns the IP address of the client that sent the request. Behind
+// trusted proxies the realIP middleware has already put it in RemoteAddr.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
+}
+
+// forwardedClientIP resolves the client address of a request that came
+// through trusted proxies. X-Forwarded-For is read from the right, skipping
+// the proxies' own entries, so a client cannot spoof its address by sending
+// the header itself. Requests from untrusted peers keep their peer address.
+func forwardedClientIP(peer string, forwardedFor []string, trusted []*net.IPNet) string {
+	if !ipInNets(peer, trusted) {
+		return peer
+	}
+
+	var hops []string
+	for _, header := range forwardedFor {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		if !ipInNets(hop, trusted) {
+			return hop
+		}
+		peer = hop
+	}
+
+	return peer
+}
+
+func ipInNets(ip string, nets []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
 // ============================================
 // URL PARAMETER HELPERS
 // ============================================
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+var (
+	institutionCodeRX = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,49}$`)
+	domainRX          = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+)
+
+// ============================================
+// INSTITUTION MANAGEMENT
+// ============================================
+
+// listInstitutions returns every institution in the group
+func (app *application) listInstitutions(w http.ResponseWriter, r *http.Request) {
+	_, err := app.requirePermission(r, auth.PermInstitutionsAll)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	institutions, err := app.models.Institutions.GetAll(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"institutions": institutions}, nil)
+}
+
+// createInstitutionInput is the body of POST /api/v1/admin/institutions
+type createInstitutionInput struct {
+	Code         string   `json:"code"`
+	Name         string   `json:"name"`
+	Domains      []string `json:"domains"`
+	LogoURL      *string  `json:"logo_url"`
+	PrimaryColor *string  `json:"primary_color"`
+}
+
+// createInstitution adds a college to the group. Its skill catalogue and
+// batches start as a copy of the first institution's.
+func (app *application) createInstitution(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermInstitutionsAll)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	var input createInstitutionInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	inst := &models.Institution{
+		Code:         strings.ToLower(strings.TrimSpace(input.Code)),
+		Name:         strings.TrimSpace(input.Name),
+		Domains:      normalizeDomains(input.Domains),
+		LogoURL:      input.LogoURL,
+		PrimaryColor: input.PrimaryColor,
+	}
+
+	if !institutionCodeRX.MatchString(inst.Code) {
+		app.badRequestResponse(w, r, errors.New("code must be 2-50 lowercase letters, digits or dashes"))
+		return
+	}
+	if err := validateInstitution(inst); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Institutions.Insert(r.Context(), inst); err != nil {
+		app.institutionConflictResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "institution.created", "institution", inst.ID, map[string]interface{}{
+		"code":    inst.Code,
+		"name":    inst.Name,
+		"domains": inst.Domains,
+	})
+
+	app.writeJSON(w, http.StatusCreated, envelope{"institution": inst}, nil)
+}
+
+// updateInstitutionInput changes only the fields that are present
+type updateInstitutionInput struct {
+	Name         *string  `json:"name"`
+	Domains      []string `json:"domains"`
+	LogoURL      *string  `json:"logo_url"`
+	PrimaryColor *string  `json:"primary_color"`
+	IsActive     *bool    `json:"is_active"`
+}
+
+// updateInstitution edits an institution's name, branding, domains or status.
+// The code is permanent.
+func (app *application) updateInstitution(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermInstitutionsAll)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	inst, err := app.models.Institutions.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	var input updateInstitutionInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	changes := map[string]interface{}{}
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) != inst.Name {
+		changes["name"] = fieldChange(inst.Name, strings.TrimSpace(*input.Name))
+		inst.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Domains != nil {
+		domains := normalizeDomains(input.Domains)
+		changes["domains"] = fieldChange(inst.Domains, domains)
+		inst.Domains = domains
+	}
+	if input.LogoURL != nil {
+		changes["logo_url"] = fieldChange(inst.LogoURL, *input.LogoURL)
+		inst.LogoURL = input.LogoURL
+	}
+	if input.PrimaryColor != nil {
+		changes["primary_color"] = fieldChange(inst.PrimaryColor, *input.PrimaryColor)
+		inst.PrimaryColor = input.PrimaryColor
+	}
+	if input.IsActive != nil && *input.IsActive != inst.IsActive {
+		changes["is_active"] = fieldChange(inst.IsActive, *input.IsActive)
+		inst.IsActive = *input.IsActive
+	}
+
+	if err := validateInstitution(inst); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.models.Institutions.Update(r.Context(), inst); err != nil {
+		if errors.Is(err, models.ErrRecordNotFound) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.institutionConflictResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "institution.updated", "institution", inst.ID, changes)
+
+	app.writeJSON(w, http.StatusOK, envelope{"institution": inst}, nil)
+}
+
+func (app *application) institutionConflictResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, models.ErrDuplicateCode):
+		app.errorResponse(w, r, http.StatusConflict, codeDuplicateCode, "an institution with this code already exists")
+	case errors.Is(err, models.ErrDuplicateDomain):
+		app.errorResponse(w, r, http.StatusConflict, codeDuplicateDomain, "a domain already belongs to another institution")
+	default:
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// validateInstitution checks the fields shared by create and update
+func validateInstitution(inst *models.Institution) error {
+	if inst.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(inst.Domains) == 0 {
+		return errors.New("at least one domain is required")
+	}
+	for _, domain := range inst.Domains {
+		if !domainRX.MatchString(domain) {
+			return errors.New("invalid domain " + domain)
+		}
+	}
+	return nil
+}
+
+// normalizeDomains lowercases domains, strips a leading @ and drops duplicates
+func normalizeDomains(domains []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		normalized = append(normalized, domain)
+	}
+	return normalized
+}
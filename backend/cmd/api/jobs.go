@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/jobs"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+// Job kinds. A kind is stored with every job, so never rename one.
+const (
+	jobStudentsExport = "students.export"
+)
+
+var jobStatuses = []string{
+	string(jobs.StatusPending), string(jobs.StatusRunning), string(jobs.StatusSucceeded), string(jobs.StatusDead),
+}
+
+// registerJobs tells the runner how to run each kind of job
+func (app *application) registerJobs(runner *jobs.Runner) {
+	runner.Handle(jobStudentsExport, app.runStudentsExport)
+}
+
+// exportDir holds the files written by export jobs
+func (app *application) exportDir() string {
+	return filepath.Join(app.config.Uploads.Dir, "exports")
+}
+
+// ============================================
+// STUDENT EXPORT JOB
+// ============================================
+
+// exportJobPayload is the filter an export job was queued with, already
+// limited to what the requester may see
+type exportJobPayload struct {
+	InstitutionID   *int64  `json:"institution_id"`
+	BatchYear       *int    `json:"batch_year"`
+	Department      *string `json:"department"`
+	PlacementStatus *string `json:"placement_status"`
+}
+
+// exportJobResult is stored on a finished export job
+type exportJobResult struct {
+	Rows int    `json:"rows"`
+	File string `json:"file"`
+}
+
+// createExportJob queues a CSV export of the students the caller can see,
+// for exports too large to build within one request
+func (app *application) createExportJob(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	filter := app.exportFilter(r, claims)
+	payload, err := json.Marshal(exportJobPayload{
+		InstitutionID:   filter.InstitutionID,
+		BatchYear:       filter.BatchYear,
+		Department:      filter.Department,
+		PlacementStatus: (*string)(filter.PlacementStatus),
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	job := &jobs.Job{
+		InstitutionID: filter.InstitutionID,
+		Kind:          jobStudentsExport,
+		Payload:       payload,
+		CreatedByType: &claims.Role,
+		CreatedByID:   &claims.UserID,
+	}
+	if err := app.jobs.Enqueue(r.Context(), job); err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.logActivity(r, claims, "export_queued", "job", job.ID, nil)
+
+	headers := make(http.Header)
+	headers.Set("Location", fmt.Sprintf("%s/admin/jobs/%d", apiV1, job.ID))
+	app.writeJSON(w, http.StatusAccepted, envelope{"job": job}, headers)
+}
+
+// runStudentsExport writes the export to a file. The file is written under a
+// temporary name and renamed, so a download never sees a partial export.
+func (app *application) runStudentsExport(ctx context.Context, job *jobs.Job) (json.RawMessage, error) {
+	var payload exportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, jobs.Permanent(fmt.Errorf("invalid payload: %w", err))
+	}
+
+	filter := models.StudentFilter{
+		InstitutionID: payload.InstitutionID,
+		BatchYear:     payload.BatchYear,
+		Department:    payload.Department,
+	}
+	if payload.PlacementStatus != nil {
+		status := models.PlacementStatus(*payload.PlacementStatus)
+		filter.PlacementStatus = &status
+	}
+
+	result, err := app.models.Students.Export(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(app.exportDir(), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(app.exportDir(), ".export-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	err = writeStudentsCSV(f, result.Students)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("job-%d.csv", job.ID)
+	if err := os.Rename(f.Name(), filepath.Join(app.exportDir(), name)); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(exportJobResult{Rows: len(result.Students), File: name})
+}
+
+// ============================================
+// JOB ADMINISTRATION
+// ============================================
+
+// listJobs lists recent jobs in the caller's institution, newest first
+func (app *application) listJobs(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermJobsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	qs := r.URL.Query()
+	status := qs.Get("status")
+	limit := app.readInt(qs, "limit", 50)
+
+	v := validator.New()
+	v.OneOf("status", &status, jobStatuses...)
+	v.IntRange("limit", &limit, 1, 200)
+	if !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	list, err := app.jobs.List(r.Context(), jobs.Filter{
+		InstitutionID: app.institutionScope(r, claims),
+		Status:        jobs.Status(status),
+		Kind:          qs.Get("kind"),
+		Limit:         limit,
+	})
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"jobs": list}, nil)
+}
+
+// getJob returns one job, to the admin who queued it or a job manager
+func (app *application) getJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := app.readJob(w, r)
+	if !ok {
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"job": job}, nil)
+}
+
+// retryJob queues a dead job again with a fresh set of attempts
+func (app *application) retryJob(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermJobsManage)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	job, ok := app.readJob(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.jobs.Retry(r.Context(), job.ID); err != nil {
+		switch {
+		case errors.Is(err, jobs.ErrNotDead):
+			app.errorResponse(w, r, http.StatusConflict, codeJobNotRetryable, "Only jobs that have failed for good can be retried")
+		case errors.Is(err, jobs.ErrNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	app.logActivity(r, claims, "job_retried", "job", job.ID, map[string]interface{}{"kind": job.Kind})
+
+	job, err = app.jobs.Get(r.Context(), job.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"job": job}, nil)
+}
+
+// downloadJobResult serves the file written by a finished export job. The
+// caller's access is checked again against the export's filter, since the
+// admin who queued it may have lost access to those students since.
+func (app *application) downloadJobResult(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermStudentsRead)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	job, ok := app.readJob(w, r)
+	if !ok {
+		return
+	}
+
+	if job.Kind != jobStudentsExport {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	var payload exportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		app.serverErrorResponse(w, r, fmt.Errorf("job %d has an invalid payload: %w", job.ID, err))
+		return
+	}
+	if !app.canReadExport(claims, payload) {
+		app.authErrorResponse(w, r, errPermissionDenied)
+		return
+	}
+
+	if job.Status != jobs.StatusSucceeded {
+		app.errorResponse(w, r, http.StatusConflict, codeJobNotFinished, fmt.Sprintf("The export is %s; download it once it has succeeded", job.Status))
+		return
+	}
+
+	var result exportJobResult
+	if err := json.Unmarshal(job.Result, &result); err != nil || result.File == "" {
+		app.serverErrorResponse(w, r, fmt.Errorf("job %d has no export file: %v", job.ID, err))
+		return
+	}
+
+	f, err := os.Open(filepath.Join(app.exportDir(), filepath.Base(result.File)))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			app.notFoundResponse(w, r)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=students_export.csv")
+	http.ServeContent(w, r, "", *job.FinishedAt, f)
+}
+
+// readJob loads the job named in the URL, writing the error response itself
+// when the job is missing or the caller may not see it
+func (app *application) readJob(w http.ResponseWriter, r *http.Request) (*jobs.Job, bool) {
+	claims := app.contextGetClaims(r)
+
+	id, err := app.readIDParam(r, "id")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return nil, false
+	}
+
+	job, err := app.jobs.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			app.notFoundResponse(w, r)
+			return nil, false
+		}
+		app.serverErrorResponse(w, r, err)
+		return nil, false
+	}
+
+	if !app.canSeeJob(claims, job) {
+		app.notFoundResponse(w, r)
+		return nil, false
+	}
+	return job, true
+}
+
+// canSeeJob reports whether the job is in the caller's institution, and the
+// caller either queued it or manages jobs
+func (app *application) canSeeJob(claims *auth.Claims, job *jobs.Job) bool {
+	// Jobs that are not tied to one institution are for group admins only
+	if job.InstitutionID == nil {
+		if !claims.Can(auth.PermInstitutionsAll) {
+			return false
+		}
+	} else if !app.inInstitution(claims, *job.InstitutionID) {
+		return false
+	}
+
+	if job.CreatedByType != nil && *job.CreatedByType == claims.Role &&
+		job.CreatedByID != nil && *job.CreatedByID == claims.UserID {
+		return true
+	}
+	return claims.Can(auth.PermJobsManage)
+}
+
+// canReadExport reports whether the caller may still see every student an
+// export was built from
+func (app *application) canReadExport(claims *auth.Claims, payload exportJobPayload) bool {
+	if payload.InstitutionID == nil {
+		if !claims.Can(auth.PermInstitutionsAll) {
+			return false
+		}
+	} else if !app.inInstitution(claims, *payload.InstitutionID) {
+		return false
+	}
+
+	scope := app.departmentScope(claims)
+	if scope == nil {
+		return true
+	}
+	return *scope != "" && payload.Department != nil && *payload.Department == *scope
+}
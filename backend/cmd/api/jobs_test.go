@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/jobs"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+	"github.com/gorilla/mux"
+)
+
+func TestExportJob(t *testing.T) {
+	app := newTestApplication()
+	app.models = memstore.New().Models()
+	app.jobs = jobs.NewMemoryQueue()
+	app.config.Uploads.Dir = t.TempDir()
+
+	for _, name := range []string{"Asha", "Bala"} {
+		student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: strings.ToLower(name) + "@kct.ac.in", Name: name}
+		if err := app.models.Students.Insert(t.Context(), student); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	coordinator := &auth.Claims{UserID: 7, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID}
+	other := &auth.Claims{UserID: 8, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID}
+	superAdmin := &auth.Claims{UserID: 9, Role: "admin", AdminRole: auth.RoleSuperAdmin, InstitutionID: memstore.DefaultInstitutionID}
+
+	call := func(handler http.HandlerFunc, method string, id int64, claims *auth.Claims) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/admin/jobs/"+strconv.FormatInt(id, 10), nil)
+		r = mux.SetURLVars(r, map[string]string{"id": strconv.FormatInt(id, 10)})
+		r = app.contextSetClaims(r, claims)
+		rr := httptest.NewRecorder()
+		handler(rr, r)
+		return rr
+	}
+
+	rr := call(app.createExportJob, http.MethodPost, 0, coordinator)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("queueing the export: got %d, want %d", rr.Code, http.StatusAccepted)
+	}
+	var body struct{ Job jobs.Job }
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	id := body.Job.ID
+
+	if rr := call(app.downloadJobResult, http.MethodGet, id, coordinator); rr.Code != http.StatusConflict {
+		t.Errorf("download before the export ran: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+
+	// Run the queue until the export has finished
+	runner := jobs.NewRunner(app.jobs, app.logger)
+	runner.PollInterval = 10 * time.Millisecond
+	app.registerJobs(runner)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		job, err := app.jobs.Get(t.Context(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == jobs.StatusSucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("export did not finish: %+v", job)
+		}
+	}
+	cancel()
+	<-done
+
+	rr = call(app.downloadJobResult, http.MethodGet, id, coordinator)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("download: got %d, want %d", rr.Code, http.StatusOK)
+	}
+	if lines := strings.Count(rr.Body.String(), "\n"); lines != 3 {
+		t.Errorf("export has %d lines, want a header and 2 students:\n%s", lines, rr.Body.String())
+	}
+
+	// The download is checked against the caller's access now, not when the
+	// export was queued
+	demoted := *coordinator
+	demoted.AdminRole, demoted.Department = auth.RoleDepartmentCoordinator, "CSE"
+	if rr := call(app.downloadJobResult, http.MethodGet, id, &demoted); rr.Code != http.StatusForbidden {
+		t.Errorf("download after a demotion to one department: got %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	moved := *coordinator
+	moved.InstitutionID = memstore.DefaultInstitutionID + 1
+	if rr := call(app.downloadJobResult, http.MethodGet, id, &moved); rr.Code != http.StatusNotFound {
+		t.Errorf("download after moving institution: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if rr := call(app.getJob, http.MethodGet, id, &moved); rr.Code != http.StatusNotFound {
+		t.Errorf("reading the job after moving institution: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+
+	// Other admins only see the job if they manage jobs
+	if rr := call(app.getJob, http.MethodGet, id, other); rr.Code != http.StatusNotFound {
+		t.Errorf("another coordinator reading the job: got %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if rr := call(app.getJob, http.MethodGet, id, superAdmin); rr.Code != http.StatusOK {
+		t.Errorf("super admin reading the job: got %d, want %d", rr.Code, http.StatusOK)
+	}
+
+	// Only dead jobs can be retried, and only by job managers
+	if rr := call(app.retryJob, http.MethodPost, id, coordinator); rr.Code != http.StatusForbidden {
+		t.Errorf("coordinator retrying: got %d, want %d", rr.Code, http.StatusForbidden)
+	}
+	if rr := call(app.retryJob, http.MethodPost, id, superAdmin); rr.Code != http.StatusConflict {
+		t.Errorf("retrying a succeeded job: got %d, want %d", rr.Code, http.StatusConflict)
+	}
+}
@@ -1,140 +1,236 @@
 package main
 
 import (
+	"database/sql"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/config"
 	"github.com/VJ-2303/placement-profiling-system/internal/data"
+	"github.com/VJ-2303/placement-profiling-system/internal/jobs"
 	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+	"github.com/VJ-2303/placement-profiling-system/internal/schedule"
 )
 
-type config struct {
-	port string
-	env  string
-	db   struct {
-		dsn string
-	}
-	oauth struct {
-		clientID     string
-		clientSecret string
-		tenantID     string
-		redirectURL  string
-	}
-	jwt struct {
-		secret string
-	}
-	frontend struct {
-		url string
-	}
-	allowedDomain string // e.g., kct.ac.in
-}
-
 type application struct {
-	config     config
-	logger     *log.Logger
+	config     config.Config
+	logger     *slog.Logger
 	models     models.Models
-	msOAuth    *auth.MicrosoftOAuth
+	providers  map[string]auth.IdentityProvider
 	jwtService *auth.JWTService
+	// rateLimiter enforces per-API-key, per-user and per-IP request budgets
+	rateLimiter ratelimit.Limiter
+	metrics     *metrics
+	// jobs holds background work; jobRunner is nil when this process runs none
+	jobs      jobs.Queue
+	jobRunner *jobs.Runner
+	// scheduler runs maintenance tasks while this process holds the leader lock
+	scheduler *schedule.Scheduler
+	notifier  notifier
+	// draining is set once shutdown starts so /ready turns new traffic away
+	draining atomic.Bool
 }
 
 func main() {
-	var cfg config
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file; environment variables take precedence")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	flag.Parse()
 
-	cfg.port = getEnvWithDefault("PORT", "4000")
-	cfg.env = getEnvWithDefault("ENV", "development")
-	cfg.allowedDomain = getEnvWithDefault("ALLOWED_DOMAIN", "kct.ac.in")
-
-	// Database - support both DATABASE_URL (Neon/Railway style) and DB_DSN
-	cfg.db.dsn = os.Getenv("DATABASE_URL")
-	if cfg.db.dsn == "" {
-		cfg.db.dsn = os.Getenv("DB_DSN")
+	if flag.NArg() > 0 && flag.Arg(0) != "migrate" {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", flag.Arg(0), migrateUsage)
+		os.Exit(2)
 	}
 
-	// Microsoft OAuth - support both naming conventions
-	cfg.oauth.clientID = os.Getenv("MICROSOFT_CLIENT_ID")
-	if cfg.oauth.clientID == "" {
-		cfg.oauth.clientID = os.Getenv("CLIENT_ID")
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		if err := cfg.Write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// JSON logs; anything still written through the log package goes here too
+	logger := newLogger(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)
+
+	logger.Info("placement profiling system starting",
+		"version", version,
+		"env", cfg.Env,
+		"port", cfg.Port,
+		"config_file", *configFile,
+		"allowed_domain", cfg.AllowedDomain,
+		"frontend_url", cfg.Frontend.URL,
+		"oauth_tenant", cfg.Microsoft.TenantID,
+		"oauth_redirect", cfg.Microsoft.RedirectURL,
+		"rate_limit_backend", cfg.RateLimit.Backend,
+		"trusted_proxies", len(cfg.RateLimit.TrustedProxyNets),
+	)
+	for _, p := range cfg.OIDC {
+		logger.Info("oidc provider", "name", p.Name, "issuer", p.Issuer, "domains", p.AllowedDomains)
 	}
 
-	cfg.oauth.clientSecret = os.Getenv("MICROSOFT_CLIENT_SECRET")
-	if cfg.oauth.clientSecret == "" {
-		cfg.oauth.clientSecret = os.Getenv("CLIENT_SECRET")
+	// Open database connection
+	db, err := data.OpenDB(cfg.DB.DSN, data.PoolConfig{
+		MaxOpenConns: cfg.DB.MaxOpenConns,
+		MaxIdleConns: cfg.DB.MaxIdleConns,
+		MaxIdleTime:  cfg.DB.MaxIdleTime,
+	})
+	if err != nil {
+		logger.Error("database connection failed", "error", err)
+		os.Exit(1)
 	}
+	defer db.Close()
 
-	cfg.oauth.tenantID = os.Getenv("MICROSOFT_TENANT_ID")
+	logger.Info("database connection established", "max_open_conns", cfg.DB.MaxOpenConns, "max_idle_conns", cfg.DB.MaxIdleConns)
 
-	cfg.oauth.redirectURL = os.Getenv("MICROSOFT_REDIRECT_URL")
-	if cfg.oauth.redirectURL == "" {
-		cfg.oauth.redirectURL = getEnvWithDefault("OAUTH_REDIRECT_URL", "http://localhost:4000/auth/callback")
+	// `api migrate ...` manages the schema and exits instead of serving
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(db, flag.Args()[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			db.Close()
+			os.Exit(1)
+		}
+		return
 	}
 
-	cfg.jwt.secret = os.Getenv("JWT_SECRET")
-	cfg.frontend.url = getEnvWithDefault("FRONTEND_URL", "http://localhost:5500")
-
-	// Validate required env vars
-	if cfg.db.dsn == "" {
-		log.Fatal("DATABASE_URL or DB_DSN environment variable is required")
-	}
-	if cfg.oauth.clientID == "" {
-		log.Fatal("MICROSOFT_CLIENT_ID or CLIENT_ID environment variable is required")
-	}
-	if cfg.oauth.clientSecret == "" {
-		log.Fatal("MICROSOFT_CLIENT_SECRET or CLIENT_SECRET environment variable is required")
-	}
-	if cfg.jwt.secret == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+	if err := checkSchema(db); err != nil {
+		logger.Error("database schema check failed; run `api migrate up`", "error", err)
+		os.Exit(1)
 	}
 
-	// Initialize logger
-	logger := log.New(os.Stdout, "[PPS] ", log.Ldate|log.Ltime|log.Lshortfile)
-
-	logger.Println("=== Placement Profiling System ===")
-	logger.Printf("Environment: %s", cfg.env)
-	logger.Printf("Port: %s", cfg.port)
-	logger.Printf("Allowed Domain: %s", cfg.allowedDomain)
-	logger.Printf("Frontend URL: %s", cfg.frontend.url)
-	logger.Printf("OAuth Redirect: %s", cfg.oauth.redirectURL)
-
-	// Open database connection
-	db, err := data.OpenDB(cfg.db.dsn)
+	jwtService, err := newJWTService(cfg)
 	if err != nil {
-		logger.Fatal(err)
+		logger.Error("loading JWT keys failed", "error", err)
+		os.Exit(1)
+	}
+	if jwtService.Keys != nil {
+		logger.Info("JWT signing key", "kid", jwtService.Keys.Active.ID, "algorithm", jwtService.Keys.Active.Algorithm)
+	} else {
+		logger.Info("JWT signing with HS256 shared secret")
 	}
-	defer db.Close()
 
-	logger.Println("Database connection established")
+	// Model calls run under the request context, bounded by these timeouts
+	modelsDB := &models.DB{
+		DB: db,
+		Timeouts: models.Timeouts{
+			Read:      cfg.DB.ReadTimeout,
+			Write:     cfg.DB.WriteTimeout,
+			Analytics: cfg.DB.AnalyticsTimeout,
+			Export:    cfg.DB.ExportTimeout,
+		},
+		SlowQuery: cfg.DB.SlowQuery,
+		Logger:    logger,
+	}
 
 	// Initialize application struct
 	app := &application{
-		config:     cfg,
-		logger:     logger,
-		models:     models.NewModels(db),
-		msOAuth:    auth.NewMicrosoftOAuth(cfg.oauth.clientID, cfg.oauth.clientSecret, cfg.oauth.redirectURL),
-		jwtService: auth.NewJWTService(cfg.jwt.secret),
+		config:      *cfg,
+		logger:      logger,
+		models:      models.NewModels(modelsDB),
+		providers:   newIdentityProviders(cfg),
+		jwtService:  jwtService,
+		rateLimiter: newRateLimiter(cfg, db),
+		metrics:     newMetrics(),
+		jobs:        jobs.NewPostgresQueue(db),
+		notifier:    logNotifier{logger: logger},
+	}
+	app.metrics.registerDB(db, app)
+
+	// ANALYTICS_CACHE_TTL=0 sends every dashboard load to the database
+	if cfg.Analytics.CacheTTL > 0 {
+		var cache *models.AnalyticsCache
+		app.models, cache = models.WithAnalyticsCache(app.models, cfg.Analytics.CacheTTL)
+		app.metrics.registerAnalyticsCache(cache)
+	}
+
+	// JOB_WORKERS=0 leaves the queue to other replicas
+	if cfg.Jobs.Workers > 0 {
+		app.jobRunner = jobs.NewRunner(app.jobs, logger)
+		app.jobRunner.Workers = cfg.Jobs.Workers
+		app.jobRunner.PollInterval = cfg.Jobs.PollInterval
+		app.jobRunner.Lease = cfg.Jobs.Lease
+		app.registerJobs(app.jobRunner)
+	}
+
+	// Every replica knows the tasks so any of them can report on the
+	// schedule; SCHEDULE_ENABLED decides whether it competes to run them
+	app.scheduler = schedule.New(schedule.NewPostgresLock(db), schedule.NewPostgresHistory(db), cfg.Schedule.Location, logger)
+	if err := app.registerTasks(app.scheduler); err != nil {
+		logger.Error("registering scheduled tasks failed", "error", err)
+		os.Exit(1)
 	}
 
 	// Start server
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.port),
+		Addr:         fmt.Sprintf(":%d", cfg.Port),
 		Handler:      app.routes(),
 		IdleTimeout:  time.Minute,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 60 * time.Second,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
-	logger.Printf("Starting %s server on port %s", cfg.env, cfg.port)
-	err = srv.ListenAndServe()
-	logger.Fatal(err)
+	if err := app.serve(srv); err != nil {
+		logger.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
 
-func getEnvWithDefault(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// newJWTService signs with the asymmetric key set when one is configured, and
+// falls back to the shared HS256 secret otherwise
+func newJWTService(cfg *config.Config) (*auth.JWTService, error) {
+	var j *auth.JWTService
+	if cfg.JWT.KeysDir == "" {
+		j = auth.NewJWTService(cfg.JWT.Secret)
+	} else {
+		keys, err := auth.LoadKeySet(cfg.JWT.KeysDir, cfg.JWT.ActiveKID)
+		if err != nil {
+			return nil, err
+		}
+		// Config validation requires a deadline whenever the secret is kept
+		legacyUntil, _, _ := cfg.JWT.LegacyHS256Deadline()
+		j = auth.NewAsymmetricJWTService(keys, cfg.JWT.Issuer, cfg.JWT.Secret, legacyUntil)
+	}
+
+	j.AccessTokenTTL = cfg.Tokens.AccessTTL
+	j.RefreshTokenTTL = cfg.Tokens.RefreshTTL
+	j.ImpersonationTokenTTL = cfg.Tokens.ImpersonationTTL
+	return j, nil
+}
+
+// newRateLimiter keeps buckets in memory unless replicas must share them through Postgres
+func newRateLimiter(cfg *config.Config, db *sql.DB) ratelimit.Limiter {
+	if cfg.RateLimit.Backend == "postgres" {
+		return ratelimit.NewPostgresLimiter(db)
+	}
+	return ratelimit.NewMemoryLimiter()
+}
+
+// newIdentityProviders builds the Microsoft provider plus any configured OIDC providers
+func newIdentityProviders(cfg *config.Config) map[string]auth.IdentityProvider {
+	ms := cfg.Microsoft
+	providers := map[string]auth.IdentityProvider{
+		auth.MicrosoftProviderName: auth.NewMicrosoftOAuth(
+			ms.ClientID, ms.ClientSecret, ms.TenantID, ms.RedirectURL, ms.AllowedDomains,
+		),
 	}
-	return value
+
+	for _, p := range cfg.OIDC {
+		providers[p.Name] = auth.NewOIDCProvider(p.Name, p.Issuer, p.ClientID, p.ClientSecret, p.RedirectURL, p.AllowedDomains)
+	}
+
+	return providers
 }
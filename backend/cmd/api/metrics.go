@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics holds the Prometheus collectors served on /metrics. Each
+// application has its own registry so tests do not share global state.
+type metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	legacy   *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pps_http_requests_total",
+			Help: "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pps_http_request_duration_seconds",
+			Help:    "HTTP request latency by route template and method.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"route", "method"}),
+		legacy: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pps_legacy_requests_total",
+			Help: "Requests made on unversioned paths, by the /api/v1 route they map to.",
+		}, []string{"route"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.legacy,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// registerDB adds connection pool gauges and the business gauges read from the database
+func (m *metrics) registerDB(db *sql.DB, app *application) {
+	m.registry.MustRegister(
+		collectors.NewDBStatsCollector(db, "placement"),
+		&businessCollector{app: app},
+	)
+}
+
+// registerAnalyticsCache counts the analytics lookups the cache answered and
+// the ones that went to the database
+func (m *metrics) registerAnalyticsCache(cache *models.AnalyticsCache) {
+	for _, result := range []string{"hit", "miss"} {
+		m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "pps_analytics_cache_requests_total",
+			Help:        "Analytics queries by whether the cache answered them.",
+			ConstLabels: prometheus.Labels{"result": result},
+		}, func() float64 {
+			hits, misses := cache.Stats()
+			if result == "hit" {
+				return float64(hits)
+			}
+			return float64(misses)
+		}))
+	}
+}
+
+// observe records one finished request. Unmatched paths share a single label
+// so scanners cannot blow up the number of series.
+func (m *metrics) observe(route, method string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	m.requests.WithLabelValues(route, method, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(route, method).Observe(elapsed.Seconds())
+}
+
+// ============================================
+// BUSINESS GAUGES
+// ============================================
+
+var (
+	studentsRegisteredDesc = prometheus.NewDesc("pps_students_registered",
+		"Students who have signed in at least once.", []string{"institution"}, nil)
+	profilesCompletedDesc = prometheus.NewDesc("pps_profiles_completed",
+		"Students who have completed their profile.", []string{"institution"}, nil)
+	placementsRecordedDesc = prometheus.NewDesc("pps_placements_recorded",
+		"Accepted placement offers.", []string{"institution"}, nil)
+)
+
+// businessCollector queries the headline counts on every scrape
+type businessCollector struct {
+	app *application
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- studentsRegisteredDesc
+	ch <- profilesCompletedDesc
+	ch <- placementsRecordedDesc
+}
+
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	totals, err := c.app.models.Analytics.GetInstitutionTotals(context.Background())
+	if err != nil {
+		c.app.logger.Error("collecting business metrics failed", "error", err)
+		return
+	}
+
+	for _, t := range totals {
+		ch <- prometheus.MustNewConstMetric(studentsRegisteredDesc, prometheus.GaugeValue, float64(t.StudentsRegistered), t.InstitutionCode)
+		ch <- prometheus.MustNewConstMetric(profilesCompletedDesc, prometheus.GaugeValue, float64(t.ProfilesCompleted), t.InstitutionCode)
+		ch <- prometheus.MustNewConstMetric(placementsRecordedDesc, prometheus.GaugeValue, float64(t.PlacementsRecorded), t.InstitutionCode)
+	}
+}
+
+// ============================================
+// HANDLERS
+// ============================================
+
+// metricsHandler serves the registry in the Prometheus text format
+func (app *application) metricsHandler() http.Handler {
+	return promhttp.HandlerFor(app.metrics.registry, promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(app.logger.Handler(), slog.LevelError),
+	})
+}
+
+// requireMetricsToken only lets scrapers presenting METRICS_TOKEN through.
+// Without a configured token the endpoint does not exist.
+func (app *application) requireMetricsToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.config.Metrics.Token == "" {
+			app.notFoundResponse(w, r)
+			return
+		}
+
+		token, err := app.extractTokenFromHeader(r)
+		if err != nil || subtle.ConstantTimeCompare([]byte(token), []byte(app.config.Metrics.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			app.unauthorizedResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	app := newTestApplication()
+	app.config.Metrics.Token = "scrape-secret"
+	handler := app.routes()
+
+	cache := models.NewAnalyticsCache(memstore.New().Models().Analytics, time.Minute)
+	app.metrics.registerAnalyticsCache(cache)
+	for range 2 {
+		if _, err := cache.GetDashboardStats(t.Context(), nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(path, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	get("/health", "")
+
+	if rr := get("/metrics", ""); rr.Code != http.StatusUnauthorized {
+		t.Errorf("without a token: got %d, want 401", rr.Code)
+	}
+	if rr := get("/metrics", "wrong"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("with a wrong token: got %d, want 401", rr.Code)
+	}
+
+	rr := get("/metrics", "scrape-secret")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("with the token: got %d, want 200", rr.Code)
+	}
+
+	for _, want := range []string{
+		`pps_http_requests_total{method="GET",route="/health",status="200"} 1`,
+		`pps_analytics_cache_requests_total{result="hit"} 1`,
+		`pps_analytics_cache_requests_total{result="miss"} 1`,
+	} {
+		if !strings.Contains(rr.Body.String(), want) {
+			t.Errorf("metrics output is missing %s", want)
+		}
+	}
+}
+
+func TestMetricsDisabledWithoutToken(t *testing.T) {
+	handler := newTestApplication().routes()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("got %d, want 404", rr.Code)
+	}
+}
@@ -1,8 +1,17 @@
 package main
 
 import (
+	"fmt"
+	"net"
 	"net/http"
+	"net/url"
+	"runtime/debug"
 	"strings"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+	"github.com/gorilla/mux"
 )
 
 // enableCORS enables Cross-Origin Resource Sharing
@@ -10,24 +19,17 @@ func (app *application) enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
-		// Allow requests from configured frontend URL or localhost for development
-		allowedOrigins := []string{
-			app.config.frontend.url,
-			"http://localhost:3000",
-			"http://localhost:5173",
-			"http://localhost:8080",
-			"http://127.0.0.1:5500", // VS Code Live Server
-		}
-
-		for _, allowed := range allowedOrigins {
-			if allowed != "" && origin == allowed {
+		// Allow requests from the configured origins (frontend URL and local dev servers by default)
+		for _, allowed := range app.config.CORS.AllowedOrigins {
+			if origin == allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				break
 			}
 		}
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-CSRF-Token, X-Request-ID, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Retry-After, Deprecation, Sunset, Link, ETag")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 		w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
@@ -53,13 +55,18 @@ func (app *application) secureHeaders(next http.Handler) http.Handler {
 	})
 }
 
-// recoverPanic recovers from panics and returns a 500 response
+// recoverPanic recovers from panics, logs the stack trace and returns a 500 response
 func (app *application) recoverPanic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
 				w.Header().Set("Connection", "close")
-				app.logger.Printf("PANIC: %v", err)
+				app.logger.ErrorContext(r.Context(), "panic",
+					"error", fmt.Sprint(err),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"stack", string(debug.Stack()),
+				)
 				app.serverErrorResponse(w, r, nil)
 			}
 		}()
@@ -68,55 +75,287 @@ func (app *application) recoverPanic(next http.Handler) http.Handler {
 	})
 }
 
-// logRequests logs all HTTP requests
+// requestID gives every request an ID, echoed in the X-Request-ID header. An
+// ID set by the load balancer is kept so logs can be correlated across hops.
+func (app *application) requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, contextSetRequestInfo(r, &requestInfo{id: id}))
+	})
+}
+
+// logRequests records the status and latency of every response in the
+// request metrics, and writes an access log line with its size and the user
+// who made it
 func (app *application) logRequests(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Skip logging for health checks
-		if r.URL.Path == "/health" {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+
+		info := requestInfoFromContext(r.Context())
+		var route string
+		if info != nil {
+			route = info.route
+		}
+		app.metrics.observe(route, r.Method, rec.status, elapsed)
+
+		// Skip logging for probes
+		if r.URL.Path == "/health" || r.URL.Path == "/ready" {
+			return
+		}
+
+		attrs := []any{
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", float64(elapsed.Microseconds()) / 1000,
+			"remote_ip", clientIP(r),
+		}
+		if info != nil && info.claims != nil {
+			attrs = append(attrs, "user_id", info.claims.UserID, "role", info.claims.Role)
+		}
+
+		app.logger.InfoContext(r.Context(), "request", attrs...)
+	})
+}
+
+// recordRoute notes which route template matched, for metrics labels
+func (app *application) recordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info := requestInfoFromContext(r.Context()); info != nil {
+			if route := mux.CurrentRoute(r); route != nil {
+				info.route, _ = route.GetPathTemplate()
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// legacyPaths serves the unversioned paths that predate /api/v1 by rewriting
+// them to their /api/v1 equivalent. Responses are marked deprecated, point to
+// the new path and keep the old error shape. With legacy paths switched off
+// they answer 410 Gone instead.
+func (app *application) legacyPaths(next http.Handler) http.Handler {
+	var sunset string
+	if date, ok, _ := app.config.API.SunsetDate(); ok {
+		sunset = date.Format(http.TimeFormat)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		successor, ok := v1Path(r.URL.Path)
+		if !ok {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		app.logger.Printf("%s - %s %s %s", r.RemoteAddr, r.Proto, r.Method, r.URL.RequestURI())
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+		if !app.config.API.LegacyPaths {
+			app.errorResponse(w, r, http.StatusGone, codeEndpointRetired, "this path has been retired; use "+successor)
+			return
+		}
+
+		w.Header().Set("Deprecation", "true")
+		if sunset != "" {
+			w.Header().Set("Sunset", sunset)
+		}
+
+		info := requestInfoFromContext(r.Context())
+		if info != nil {
+			info.legacy = true
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = successor
+		r2.URL.RawPath = ""
+
+		next.ServeHTTP(w, r2)
+
+		if info != nil && info.route != "" {
+			app.metrics.legacy.WithLabelValues(info.route).Inc()
+		}
+	})
+}
+
+// v1Path maps an unversioned API path to its /api/v1 equivalent. Probes,
+// /metrics and the JWKS are not versioned and are left alone.
+func v1Path(path string) (string, bool) {
+	switch {
+	case path == apiV1 || strings.HasPrefix(path, apiV1+"/"):
+		return "", false
+	case path == "/auth" || strings.HasPrefix(path, "/auth/"):
+		return apiV1 + path, true
+	case strings.HasPrefix(path, "/api/"):
+		return apiV1 + strings.TrimPrefix(path, "/api"), true
+	}
+	return "", false
+}
+
+// statusRecorder remembers the status code and body size written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// realIP replaces RemoteAddr with the client address forwarded by trusted
+// proxies, so logging, auditing and rate limits see the real client
+func (app *application) realIP(next http.Handler) http.Handler {
+	if len(app.config.RateLimit.TrustedProxyNets) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if peer := clientIP(r); peer != "" {
+			ip := forwardedClientIP(peer, r.Header.Values("X-Forwarded-For"), app.config.RateLimit.TrustedProxyNets)
+			r.RemoteAddr = net.JoinHostPort(ip, "0")
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
-// authenticate extracts and validates JWT token from request
+// rateLimit applies a token bucket per signed-in user, or per client IP for
+// anonymous requests, to a group of routes. perMinute <= 0 disables the limit.
+// API keys are skipped; they have their own per-key limit.
+func (app *application) rateLimit(group string, perMinute int) mux.MiddlewareFunc {
+	limit := ratelimit.PerMinute(perMinute)
+
+	return func(next http.Handler) http.Handler {
+		if perMinute <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := "ip:" + clientIP(r)
+			if claims := app.contextGetClaims(r); claims != nil {
+				if claims.Role == auth.RoleService {
+					next.ServeHTTP(w, r)
+					return
+				}
+				key = fmt.Sprintf("%s:%d", claims.Role, claims.UserID)
+			}
+
+			result, err := app.rateLimiter.Allow(r.Context(), group+":"+key, limit)
+			if err != nil {
+				// A limiter outage should not take the API down with it
+				app.logger.ErrorContext(r.Context(), "rate limiter failed", "group", group, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !result.Allowed {
+				app.rateLimitExceededResponse(w, r, result.RetryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticate validates the caller's access token or API key and stores the
+// claims in the request context. Requests without valid credentials are rejected.
 func (app *application) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Add Vary header for caching
 		w.Header().Add("Vary", "Authorization")
 
-		// Get the authorization header
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			next.ServeHTTP(w, r)
+		claims, err := app.extractAndValidateToken(r)
+		if err != nil {
+			app.authErrorResponse(w, r, err)
 			return
 		}
 
-		// Check for Bearer token
-		headerParts := strings.Split(authHeader, " ")
-		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-			app.invalidAuthenticationTokenResponse(w, r)
+		next.ServeHTTP(w, app.contextSetClaims(r, claims))
+	})
+}
+
+// requireStudent lets only students through
+func (app *application) requireStudent(next http.Handler) http.Handler {
+	return app.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if app.contextGetClaims(r).Role != "student" {
+			app.forbiddenResponse(w, r)
 			return
 		}
 
-		token := headerParts[1]
+		next.ServeHTTP(w, r)
+	}))
+}
 
-		// Validate the token
-		_, err := app.jwtService.ValidateToken(token)
-		if err != nil {
-			app.invalidAuthenticationTokenResponse(w, r)
+// requireStaff lets only admins and API keys through. Handlers still check the
+// permission each action needs.
+func (app *application) requireStaff(next http.Handler) http.Handler {
+	return app.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		role := app.contextGetClaims(r).Role
+		if role != "admin" && role != auth.RoleService {
+			app.forbiddenResponse(w, r)
 			return
 		}
 
 		next.ServeHTTP(w, r)
+	}))
+}
+
+// allowAnonymous marks routes that anyone may call
+func (app *application) allowAnonymous(next http.Handler) http.Handler {
+	return next
+}
+
+// failClosed refuses every route that is not registered under one of the
+// guarded subrouters, so a handler added without a guard is never exposed
+func (app *application) failClosed(router *mux.Router, guarded map[*mux.Route]bool) {
+	router.Walk(func(route *mux.Route, _ *mux.Router, ancestors []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		for _, ancestor := range ancestors {
+			if guarded[ancestor] {
+				return nil
+			}
+		}
+
+		path, _ := route.GetPathTemplate()
+		app.logger.Warn("route has no access guard and will refuse every request", "path", path)
+		route.HandlerFunc(app.forbiddenResponse)
+		return nil
 	})
 }
 
 // invalidAuthenticationTokenResponse sends an invalid token error response
 func (app *application) invalidAuthenticationTokenResponse(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("WWW-Authenticate", "Bearer")
-	app.errorResponse(w, r, http.StatusUnauthorized, "invalid or missing authentication token")
+	app.errorResponse(w, r, http.StatusUnauthorized, codeInvalidToken, "invalid or missing authentication token")
 }
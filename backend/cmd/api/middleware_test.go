@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/config"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+)
+
+func TestForwardedClientIP(t *testing.T) {
+	trusted, err := config.ParseCIDRs([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		want         string
+	}{
+		{"untrusted peer ignores header", "203.0.113.9", []string{"1.2.3.4"}, "203.0.113.9"},
+		{"trusted peer", "10.0.0.5", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed entry on the left", "10.0.0.5", []string{"1.2.3.4, 198.51.100.7"}, "198.51.100.7"},
+		{"chain of proxies", "10.0.0.5", []string{"198.51.100.7, 192.168.1.1", "10.1.1.1"}, "198.51.100.7"},
+		{"no header", "10.0.0.5", nil, "10.0.0.5"},
+		{"garbage hop", "10.0.0.5", []string{"not-an-ip"}, "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		if got := forwardedClientIP(tt.peer, tt.forwardedFor, trusted); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	app := newTestApplication()
+	app.rateLimiter = ratelimit.NewMemoryLimiter()
+
+	handler := app.rateLimit("test", 2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, r)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := send("198.51.100.7:1234"); rr.Code != http.StatusOK {
+			t.Fatalf("request %d: got %d, want 200", i+1, rr.Code)
+		}
+	}
+
+	rr := send("198.51.100.7:1234")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("got %d, want 429", rr.Code)
+	}
+	if rr.Header().Get("Retry-After") != "30" {
+		t.Errorf("got Retry-After %q, want 30", rr.Header().Get("Retry-After"))
+	}
+
+	if rr := send("198.51.100.8:1234"); rr.Code != http.StatusOK {
+		t.Errorf("another client was limited: got %d", rr.Code)
+	}
+}
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	app := newTestApplication()
+	var logs strings.Builder
+	app.logger = newLogger(&logs, "info")
+
+	handler := app.requestID(app.logRequests(app.recoverPanic(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))))
+
+	r := httptest.NewRequest(http.MethodGet, apiV1+"/admin/dashboard", nil)
+	r.Header.Set("X-Request-ID", "lb-123")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, r)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Fatalf("got %d, want 500", rr.Code)
+	}
+	if got := rr.Header().Get("X-Request-ID"); got != "lb-123" {
+		t.Errorf("got X-Request-ID %q, want the upstream ID", got)
+	}
+
+	var body struct {
+		Error apiError `json:"error"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.RequestID != "lb-123" || body.Error.Code != codeInternalError {
+		t.Errorf("error envelope has request_id %q and code %q", body.Error.RequestID, body.Error.Code)
+	}
+
+	var panicLine, accessLine map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %s", line)
+		}
+		switch entry["msg"] {
+		case "panic":
+			panicLine = entry
+		case "request":
+			accessLine = entry
+		}
+	}
+
+	if panicLine == nil || panicLine["request_id"] != "lb-123" || !strings.Contains(fmt.Sprint(panicLine["stack"]), "goroutine") {
+		t.Errorf("panic log missing request ID or stack: %v", panicLine)
+	}
+	if accessLine == nil || accessLine["status"] != float64(500) || accessLine["request_id"] != "lb-123" {
+		t.Errorf("access log missing status or request ID: %v", accessLine)
+	}
+}
+
+func TestRequestIDRejectsUnsafeValues(t *testing.T) {
+	for _, id := range []string{"", "has space", "new\nline", strings.Repeat("a", 65)} {
+		if validRequestID(id) {
+			t.Errorf("validRequestID(%q) = true", id)
+		}
+	}
+	if !validRequestID("3f2a-01.b_c") {
+		t.Error("rejected a valid request ID")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/migrate"
+	"github.com/VJ-2303/placement-profiling-system/migrations"
+)
+
+const migrateUsage = `usage: api [flags] migrate <command>
+
+commands:
+  up                apply every pending migration
+  down [N]          revert the last N migrations (default 1)
+  status            list migrations and when each was applied
+  baseline VERSION  record migrations up to VERSION as applied without running
+                    them, for a database that was set up by hand`
+
+// newMigrator loads the migrations embedded in the binary
+func newMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	return migrate.New(db, list), nil
+}
+
+// runMigrate handles the migrate subcommand; args are what follows "migrate"
+func runMigrate(db *sql.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C aborts the current migration; its transaction rolls back
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Fprintf(out, "applied %03d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("down: %q is not a positive number of steps", args[1])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			fmt.Fprintf(out, "reverted %03d_%s\n", mig.Version, mig.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%03d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return tw.Flush()
+
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New("baseline: missing VERSION")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("baseline: %q is not a version", args[1])
+		}
+		if err := m.Baseline(ctx, version); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "recorded migrations up to %03d as applied\n", version)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], migrateUsage)
+	}
+}
+
+// checkSchema refuses to serve on a database that is missing migrations this
+// build depends on
+func checkSchema(db *sql.DB) error {
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return m.Check(ctx)
+}
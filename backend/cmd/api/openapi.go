@@ -0,0 +1,683 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/jobs"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/schedule"
+)
+
+// ============================================
+// OPENAPI DOCUMENT
+// ============================================
+//
+// Every route registered in routes() has an entry in apiOperations. Request
+// and response schemas are generated from the Go types the handlers decode
+// into and encode, so they change when the code does. TestOpenAPICoversRoutes
+// fails when a route is added or removed without updating the table.
+
+// apiParam is a query string parameter
+type apiParam struct {
+	Name        string
+	Type        string // integer, number, boolean or string
+	Description string
+}
+
+// apiOperation documents one method on one route
+type apiOperation struct {
+	Method  string
+	Path    string // the route's path with any {id:[0-9]+} patterns reduced to {id}
+	Tag     string
+	Summary string
+	Auth    []string // accepted security schemes; empty for public routes
+	Query   []apiParam
+	Request any // a value of the type the handler decodes the JSON body into
+	Status  int // success status, 200 when zero
+	// Response is the success body: a model value, a fields shape or a
+	// listOf. ContentType overrides application/json for other bodies.
+	Response    any
+	ContentType string
+	Errors      []int // error statuses besides the 401 and 500 every route can return
+	// ETag marks handlers that write with writeJSONWithETag: the response
+	// carries an ETag, and a matching If-None-Match gets 304 with no body
+	ETag bool
+}
+
+// fields describes a JSON object by example: each value's type becomes the
+// schema of that property. Handlers build their responses from envelopes, so
+// this mirrors the envelope they write.
+type fields map[string]any
+
+// listOf describes a JSON array of item
+type listOf struct{ item any }
+
+// Security schemes accepted by the API
+var (
+	userAuth    = []string{"bearerAuth", "cookieAuth"}
+	staffAuth   = []string{"bearerAuth", "cookieAuth", "apiKey"}
+	metricsAuth = []string{"metricsToken"}
+)
+
+// Query parameters shared by several routes
+var (
+	batchParam       = apiParam{"batch", "integer", "Only include students of this batch year"}
+	institutionParam = apiParam{"institution", "integer", "Group admins only: limit the results to one institution"}
+)
+
+var messageResponse = fields{"message": ""}
+
+var apiOperations = []apiOperation{
+	// Probes, metrics and keys
+	{Method: "GET", Path: "/health", Tag: "System", Summary: "Liveness probe with build information",
+		Response: fields{"status": "", "service": "", "env": "", "build": fields{"version": "", "commit": "", "build_time": "", "go_version": ""}}},
+	{Method: "GET", Path: "/ready", Tag: "System", Summary: "Readiness probe: database, upload storage and shutdown state",
+		Response: fields{"status": "", "checks": fields{"database": "", "storage": ""}}, Errors: []int{503}},
+	{Method: "GET", Path: "/metrics", Tag: "System", Summary: "Prometheus metrics; 404 unless METRICS_TOKEN is set", Auth: metricsAuth,
+		Response: "", ContentType: "text/plain", Errors: []int{404}},
+	{Method: "GET", Path: "/.well-known/jwks.json", Tag: "System", Summary: "Public keys that verify access tokens",
+		Response: auth.JSONWebKeySet{}, ContentType: "application/jwk-set+json"},
+	{Method: "GET", Path: "/api/v1/openapi.json", Tag: "System", Summary: "This document",
+		Response: fields{}},
+	{Method: "GET", Path: "/api/v1/docs", Tag: "System", Summary: "API reference page for this document",
+		Response: "", ContentType: "text/html"},
+
+	// Auth
+	{Method: "GET", Path: "/api/v1/auth/providers", Tag: "Auth", Summary: "List the identity providers students and staff can sign in with",
+		Response: fields{"providers": listOf{fields{"name": "", "login_url": "", "allowed_domains": []string{}}}}},
+	{Method: "GET", Path: "/api/v1/auth/login/{provider}", Tag: "Auth", Summary: "Start a sign-in with an identity provider",
+		Status: http.StatusTemporaryRedirect, Errors: []int{404, 429}},
+	{Method: "GET", Path: "/api/v1/auth/callback/{provider}", Tag: "Auth", Summary: "Identity provider callback; redirects to the frontend with a one-time login code",
+		Query:  []apiParam{{"code", "string", "Authorization code"}, {"state", "string", "State from the login redirect"}, {"error", "string", "Error reported by the provider"}, {"error_description", "string", ""}},
+		Status: http.StatusTemporaryRedirect, Errors: []int{404, 429}},
+	{Method: "GET", Path: "/api/v1/auth/login", Tag: "Auth", Summary: "Start a Microsoft sign-in (legacy route)",
+		Status: http.StatusTemporaryRedirect, Errors: []int{429}},
+	{Method: "GET", Path: "/api/v1/auth/callback", Tag: "Auth", Summary: "Microsoft callback (legacy route registered in Azure AD)",
+		Query:  []apiParam{{"code", "string", "Authorization code"}, {"state", "string", "State from the login redirect"}, {"error", "string", "Error reported by the provider"}, {"error_description", "string", ""}},
+		Status: http.StatusTemporaryRedirect, Errors: []int{429}},
+	{Method: "POST", Path: "/api/v1/auth/exchange", Tag: "Auth", Summary: "Trade a one-time login code for a session; mode \"cookie\" keeps the tokens in HttpOnly cookies",
+		Request:  exchangeInput{},
+		Response: fields{"token": "", "refresh_token": "", "csrf_token": "", "expires_in": 0, "role": ""}, Errors: []int{400, 429}},
+	{Method: "POST", Path: "/api/v1/auth/refresh", Tag: "Auth", Summary: "Rotate the refresh token and issue a new access token; cookie sessions send X-CSRF-Token instead of a body",
+		Request:  refreshInput{},
+		Response: fields{"token": "", "refresh_token": "", "csrf_token": "", "expires_in": 0, "role": ""}, Errors: []int{400, 403, 429}},
+	{Method: "GET", Path: "/api/v1/auth/me", Tag: "Auth", Summary: "The signed-in student, admin or API key", Auth: staffAuth,
+		Response: fields{"user": fields{}, "role": "", "permissions": []string{}, "institution": models.Institution{}, "impersonated_by": auth.Actor{}, "api_key": fields{"id": 0, "name": ""}}, Errors: []int{403}},
+	{Method: "POST", Path: "/api/v1/auth/logout", Tag: "Auth", Summary: "Revoke the current session and clear session cookies", Auth: userAuth,
+		Response: messageResponse, Errors: []int{400, 403}},
+
+	// Student
+	{Method: "GET", Path: "/api/v1/student/profile", Tag: "Student", Summary: "The signed-in student's full profile", Auth: userAuth,
+		Response: fields{"profile": models.StudentFullProfile{}}, Errors: []int{403, 404, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile", Tag: "Student", Summary: "Update basic student details", Auth: userAuth,
+		Request: studentProfileInput{}, Response: fields{"student": models.Student{}}, Errors: []int{400, 403, 409, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/personal", Tag: "Student", Summary: "Save personal details", Auth: userAuth,
+		Request: personalDetailsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/family", Tag: "Student", Summary: "Save family details", Auth: userAuth,
+		Request: familyDetailsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/academics", Tag: "Student", Summary: "Save academic details", Auth: userAuth,
+		Request: academicsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/achievements", Tag: "Student", Summary: "Save achievements", Auth: userAuth,
+		Request: achievementsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/aspirations", Tag: "Student", Summary: "Save career aspirations", Auth: userAuth,
+		Request: aspirationsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/student/profile/skills", Tag: "Student", Summary: "Replace the student's skills", Auth: userAuth,
+		Request: skillsInput{}, Response: messageResponse, Errors: []int{400, 403, 422, 429}},
+	{Method: "POST", Path: "/api/v1/student/profile/complete", Tag: "Student", Summary: "Mark the profile as complete", Auth: userAuth,
+		Response: messageResponse, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/student/photo", Tag: "Student", Summary: "Upload a profile photo as multipart field \"photo\" or as base64 JSON", Auth: userAuth,
+		Request: photoInput{}, Response: fields{"message": "", "photo_url": ""}, Errors: []int{400, 403, 413, 429}},
+
+	// Dashboard and analytics
+	{Method: "GET", Path: "/api/v1/admin/dashboard", Tag: "Analytics", Summary: "Dashboard totals, recent activity and batches", Auth: staffAuth,
+		Query:    []apiParam{batchParam, institutionParam},
+		Response: fields{"admin": &models.Admin{}, "stats": models.DashboardStats{}, "activity": []models.RecentActivity{}, "batches": []models.Batch{}}, Errors: []int{403, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/analytics/batch", Tag: "Analytics", Summary: "Placement statistics per batch", Auth: staffAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"batch_stats": []models.BatchStats{}}, Errors: []int{403, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/analytics/skills", Tag: "Analytics", Summary: "How many students list each skill", Auth: staffAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"skill_stats": []models.SkillStats{}}, Errors: []int{403, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/analytics/cgpa", Tag: "Analytics", Summary: "CGPA distribution", Auth: staffAuth,
+		Query:    []apiParam{batchParam, institutionParam},
+		Response: fields{"cgpa_distribution": []models.CGPADistribution{}}, Errors: []int{403, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/analytics/companies", Tag: "Analytics", Summary: "Offers and packages per company", Auth: staffAuth,
+		Query:    []apiParam{batchParam, institutionParam},
+		Response: fields{"company_stats": []models.CompanyStats{}}, Errors: []int{403, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/analytics/history", Tag: "Analytics", Summary: "Daily dashboard snapshots, oldest first", Auth: staffAuth,
+		Query:    []apiParam{{"days", "integer", "Number of days back from today, 1 to 366 (default 30)"}, institutionParam},
+		Response: fields{"snapshots": []models.DashboardSnapshot{}}, Errors: []int{400, 403, 422, 429}, ETag: true},
+	{Method: "GET", Path: "/api/v1/admin/activity", Tag: "Analytics", Summary: "Recent activity", Auth: staffAuth,
+		Query:    []apiParam{{"limit", "integer", "Maximum entries, default 20"}, institutionParam},
+		Response: fields{"activities": []models.RecentActivity{}}, Errors: []int{403, 429}},
+
+	// Students
+	{Method: "GET", Path: "/api/v1/admin/students/export", Tag: "Students", Summary: "Export students as CSV", Auth: staffAuth,
+		Query:    []apiParam{batchParam, {"status", "string", "Placement status"}, institutionParam},
+		Response: "", ContentType: "text/csv", Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/students/export/jobs", Tag: "Students", Summary: "Queue a CSV export of students to download when it is ready", Auth: staffAuth,
+		Query:  []apiParam{batchParam, {"status", "string", "Placement status"}, institutionParam},
+		Status: http.StatusAccepted, Response: fields{"job": jobs.Job{}}, Errors: []int{403, 429}},
+	{Method: "GET", Path: "/api/v1/admin/students/roll/{rollno}", Tag: "Students", Summary: "A student's full profile by roll number", Auth: staffAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"profile": models.StudentFullProfile{}}, Errors: []int{400, 403, 404, 429}},
+	{Method: "PUT", Path: "/api/v1/admin/students/{id}/status", Tag: "Students", Summary: "Update account, placement or eligibility status", Auth: staffAuth,
+		Request: studentStatusInput{}, Response: messageResponse, Errors: []int{400, 403, 404, 422, 429}},
+	{Method: "PATCH", Path: "/api/v1/admin/students/{id}/status", Tag: "Students", Summary: "Update account, placement or eligibility status", Auth: staffAuth,
+		Request: studentStatusInput{}, Response: messageResponse, Errors: []int{400, 403, 404, 422, 429}},
+	{Method: "PUT", Path: "/api/v1/admin/students/{id}/department", Tag: "Students", Summary: "Move a student to another department", Auth: staffAuth,
+		Request: studentDepartmentInput{}, Response: fields{"student": models.Student{}}, Errors: []int{400, 403, 404, 409, 422, 429}},
+	{Method: "PATCH", Path: "/api/v1/admin/students/{id}/department", Tag: "Students", Summary: "Move a student to another department", Auth: staffAuth,
+		Request: studentDepartmentInput{}, Response: fields{"student": models.Student{}}, Errors: []int{400, 403, 404, 409, 422, 429}},
+	{Method: "DELETE", Path: "/api/v1/admin/students/{id}/sessions", Tag: "Students", Summary: "Sign a student out of every device", Auth: staffAuth,
+		Response: fields{"message": "", "revoked_sessions": 0}, Errors: []int{400, 403, 404, 429}},
+	{Method: "POST", Path: "/api/v1/admin/students/{id}/impersonate", Tag: "Students", Summary: "Issue a short-lived, read-only token to view the app as a student", Auth: userAuth,
+		Status:   http.StatusCreated,
+		Response: fields{"access_token": "", "token_type": "", "expires_in": 0, "read_only": true, "impersonating": models.Student{}}, Errors: []int{400, 403, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/students/{id}", Tag: "Students", Summary: "A student's full profile", Auth: staffAuth,
+		Response: fields{"profile": models.StudentFullProfile{}}, Errors: []int{400, 403, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/students", Tag: "Students", Summary: "Search and page through students", Auth: staffAuth,
+		Query: []apiParam{
+			{"search", "string", "Name, email or roll number"},
+			{"page", "integer", "Page number, default 1"},
+			{"page_size", "integer", "Page size, default 20"},
+			batchParam,
+			{"status", "string", "Placement status"},
+			{"min_cgpa", "number", ""},
+			{"max_cgpa", "number", ""},
+			{"has_backlogs", "boolean", ""},
+			{"department", "string", "Ignored for department-scoped roles"},
+			institutionParam,
+		},
+		Response: fields{"students": []models.StudentListItem{}, "total": 0, "page": 0, "page_size": 0, "total_pages": 0}, Errors: []int{403, 429}},
+
+	// Placements
+	{Method: "PUT", Path: "/api/v1/admin/placements/{id}", Tag: "Placements", Summary: "Update a placement record", Auth: staffAuth,
+		Request: updatePlacementInput{}, Response: fields{"placement": models.PlacementRecord{}}, Errors: []int{400, 403, 404, 422, 429}},
+	{Method: "DELETE", Path: "/api/v1/admin/placements/{id}", Tag: "Placements", Summary: "Delete a placement record", Auth: staffAuth,
+		Response: messageResponse, Errors: []int{400, 403, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/placements", Tag: "Placements", Summary: "List placement records", Auth: staffAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"placements": []models.PlacementWithStudent{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/placements", Tag: "Placements", Summary: "Record a placement for a student", Auth: staffAuth,
+		Request: createPlacementInput{}, Status: http.StatusCreated, Response: fields{"placement": models.PlacementRecord{}}, Errors: []int{400, 403, 422, 429}},
+
+	// Companies
+	{Method: "GET", Path: "/api/v1/admin/companies/search", Tag: "Companies", Summary: "Search companies by name", Auth: staffAuth,
+		Query:    []apiParam{{"q", "string", "Search text"}, institutionParam},
+		Response: fields{"companies": []models.Company{}}, Errors: []int{400, 403, 429}},
+	{Method: "PUT", Path: "/api/v1/admin/companies/{id}", Tag: "Companies", Summary: "Update a company", Auth: staffAuth,
+		Request: updateCompanyInput{}, Response: fields{"company": models.Company{}}, Errors: []int{400, 403, 404, 422, 429}},
+	{Method: "DELETE", Path: "/api/v1/admin/companies/{id}", Tag: "Companies", Summary: "Delete a company", Auth: staffAuth,
+		Response: messageResponse, Errors: []int{400, 403, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/companies", Tag: "Companies", Summary: "List companies", Auth: staffAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"companies": []models.Company{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/companies", Tag: "Companies", Summary: "Add a company", Auth: staffAuth,
+		Request: models.Company{}, Status: http.StatusCreated, Response: fields{"company": models.Company{}}, Errors: []int{400, 403, 422, 429}},
+
+	// Admin accounts
+	{Method: "GET", Path: "/api/v1/admin/admins/audit", Tag: "Admins", Summary: "Audit log of admin account changes", Auth: userAuth,
+		Query:    []apiParam{{"limit", "integer", "Maximum entries, default 50"}, institutionParam},
+		Response: fields{"audit_log": []models.ActivityLog{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/admins/{id}/deactivate", Tag: "Admins", Summary: "Deactivate an admin and revoke their sessions", Auth: userAuth,
+		Response: fields{"admin": models.Admin{}}, Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "POST", Path: "/api/v1/admin/admins/{id}/reactivate", Tag: "Admins", Summary: "Reactivate an admin", Auth: userAuth,
+		Response: fields{"admin": models.Admin{}}, Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "DELETE", Path: "/api/v1/admin/admins/{id}/sessions", Tag: "Admins", Summary: "Sign an admin out of every device", Auth: userAuth,
+		Response: fields{"message": "", "revoked_sessions": 0}, Errors: []int{400, 403, 404, 429}},
+	{Method: "PUT", Path: "/api/v1/admin/admins/{id}", Tag: "Admins", Summary: "Update an admin's name, role or department", Auth: userAuth,
+		Request: updateAdminInput{}, Response: fields{"admin": models.Admin{}}, Errors: []int{400, 403, 404, 409, 422, 429}},
+	{Method: "PATCH", Path: "/api/v1/admin/admins/{id}", Tag: "Admins", Summary: "Update an admin's name, role or department", Auth: userAuth,
+		Request: updateAdminInput{}, Response: fields{"admin": models.Admin{}}, Errors: []int{400, 403, 404, 409, 422, 429}},
+	{Method: "GET", Path: "/api/v1/admin/admins", Tag: "Admins", Summary: "List admins and the roles they can be given", Auth: userAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"admins": []models.Admin{}, "roles": []string{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/admins", Tag: "Admins", Summary: "Invite an admin", Auth: userAuth,
+		Request: inviteAdminInput{}, Status: http.StatusCreated, Response: fields{"admin": models.Admin{}}, Errors: []int{400, 403, 409, 422, 429}},
+
+	// API keys
+	{Method: "DELETE", Path: "/api/v1/admin/api-keys/{id}", Tag: "API Keys", Summary: "Revoke an API key", Auth: userAuth,
+		Response: messageResponse, Errors: []int{400, 403, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/api-keys", Tag: "API Keys", Summary: "List API keys and the scopes they can be given", Auth: userAuth,
+		Query:    []apiParam{institutionParam},
+		Response: fields{"api_keys": []models.APIKey{}, "scopes": []string{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/api-keys", Tag: "API Keys", Summary: "Create an API key; the key is only returned once", Auth: userAuth,
+		Request: createAPIKeyInput{}, Status: http.StatusCreated, Response: fields{"api_key": models.APIKey{}, "key": ""}, Errors: []int{400, 403, 422, 429}},
+	{Method: "POST", Path: "/api/v1/admin/academics/sync", Tag: "API Keys", Summary: "Apply a batch of academic records from the ERP", Auth: staffAuth,
+		Request: academicSyncInput{}, Response: fields{"updated": 0, "errors": []academicSyncError{}}, Errors: []int{400, 403, 422, 429}},
+
+	// Background jobs
+	{Method: "POST", Path: "/api/v1/admin/jobs/{id}/retry", Tag: "Jobs", Summary: "Run a dead job again with a fresh set of attempts", Auth: staffAuth,
+		Response: fields{"job": jobs.Job{}}, Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "GET", Path: "/api/v1/admin/jobs/{id}/download", Tag: "Jobs", Summary: "Download the CSV written by a finished export job", Auth: staffAuth,
+		Response: "", ContentType: "text/csv", Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "GET", Path: "/api/v1/admin/jobs/{id}", Tag: "Jobs", Summary: "A job's status, attempts, last error and result", Auth: staffAuth,
+		Response: fields{"job": jobs.Job{}}, Errors: []int{400, 404, 429}},
+	{Method: "GET", Path: "/api/v1/admin/jobs", Tag: "Jobs", Summary: "List recent background jobs, newest first", Auth: staffAuth,
+		Query: []apiParam{
+			{"status", "string", "pending, running, succeeded or dead"},
+			{"kind", "string", "Only include jobs of this kind, e.g. students.export"},
+			{"limit", "integer", "Number of jobs, 1 to 200 (default 50)"},
+			institutionParam,
+		},
+		Response: fields{"jobs": []jobs.Job{}}, Errors: []int{403, 422, 429}},
+
+	// Scheduled maintenance
+	{Method: "GET", Path: "/api/v1/admin/schedule/runs", Tag: "Schedule", Summary: "Recent runs of scheduled tasks, newest first", Auth: staffAuth,
+		Query: []apiParam{
+			{"task", "string", "Only include runs of this task, e.g. dashboard-snapshot"},
+			{"limit", "integer", "Number of runs, 1 to 200 (default 50)"},
+		},
+		Response: fields{"runs": []schedule.Run{}}, Errors: []int{403, 422, 429}},
+	{Method: "GET", Path: "/api/v1/admin/schedule", Tag: "Schedule", Summary: "Scheduled tasks with their next and last runs, and whether this replica leads", Auth: staffAuth,
+		Response: fields{"enabled": true, "leader": true, "instance": "", "timezone": "", "tasks": []schedule.TaskStatus{}}, Errors: []int{403, 429}},
+
+	// Institutions
+	{Method: "PUT", Path: "/api/v1/admin/institutions/{id}", Tag: "Institutions", Summary: "Update an institution", Auth: userAuth,
+		Request: updateInstitutionInput{}, Response: fields{"institution": models.Institution{}}, Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "PATCH", Path: "/api/v1/admin/institutions/{id}", Tag: "Institutions", Summary: "Update an institution", Auth: userAuth,
+		Request: updateInstitutionInput{}, Response: fields{"institution": models.Institution{}}, Errors: []int{400, 403, 404, 409, 429}},
+	{Method: "GET", Path: "/api/v1/admin/institutions", Tag: "Institutions", Summary: "List institutions", Auth: userAuth,
+		Response: fields{"institutions": []models.Institution{}}, Errors: []int{403, 429}},
+	{Method: "POST", Path: "/api/v1/admin/institutions", Tag: "Institutions", Summary: "Add an institution", Auth: userAuth,
+		Request: createInstitutionInput{}, Status: http.StatusCreated, Response: fields{"institution": models.Institution{}}, Errors: []int{400, 403, 409, 429}},
+
+	// Common
+	{Method: "GET", Path: "/api/v1/skills", Tag: "Catalogue", Summary: "The institution's skill catalogue, also grouped by category", Auth: staffAuth,
+		Response: fields{"skills": []models.Skill{}, "grouped": map[string][]models.Skill{}}},
+	{Method: "GET", Path: "/api/v1/batches", Tag: "Catalogue", Summary: "The institution's batches", Auth: staffAuth,
+		Response: fields{"batches": []models.Batch{}}},
+}
+
+// errorResponses names the shared response for each error status
+var errorResponses = map[int]struct{ name, description string }{
+	http.StatusBadRequest:            {"BadRequest", "The request body or parameters are malformed"},
+	http.StatusUnauthorized:          {"Unauthorized", "Authentication is missing or invalid"},
+	http.StatusForbidden:             {"Forbidden", "The caller may not perform this action"},
+	http.StatusNotFound:              {"NotFound", "The resource does not exist"},
+	http.StatusConflict:              {"Conflict", "The change conflicts with existing data"},
+	http.StatusRequestEntityTooLarge: {"PayloadTooLarge", "The upload is too large"},
+	http.StatusUnprocessableEntity:   {"ValidationFailed", "One or more fields are invalid; details maps field names to messages"},
+	http.StatusTooManyRequests:       {"TooManyRequests", "Rate limit exceeded; retry after the Retry-After header"},
+	http.StatusInternalServerError:   {"ServerError", "The server encountered a problem"},
+	http.StatusServiceUnavailable:    {"Unavailable", "The instance cannot take traffic"},
+}
+
+// openAPIDocument renders apiOperations as an OpenAPI 3.0 document
+func openAPIDocument() map[string]any {
+	schemas := &schemaSet{components: map[string]any{}, names: map[string]reflect.Type{}}
+
+	schemas.components["Error"] = map[string]any{
+		"type":       "object",
+		"required":   []string{"error"},
+		"properties": map[string]any{"error": schemas.of(apiError{})},
+	}
+	body := schemas.components["ApiError"].(map[string]any)
+	body["required"] = []string{"code", "message"}
+	props := body["properties"].(map[string]any)
+	props["code"].(map[string]any)["enum"] = errorCodes
+	props["request_id"].(map[string]any)["description"] = "Matches the X-Request-ID response header"
+
+	responses := map[string]any{}
+	for status, resp := range errorResponses {
+		r := map[string]any{
+			"description": resp.description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": ref("Error")},
+			},
+		}
+		if status == http.StatusTooManyRequests {
+			r["headers"] = map[string]any{
+				"Retry-After": map[string]any{"description": "Seconds until the limit resets", "schema": map[string]any{"type": "integer"}},
+			}
+		}
+		responses[resp.name] = r
+	}
+
+	paths := map[string]any{}
+	for _, op := range apiOperations {
+		item, ok := paths[op.Path].(map[string]any)
+		if !ok {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = op.document(schemas)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Placement Profiling System API",
+			"version": version,
+			"description": "Student profiles, placements and analytics for college placement cells.\n\n" +
+				"The unversioned paths used before /api/v1 (/auth/... and /api/...) still work but are deprecated: " +
+				"their responses carry Deprecation, Link and, once a date is set, Sunset headers, and errors keep the old " +
+				"{\"error\": \"message\", \"code\": \"...\"} shape.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":   schemas.components,
+			"responses": responses,
+			"securitySchemes": map[string]any{
+				"bearerAuth":   map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT", "description": "Access token from /auth/exchange or /auth/refresh"},
+				"cookieAuth":   map[string]any{"type": "apiKey", "in": "cookie", "name": accessCookieName, "description": "Cookie-mode session; changes also need the X-CSRF-Token header"},
+				"apiKey":       map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key", "description": "Service account key; \"Authorization: ApiKey <key>\" also works"},
+				"metricsToken": map[string]any{"type": "http", "scheme": "bearer", "description": "METRICS_TOKEN"},
+			},
+		},
+		"tags": []any{
+			map[string]any{"name": "System"},
+			map[string]any{"name": "Auth"},
+			map[string]any{"name": "Student"},
+			map[string]any{"name": "Analytics"},
+			map[string]any{"name": "Students"},
+			map[string]any{"name": "Placements"},
+			map[string]any{"name": "Companies"},
+			map[string]any{"name": "Admins"},
+			map[string]any{"name": "API Keys"},
+			map[string]any{"name": "Institutions"},
+			map[string]any{"name": "Catalogue"},
+		},
+	}
+}
+
+var pathParamRE = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// document renders one operation
+func (op apiOperation) document(schemas *schemaSet) map[string]any {
+	doc := map[string]any{
+		"tags":        []string{op.Tag},
+		"summary":     op.Summary,
+		"operationId": operationID(op.Method, op.Path),
+	}
+
+	var params []any
+	for _, m := range pathParamRE.FindAllStringSubmatch(op.Path, -1) {
+		typ := "string"
+		if m[1] == "id" {
+			typ = "integer"
+		}
+		params = append(params, map[string]any{"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": typ}})
+	}
+	for _, q := range op.Query {
+		p := map[string]any{"name": q.Name, "in": "query", "schema": map[string]any{"type": q.Type}}
+		if q.Description != "" {
+			p["description"] = q.Description
+		}
+		params = append(params, p)
+	}
+	if op.ETag {
+		params = append(params, map[string]any{"name": "If-None-Match", "in": "header", "schema": map[string]any{"type": "string"},
+			"description": "The ETag of a previous response; 304 is returned if nothing has changed since"})
+	}
+	if params != nil {
+		doc["parameters"] = params
+	}
+
+	if op.Request != nil {
+		doc["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": schemas.of(op.Request)},
+			},
+		}
+	}
+
+	if len(op.Auth) > 0 {
+		var security []any
+		for _, scheme := range op.Auth {
+			security = append(security, map[string]any{scheme: []string{}})
+		}
+		doc["security"] = security
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]any{"description": http.StatusText(status)}
+	switch {
+	case status >= 300 && status < 400:
+		success["headers"] = map[string]any{"Location": map[string]any{"schema": map[string]any{"type": "string"}}}
+	case op.ContentType != "":
+		success["content"] = map[string]any{op.ContentType: map[string]any{"schema": schemas.of(op.Response)}}
+	case op.Response != nil:
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": schemas.of(op.Response)}}
+	}
+
+	responses := map[string]any{strconv.Itoa(status): success}
+	if op.ETag {
+		success["headers"] = map[string]any{"ETag": map[string]any{"schema": map[string]any{"type": "string"}}}
+		responses[strconv.Itoa(http.StatusNotModified)] = map[string]any{"description": "Not Modified: the If-None-Match ETag is still current"}
+	}
+
+	errs := append([]int{http.StatusInternalServerError}, op.Errors...)
+	if len(op.Auth) > 0 {
+		errs = append(errs, http.StatusUnauthorized)
+	}
+	for _, code := range errs {
+		responses[strconv.Itoa(code)] = map[string]any{"$ref": "#/components/responses/" + errorResponses[code].name}
+	}
+	doc["responses"] = responses
+
+	return doc
+}
+
+// operationID derives a stable id such as getApiAdminStudentsId
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// ============================================
+// SCHEMAS
+// ============================================
+
+// schemaSet turns Go types into JSON schemas, registering named structs as components
+type schemaSet struct {
+	components map[string]any
+	names      map[string]reflect.Type
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// of returns the schema for v: a fields shape, a listOf, or a value of any Go type
+func (s *schemaSet) of(v any) map[string]any {
+	switch v := v.(type) {
+	case fields:
+		props := map[string]any{}
+		for name, value := range v {
+			props[name] = s.of(value)
+		}
+		return map[string]any{"type": "object", "properties": props}
+	case listOf:
+		return map[string]any{"type": "array", "items": s.of(v.item)}
+	}
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+func (s *schemaSet) typeSchema(t reflect.Type) map[string]any {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	schema := s.baseSchema(t)
+	if nullable {
+		if _, isRef := schema["$ref"]; isRef {
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+	}
+	return schema
+}
+
+func (s *schemaSet) baseSchema(t reflect.Type) map[string]any {
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t == rawJSONType {
+		return map[string]any{} // any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.objectSchema(t)
+		}
+		name := s.componentName(t)
+		if _, done := s.components[name]; !done {
+			s.components[name] = map[string]any{} // placeholder for recursive types
+			s.components[name] = s.objectSchema(t)
+		}
+		return ref(name)
+	}
+	return map[string]any{}
+}
+
+// objectSchema lists a struct's JSON properties the way encoding/json would
+func (s *schemaSet) objectSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	s.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (s *schemaSet) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(ft, props)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = s.typeSchema(f.Type)
+	}
+}
+
+// componentName is the type's name, capitalised for this package's unexported
+// input types and qualified by package if two packages share a name
+func (s *schemaSet) componentName(t reflect.Type) string {
+	name := t.Name()
+	if r := []rune(name); len(r) > 0 {
+		r[0] = unicode.ToUpper(r[0])
+		name = string(r)
+	}
+	if seen, ok := s.names[name]; ok && seen != t {
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[name] = t
+	return name
+}
+
+// ============================================
+// HANDLERS
+// ============================================
+
+// openAPISpec is built once; the operation table never changes at runtime
+var openAPISpec = sync.OnceValues(func() ([]byte, error) {
+	js, err := json.MarshalIndent(openAPIDocument(), "", "\t")
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return js, nil
+})
+
+// openAPIHandler serves the OpenAPI document
+func (app *application) openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	js, err := openAPISpec()
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(js)
+}
+
+//go:embed docs/index.html
+var docsFS embed.FS
+
+// apiDocsHandler serves the API reference page. It renders /api/openapi.json
+// in the browser with no third-party scripts.
+func (app *application) apiDocsHandler(w http.ResponseWriter, r *http.Request) {
+	page, err := docsFS.ReadFile("docs/index.html")
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'unsafe-inline'")
+	w.Write(page)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+var routePatternRE = regexp.MustCompile(`\{([a-z_]+):[^}]+\}`)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	app := newTestApplication()
+
+	registered := map[string]bool{}
+	err := app.router().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+		for _, method := range methods {
+			registered[method+" "+routePatternRE.ReplaceAllString(path, "{$1}")] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	documented := map[string]bool{}
+	for _, op := range apiOperations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			t.Errorf("%s is documented twice", key)
+		}
+		documented[key] = true
+	}
+
+	for key := range registered {
+		if !documented[key] {
+			t.Errorf("%s is registered in routes() but has no entry in apiOperations", key)
+		}
+	}
+	for key := range documented {
+		if !registered[key] {
+			t.Errorf("%s is documented but no longer registered", key)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	handler := newTestApplication().routes()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, apiV1+"/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /api/v1/openapi.json: got %d", rr.Code)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["openapi"] != "3.0.3" {
+		t.Errorf("openapi version: got %v", doc["openapi"])
+	}
+
+	// Every $ref must point at something in the document
+	var check func(v any)
+	check = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				var target any = doc
+				for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					target, _ = target.(map[string]any)[part]
+				}
+				if target == nil {
+					t.Errorf("dangling reference %s", ref)
+				}
+			}
+			for _, child := range v {
+				check(child)
+			}
+		case []any:
+			for _, child := range v {
+				check(child)
+			}
+		}
+	}
+	check(doc)
+
+	// Spot check a generated request schema
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	input, ok := schemas["ExchangeInput"].(map[string]any)
+	if !ok {
+		t.Fatal("ExchangeInput schema missing")
+	}
+	if _, ok := input["properties"].(map[string]any)["code"]; !ok {
+		t.Errorf("ExchangeInput has no code property: %v", input)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, apiV1+"/docs", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "openapi.json") {
+		t.Errorf("GET /api/v1/docs: got %d", rr.Code)
+	}
+}
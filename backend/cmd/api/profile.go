@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
 )
 
 // ============================================
@@ -23,11 +24,11 @@ import (
 func (app *application) getStudentProfile(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	profile, err := app.models.Students.GetFullProfile(claims.UserID)
+	profile, err := app.models.Students.GetFullProfile(r.Context(), claims.UserID)
 	if err != nil {
 		if errors.Is(err, models.ErrRecordNotFound) {
 			app.notFoundResponse(w, r)
@@ -39,35 +40,44 @@ func (app *application) getStudentProfile(w http.ResponseWriter, r *http.Request
 
 	// Get placement info if placed
 	if profile.Student.PlacementStatus == models.PlacementStatusPlaced {
-		placement, _ := app.models.Placements.GetByStudentID(claims.UserID)
+		placement, _ := app.models.Placements.GetByStudentID(r.Context(), claims.UserID)
 		profile.Placement = placement
 	}
 
 	app.writeJSON(w, http.StatusOK, envelope{"profile": profile}, nil)
 }
 
+// studentProfileInput is the body of PUT /api/v1/student/profile
+type studentProfileInput struct {
+	Name       string  `json:"name"`
+	RollNo     *string `json:"roll_no"`
+	RegisterNo *string `json:"register_no"`
+	BatchID    *int    `json:"batch_id"`
+	PhotoURL   *string `json:"photo_url"`
+}
+
 // updateStudentProfile updates basic student info
 func (app *application) updateStudentProfile(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	var input struct {
-		Name       string  `json:"name"`
-		RollNo     *string `json:"roll_no"`
-		RegisterNo *string `json:"register_no"`
-		BatchID    *int    `json:"batch_id"`
-		PhotoURL   *string `json:"photo_url"`
-	}
+	var input studentProfileInput
 
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	student, err := app.models.Students.GetByID(claims.UserID)
+	v := validator.New()
+	if validateStudentProfile(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	student, err := app.models.Students.GetByID(r.Context(), claims.UserID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -89,9 +99,9 @@ func (app *application) updateStudentProfile(w http.ResponseWriter, r *http.Requ
 		student.PhotoURL = input.PhotoURL
 	}
 
-	if err := app.models.Students.UpdateBasicInfo(student); err != nil {
+	if err := app.models.Students.UpdateBasicInfo(r.Context(), student); err != nil {
 		if errors.Is(err, models.ErrEditConflict) {
-			app.errorResponse(w, r, http.StatusConflict, "record was modified by another request")
+			app.editConflictResponse(w, r)
 			return
 		}
 		app.serverErrorResponse(w, r, err)
@@ -101,54 +111,55 @@ func (app *application) updateStudentProfile(w http.ResponseWriter, r *http.Requ
 	app.writeJSON(w, http.StatusOK, envelope{"student": student}, nil)
 }
 
+// personalDetailsInput accepts both Student and PersonalDetails fields.
+// All numeric fields accept strings since HTML forms always send strings.
+type personalDetailsInput struct {
+	// Student fields (will update Student table)
+	Name      string  `json:"name"`
+	RollNo    *string `json:"roll_no"`
+	BatchYear *string `json:"batch_year"` // Accept as string from form
+
+	// Personal details fields (with frontend aliases)
+	DateOfBirth     *string `json:"date_of_birth"`
+	Gender          *string `json:"gender"`
+	BloodGroup      *string `json:"blood_group"`
+	MobileNumber    *string `json:"mobile_number"`
+	AlternateMobile *string `json:"alt_mobile_number"` // Frontend uses alt_mobile_number
+	PersonalEmail   *string `json:"personal_email"`
+	LinkedinURL     *string `json:"linkedin_url"`
+	GithubURL       *string `json:"github_url"`
+	PortfolioURL    *string `json:"portfolio_url"`
+	AadhaarNumber   *string `json:"aadhaar_no"` // Frontend uses aadhaar_no
+	Address         *string `json:"address"`
+	City            *string `json:"city"`
+	State           *string `json:"state"`
+	Pincode         *string `json:"pincode"`
+	ResidenceType   *string `json:"residence_type"`
+}
+
 // updatePersonalDetails updates student personal details
 func (app *application) updatePersonalDetails(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
-		return
-	}
-
-	// Custom input struct that accepts both Student and PersonalDetails fields
-	// All numeric fields accept strings since HTML forms always send strings
-	var input struct {
-		// Student fields (will update Student table)
-		Name      string  `json:"name"`
-		RollNo    *string `json:"roll_no"`
-		BatchYear *string `json:"batch_year"` // Accept as string from form
-
-		// Personal details fields (with frontend aliases)
-		DateOfBirth     *string `json:"date_of_birth"`
-		Gender          *string `json:"gender"`
-		BloodGroup      *string `json:"blood_group"`
-		MobileNumber    *string `json:"mobile_number"`
-		AlternateMobile *string `json:"alt_mobile_number"` // Frontend uses alt_mobile_number
-		PersonalEmail   *string `json:"personal_email"`
-		LinkedinURL     *string `json:"linkedin_url"`
-		GithubURL       *string `json:"github_url"`
-		PortfolioURL    *string `json:"portfolio_url"`
-		AadhaarNumber   *string `json:"aadhaar_no"` // Frontend uses aadhaar_no
-		Address         *string `json:"address"`
-		City            *string `json:"city"`
-		State           *string `json:"state"`
-		Pincode         *string `json:"pincode"`
-		ResidenceType   *string `json:"residence_type"`
+		app.authErrorResponse(w, r, err)
+		return
 	}
 
+	var input personalDetailsInput
+
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validatePersonalDetails(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	// Update Student basic info if provided
-	student, err := app.models.Students.GetByID(claims.UserID)
+	student, err := app.models.Students.GetByID(r.Context(), claims.UserID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -163,14 +174,14 @@ func (app *application) updatePersonalDetails(w http.ResponseWriter, r *http.Req
 	if input.BatchYear != nil && *input.BatchYear != "" {
 		// Convert string to int and find batch ID
 		if batchYear, err := strconv.Atoi(*input.BatchYear); err == nil {
-			batchID, err := app.models.Students.GetBatchIDByYear(batchYear)
+			batchID, err := app.models.Students.GetBatchIDByYear(r.Context(), student.InstitutionID, batchYear)
 			if err == nil && batchID > 0 {
 				student.BatchID = &batchID
 			}
 		}
 	}
 
-	if err := app.models.Students.UpdateBasicInfo(student); err != nil {
+	if err := app.models.Students.UpdateBasicInfo(r.Context(), student); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -178,8 +189,8 @@ func (app *application) updatePersonalDetails(w http.ResponseWriter, r *http.Req
 	// Update personal details
 	personalDetails := &models.StudentPersonalDetails{
 		StudentID:       claims.UserID,
-		DateOfBirth:     input.DateOfBirth,
-		Gender:          input.Gender,
+		DateOfBirth:     nilIfBlank(input.DateOfBirth),
+		Gender:          nilIfBlank(input.Gender),
 		BloodGroup:      input.BloodGroup,
 		MobileNumber:    input.MobileNumber,
 		AlternateMobile: input.AlternateMobile,
@@ -192,15 +203,10 @@ func (app *application) updatePersonalDetails(w http.ResponseWriter, r *http.Req
 		City:            input.City,
 		State:           input.State,
 		Pincode:         input.Pincode,
-		ResidenceType:   input.ResidenceType,
-	}
-
-	if err := app.models.Students.UpsertPersonalDetails(tx, personalDetails); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
+		ResidenceType:   nilIfBlank(input.ResidenceType),
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertPersonalDetails(r.Context(), personalDetails); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -208,44 +214,45 @@ func (app *application) updatePersonalDetails(w http.ResponseWriter, r *http.Req
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Personal details saved"}, nil)
 }
 
+// familyDetailsInput uses the frontend's field names
+type familyDetailsInput struct {
+	FatherName         *string `json:"father_name"`
+	FatherMobile       *string `json:"father_mobile"`
+	FatherEmail        *string `json:"father_email"`
+	FatherOccupation   *string `json:"father_occupation"`
+	FatherCompany      *string `json:"father_company_details"` // Frontend uses father_company_details
+	FatherAnnualIncome *string `json:"annual_income"`          // Frontend uses annual_income
+	MotherName         *string `json:"mother_name"`
+	MotherMobile       *string `json:"mother_mobile"`
+	MotherEmail        *string `json:"mother_email"`
+	MotherOccupation   *string `json:"mother_occupation"`
+	MotherCompany      *string `json:"mother_company"`
+	GuardianName       *string `json:"guardian_name"`
+	GuardianMobile     *string `json:"guardian_mobile"`
+	GuardianRelation   *string `json:"guardian_relation"`
+	ResidenceType      *string `json:"residence_type"` // This goes to personal details
+}
+
 // updateFamilyDetails updates student family details
 func (app *application) updateFamilyDetails(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	// Custom input struct with frontend field aliases
-	var input struct {
-		FatherName         *string `json:"father_name"`
-		FatherMobile       *string `json:"father_mobile"`
-		FatherEmail        *string `json:"father_email"`
-		FatherOccupation   *string `json:"father_occupation"`
-		FatherCompany      *string `json:"father_company_details"` // Frontend uses father_company_details
-		FatherAnnualIncome *string `json:"annual_income"`          // Frontend uses annual_income
-		MotherName         *string `json:"mother_name"`
-		MotherMobile       *string `json:"mother_mobile"`
-		MotherEmail        *string `json:"mother_email"`
-		MotherOccupation   *string `json:"mother_occupation"`
-		MotherCompany      *string `json:"mother_company"`
-		GuardianName       *string `json:"guardian_name"`
-		GuardianMobile     *string `json:"guardian_mobile"`
-		GuardianRelation   *string `json:"guardian_relation"`
-		ResidenceType      *string `json:"residence_type"` // This goes to personal details
-	}
+	var input familyDetailsInput
 
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validateFamilyDetails(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	familyDetails := &models.StudentFamilyDetails{
 		StudentID:          claims.UserID,
@@ -265,12 +272,7 @@ func (app *application) updateFamilyDetails(w http.ResponseWriter, r *http.Reque
 		GuardianRelation:   input.GuardianRelation,
 	}
 
-	if err := app.models.Students.UpsertFamilyDetails(tx, familyDetails); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertFamilyDetails(r.Context(), familyDetails); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -278,55 +280,56 @@ func (app *application) updateFamilyDetails(w http.ResponseWriter, r *http.Reque
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Family details saved"}, nil)
 }
 
+// academicsInput uses the frontend's field names
+type academicsInput struct {
+	TenthPercentage   *float64 `json:"tenth_percentage"`
+	TenthBoard        *string  `json:"tenth_board"`
+	TenthYear         *int     `json:"tenth_year"`
+	TenthSchool       *string  `json:"tenth_school"`
+	TwelfthPercentage *float64 `json:"twelfth_percentage"`
+	TwelfthBoard      *string  `json:"twelfth_board"`
+	TwelfthYear       *int     `json:"twelfth_year"`
+	TwelfthSchool     *string  `json:"twelfth_school"`
+	HasDiploma        bool     `json:"has_diploma"`
+	DiplomaPercentage *float64 `json:"diploma_percentage"`
+	DiplomaBranch     *string  `json:"diploma_branch"`
+	DiplomaCollege    *string  `json:"diploma_college"`
+	CGPASem1          *float64 `json:"cgpa_sem1"`
+	CGPASem2          *float64 `json:"cgpa_sem2"`
+	CGPASem3          *float64 `json:"cgpa_sem3"`
+	CGPASem4          *float64 `json:"cgpa_sem4"`
+	CGPASem5          *float64 `json:"cgpa_sem5"`
+	CGPASem6          *float64 `json:"cgpa_sem6"`
+	CGPASem7          *float64 `json:"cgpa_sem7"`
+	CGPASem8          *float64 `json:"cgpa_sem8"`
+	CGPAOverall       *float64 `json:"cgpa_overall"`
+	CurrentBacklogs   int      `json:"current_backlogs"`
+	HistoryOfBacklogs bool     `json:"has_backlog_history"` // Frontend uses has_backlog_history
+	BacklogDetails    *string  `json:"backlog_details"`
+	HasGapYear        bool     `json:"has_gap_year"`
+	GapYearReason     *string  `json:"gap_year_reason"`
+}
+
 // updateAcademics updates student academic details
 func (app *application) updateAcademics(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
-		return
-	}
-
-	// Custom input struct with frontend field aliases
-	var input struct {
-		TenthPercentage   *float64 `json:"tenth_percentage"`
-		TenthBoard        *string  `json:"tenth_board"`
-		TenthYear         *int     `json:"tenth_year"`
-		TenthSchool       *string  `json:"tenth_school"`
-		TwelfthPercentage *float64 `json:"twelfth_percentage"`
-		TwelfthBoard      *string  `json:"twelfth_board"`
-		TwelfthYear       *int     `json:"twelfth_year"`
-		TwelfthSchool     *string  `json:"twelfth_school"`
-		HasDiploma        bool     `json:"has_diploma"`
-		DiplomaPercentage *float64 `json:"diploma_percentage"`
-		DiplomaBranch     *string  `json:"diploma_branch"`
-		DiplomaCollege    *string  `json:"diploma_college"`
-		CGPASem1          *float64 `json:"cgpa_sem1"`
-		CGPASem2          *float64 `json:"cgpa_sem2"`
-		CGPASem3          *float64 `json:"cgpa_sem3"`
-		CGPASem4          *float64 `json:"cgpa_sem4"`
-		CGPASem5          *float64 `json:"cgpa_sem5"`
-		CGPASem6          *float64 `json:"cgpa_sem6"`
-		CGPASem7          *float64 `json:"cgpa_sem7"`
-		CGPASem8          *float64 `json:"cgpa_sem8"`
-		CGPAOverall       *float64 `json:"cgpa_overall"`
-		CurrentBacklogs   int      `json:"current_backlogs"`
-		HistoryOfBacklogs bool     `json:"has_backlog_history"` // Frontend uses has_backlog_history
-		BacklogDetails    *string  `json:"backlog_details"`
-		HasGapYear        bool     `json:"has_gap_year"`
-		GapYearReason     *string  `json:"gap_year_reason"`
+		app.authErrorResponse(w, r, err)
+		return
 	}
 
+	var input academicsInput
+
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validateAcademics(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	academics := &models.StudentAcademics{
 		StudentID:         claims.UserID,
@@ -358,12 +361,7 @@ func (app *application) updateAcademics(w http.ResponseWriter, r *http.Request)
 		GapYearReason:     input.GapYearReason,
 	}
 
-	if err := app.models.Students.UpsertAcademics(tx, academics); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertAcademics(r.Context(), academics); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -371,47 +369,48 @@ func (app *application) updateAcademics(w http.ResponseWriter, r *http.Request)
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Academic details saved"}, nil)
 }
 
+// achievementsInput uses the frontend's field names
+type achievementsInput struct {
+	Certifications         *string `json:"certifications"`
+	Awards                 *string `json:"awards"`
+	Workshops              *string `json:"workshops"`
+	Internships            *string `json:"internships"`
+	Projects               *string `json:"projects"`
+	LeetcodeProfile        *string `json:"leetcode_profile"`
+	HackerrankProfile      *string `json:"hackerrank_profile"`
+	CodeforcesProfile      *string `json:"codeforces_profile"`
+	CodechefProfile        *string `json:"codechef_profile"`
+	LeetcodeRating         *int    `json:"leetcode_rating"`
+	ProblemsSolved         *int    `json:"problems_solved"`
+	HackathonsParticipated int     `json:"hackathons_participated"`
+	HackathonsWon          int     `json:"hackathons_won"`
+	HackathonDetails       *string `json:"hackathon_details"`
+	Extracurriculars       *string `json:"extra_curriculars"` // Frontend uses extra_curriculars
+	ClubMemberships        *string `json:"club_memberships"`
+	Sports                 *string `json:"sports"`
+	VolunteerWork          *string `json:"volunteer_work"`
+}
+
 // updateAchievements updates student achievements
 func (app *application) updateAchievements(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
-		return
-	}
-
-	// Custom input struct with frontend field aliases
-	var input struct {
-		Certifications         *string `json:"certifications"`
-		Awards                 *string `json:"awards"`
-		Workshops              *string `json:"workshops"`
-		Internships            *string `json:"internships"`
-		Projects               *string `json:"projects"`
-		LeetcodeProfile        *string `json:"leetcode_profile"`
-		HackerrankProfile      *string `json:"hackerrank_profile"`
-		CodeforcesProfile      *string `json:"codeforces_profile"`
-		CodechefProfile        *string `json:"codechef_profile"`
-		LeetcodeRating         *int    `json:"leetcode_rating"`
-		ProblemsSolved         *int    `json:"problems_solved"`
-		HackathonsParticipated int     `json:"hackathons_participated"`
-		HackathonsWon          int     `json:"hackathons_won"`
-		HackathonDetails       *string `json:"hackathon_details"`
-		Extracurriculars       *string `json:"extra_curriculars"` // Frontend uses extra_curriculars
-		ClubMemberships        *string `json:"club_memberships"`
-		Sports                 *string `json:"sports"`
-		VolunteerWork          *string `json:"volunteer_work"`
+		app.authErrorResponse(w, r, err)
+		return
 	}
 
+	var input achievementsInput
+
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validateAchievements(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	achievements := &models.StudentAchievements{
 		StudentID:              claims.UserID,
@@ -435,12 +434,7 @@ func (app *application) updateAchievements(w http.ResponseWriter, r *http.Reques
 		VolunteerWork:          input.VolunteerWork,
 	}
 
-	if err := app.models.Students.UpsertAchievements(tx, achievements); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertAchievements(r.Context(), achievements); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -448,42 +442,43 @@ func (app *application) updateAchievements(w http.ResponseWriter, r *http.Reques
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Achievements saved"}, nil)
 }
 
+// aspirationsInput uses the frontend's field names
+type aspirationsInput struct {
+	DreamCompanies     *string  `json:"dream_company"` // Frontend uses dream_company (singular)
+	PreferredRoles     *string  `json:"preferred_roles"`
+	PreferredLocations *string  `json:"preferred_locations"`
+	ExpectedPackage    *float64 `json:"expected_package"` // Frontend sends as number
+	WillingToRelocate  bool     `json:"willing_to_relocate"`
+	CareerObjective    *string  `json:"career_goals"` // Frontend uses career_goals
+	ShortTermGoals     *string  `json:"short_term_goals"`
+	LongTermGoals      *string  `json:"long_term_goals"`
+	HigherStudies      *string  `json:"higher_studies"` // Extra field from frontend
+	Strengths          *string  `json:"strengths"`
+	Weaknesses         *string  `json:"weaknesses"`
+	Hobbies            *string  `json:"hobbies"`
+	LanguagesKnown     *string  `json:"languages_known"`
+}
+
 // updateAspirations updates student aspirations
 func (app *application) updateAspirations(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	// Custom input struct with frontend field aliases
-	var input struct {
-		DreamCompanies     *string  `json:"dream_company"` // Frontend uses dream_company (singular)
-		PreferredRoles     *string  `json:"preferred_roles"`
-		PreferredLocations *string  `json:"preferred_locations"`
-		ExpectedPackage    *float64 `json:"expected_package"` // Frontend sends as number
-		WillingToRelocate  bool     `json:"willing_to_relocate"`
-		CareerObjective    *string  `json:"career_goals"` // Frontend uses career_goals
-		ShortTermGoals     *string  `json:"short_term_goals"`
-		LongTermGoals      *string  `json:"long_term_goals"`
-		HigherStudies      *string  `json:"higher_studies"` // Extra field from frontend
-		Strengths          *string  `json:"strengths"`
-		Weaknesses         *string  `json:"weaknesses"`
-		Hobbies            *string  `json:"hobbies"`
-		LanguagesKnown     *string  `json:"languages_known"`
-	}
+	var input aspirationsInput
 
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validateAspirations(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	// Convert expected_package (float) to string for expected_salary
 	var expectedSalary *string
@@ -508,12 +503,7 @@ func (app *application) updateAspirations(w http.ResponseWriter, r *http.Request
 		LanguagesKnown:     input.LanguagesKnown,
 	}
 
-	if err := app.models.Students.UpsertAspirations(tx, aspirations); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertAspirations(r.Context(), aspirations); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -521,34 +511,35 @@ func (app *application) updateAspirations(w http.ResponseWriter, r *http.Request
 	app.writeJSON(w, http.StatusOK, envelope{"message": "Aspirations saved"}, nil)
 }
 
+// skillsInput replaces the student's skills with the listed ones
+type skillsInput struct {
+	Skills []skillInput `json:"skills"`
+}
+
+type skillInput struct {
+	SkillID          int `json:"skill_id"`
+	ProficiencyLevel int `json:"proficiency_level"` // Frontend sends proficiency_level as 1-5
+}
+
 // updateSkills updates student skills
 func (app *application) updateSkills(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	// Custom skill input struct to accept frontend field names
-	type SkillInput struct {
-		SkillID          int `json:"skill_id"`
-		ProficiencyLevel int `json:"proficiency_level"` // Frontend sends proficiency_level as 1-5
-	}
-
-	var input struct {
-		Skills []SkillInput `json:"skills"`
-	}
+	var input skillsInput
 	if err := app.readJSON(w, r, &input); err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
-		app.serverErrorResponse(w, r, err)
+	v := validator.New()
+	if validateSkills(v, &input); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
 		return
 	}
-	defer tx.Rollback()
 
 	// Convert input to models.StudentSkill with proficiency conversion
 	var skills []models.StudentSkill
@@ -573,12 +564,7 @@ func (app *application) updateSkills(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	if err := app.models.Students.UpsertSkills(tx, claims.UserID, skills); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
-
-	if err := tx.Commit(); err != nil {
+	if err := app.models.Students.UpsertSkills(r.Context(), claims.UserID, skills); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -590,44 +576,52 @@ func (app *application) updateSkills(w http.ResponseWriter, r *http.Request) {
 func (app *application) completeProfile(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	tx, err := app.models.DB.Begin()
-	if err != nil {
+	if err := app.models.Students.SetProfileCompleted(r.Context(), claims.UserID); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
-	defer tx.Rollback()
 
-	if err := app.models.Students.SetProfileCompleted(tx, claims.UserID); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
+	app.writeJSON(w, http.StatusOK, envelope{"message": "Profile marked as complete"}, nil)
+}
 
-	if err := tx.Commit(); err != nil {
-		app.serverErrorResponse(w, r, err)
-		return
-	}
+// uploadedPhotoPrefix is the URL path uploaded photos are served under
+const uploadedPhotoPrefix = "/uploads/photos/"
 
-	app.writeJSON(w, http.StatusOK, envelope{"message": "Profile marked as complete"}, nil)
+// photoDir is where profile photos are stored
+func (app *application) photoDir() string {
+	return filepath.Join(app.config.Uploads.Dir, "photos")
+}
+
+// photoInput is the JSON alternative to a multipart photo upload
+type photoInput struct {
+	Photo string `json:"photo"` // base64 encoded image, optionally as a data: URL
 }
 
 // uploadPhoto handles profile photo upload
 func (app *application) uploadPhoto(w http.ResponseWriter, r *http.Request) {
 	claims, err := app.authenticateStudent(r)
 	if err != nil {
-		app.unauthorizedResponse(w, r)
+		app.authErrorResponse(w, r, err)
 		return
 	}
 
-	// Parse multipart form (max 5MB)
-	if err := r.ParseMultipartForm(5 << 20); err != nil {
-		// Check if it's base64 JSON upload
-		var input struct {
-			Photo string `json:"photo"` // base64 encoded image
+	maxBytes := int64(app.config.Uploads.MaxPhotoMB) << 20
+
+	// Parse multipart form, rejecting bodies over the photo limit
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(maxBytes); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			app.errorResponse(w, r, http.StatusRequestEntityTooLarge, codePayloadTooLarge, fmt.Sprintf("photo must not be larger than %d MB", app.config.Uploads.MaxPhotoMB))
+			return
 		}
+
+		// Check if it's base64 JSON upload
+		var input photoInput
 		if err := app.readJSON(w, r, &input); err != nil {
 			app.badRequestResponse(w, r, fmt.Errorf("unable to parse upload: %v", err))
 			return
@@ -643,13 +637,13 @@ func (app *application) uploadPhoto(w http.ResponseWriter, r *http.Request) {
 			}
 
 			// Update student photo URL
-			student, err := app.models.Students.GetByID(claims.UserID)
+			student, err := app.models.Students.GetByID(r.Context(), claims.UserID)
 			if err != nil {
 				app.serverErrorResponse(w, r, err)
 				return
 			}
 			student.PhotoURL = &photoURL
-			if err := app.models.Students.UpdateBasicInfo(student); err != nil {
+			if err := app.models.Students.UpdateBasicInfo(r.Context(), student); err != nil {
 				app.serverErrorResponse(w, r, err)
 				return
 			}
@@ -688,14 +682,13 @@ func (app *application) uploadPhoto(w http.ResponseWriter, r *http.Request) {
 	filename := fmt.Sprintf("%d_%d%s", claims.UserID, time.Now().Unix(), ext)
 
 	// Create uploads directory if not exists
-	uploadDir := "./uploads/photos"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+	if err := os.MkdirAll(app.photoDir(), 0755); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Save file
-	filePath := filepath.Join(uploadDir, filename)
+	filePath := filepath.Join(app.photoDir(), filename)
 	dst, err := os.Create(filePath)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
@@ -709,15 +702,15 @@ func (app *application) uploadPhoto(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update student photo URL
-	photoURL := "/uploads/photos/" + filename
-	student, err := app.models.Students.GetByID(claims.UserID)
+	photoURL := uploadedPhotoPrefix + filename
+	student, err := app.models.Students.GetByID(r.Context(), claims.UserID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 	student.PhotoURL = &photoURL
 
-	if err := app.models.Students.UpdateBasicInfo(student); err != nil {
+	if err := app.models.Students.UpdateBasicInfo(r.Context(), student); err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
@@ -754,39 +747,43 @@ func (app *application) saveBase64Photo(studentID int64, base64Data string) (str
 	if err != nil {
 		return "", fmt.Errorf("invalid base64 data: %v", err)
 	}
+	if len(decoded) > app.config.Uploads.MaxPhotoMB<<20 {
+		return "", fmt.Errorf("photo must not be larger than %d MB", app.config.Uploads.MaxPhotoMB)
+	}
 
 	// Create uploads directory
-	uploadDir := "./uploads/photos"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+	if err := os.MkdirAll(app.photoDir(), 0755); err != nil {
 		return "", err
 	}
 
 	// Generate filename
 	filename := fmt.Sprintf("%d_%d%s", studentID, time.Now().Unix(), ext)
-	filePath := filepath.Join(uploadDir, filename)
+	filePath := filepath.Join(app.photoDir(), filename)
 
 	// Write file
 	if err := os.WriteFile(filePath, decoded, 0644); err != nil {
 		return "", err
 	}
 
-	return "/uploads/photos/" + filename, nil
+	return uploadedPhotoPrefix + filename, nil
 }
 
 // ============================================
 // COMMON ROUTES
 // ============================================
 
-// getSkills returns all available skills
+// getSkills returns the skill catalogue of the caller's institution
 func (app *application) getSkills(w http.ResponseWriter, r *http.Request) {
-	skills, err := app.models.Skills.GetAll()
+	claims := app.contextGetClaims(r)
+
+	skills, err := app.models.Skills.GetAll(r.Context(), claims.InstitutionID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
 	// Group by category
-	grouped, err := app.models.Skills.GetGroupedByCategory()
+	grouped, err := app.models.Skills.GetGroupedByCategory(r.Context(), claims.InstitutionID)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -798,9 +795,11 @@ func (app *application) getSkills(w http.ResponseWriter, r *http.Request) {
 	}, nil)
 }
 
-// getBatches returns all batches
+// getBatches returns the batches of the caller's institution
 func (app *application) getBatches(w http.ResponseWriter, r *http.Request) {
-	batches, err := app.models.Analytics.GetBatches()
+	claims := app.contextGetClaims(r)
+
+	batches, err := app.models.Analytics.GetBatches(r.Context(), app.institutionScope(r, claims))
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateAcademics(t *testing.T) {
+	app := newTestApplication()
+	app.models = memstore.New().Models()
+
+	student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: "asha@kct.ac.in", Name: "Asha"}
+	if err := app.models.Students.Insert(t.Context(), student); err != nil {
+		t.Fatal(err)
+	}
+	claims := &auth.Claims{UserID: student.ID, Role: "student", InstitutionID: student.InstitutionID}
+
+	put := func(body string) int {
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/student/profile/academics", strings.NewReader(body))
+		r = app.contextSetClaims(r, claims)
+		rr := httptest.NewRecorder()
+		app.updateAcademics(rr, r)
+		return rr.Code
+	}
+
+	if code := put(`{"cgpa_overall": 8.4, "current_backlogs": 1, "has_backlog_history": true}`); code != http.StatusOK {
+		t.Fatalf("valid academics: got %d, want %d", code, http.StatusOK)
+	}
+	if code := put(`{"cgpa_overall": 12}`); code != http.StatusUnprocessableEntity {
+		t.Fatalf("out-of-range cgpa: got %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+
+	// The rejected request must not have overwritten the saved row
+	academics, err := app.models.Students.GetAcademics(t.Context(), student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if academics == nil || academics.CGPAOverall == nil || *academics.CGPAOverall != 8.4 || academics.CurrentBacklogs != 1 {
+		t.Errorf("saved academics = %+v", academics)
+	}
+}
+
+func TestStudentDepartment(t *testing.T) {
+	app := newTestApplication()
+	app.models = memstore.New().Models()
+
+	cse := "CSE"
+	student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: "asha@kct.ac.in", Name: "Asha", Department: &cse}
+	if err := app.models.Students.Insert(t.Context(), student); err != nil {
+		t.Fatal(err)
+	}
+
+	// Students cannot move themselves into another coordinator's view
+	self := &auth.Claims{UserID: student.ID, Role: "student", InstitutionID: student.InstitutionID}
+	for _, update := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/v1/student/profile", app.updateStudentProfile},
+		{"/api/v1/student/profile/personal", app.updatePersonalDetails},
+	} {
+		r := app.contextSetClaims(httptest.NewRequest(http.MethodPut, update.path, strings.NewReader(`{"department": "ECE"}`)), self)
+		rr := httptest.NewRecorder()
+		update.handler(rr, r)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s with a department: got %d, want %d", update.path, rr.Code, http.StatusBadRequest)
+		}
+	}
+
+	move := func(claims *auth.Claims, body string) int {
+		r := httptest.NewRequest(http.MethodPut, "/api/v1/admin/students/"+strconv.FormatInt(student.ID, 10)+"/department", strings.NewReader(body))
+		r = mux.SetURLVars(app.contextSetClaims(r, claims), map[string]string{"id": strconv.FormatInt(student.ID, 10)})
+		rr := httptest.NewRecorder()
+		app.updateStudentDepartment(rr, r)
+		return rr.Code
+	}
+
+	hod := &auth.Claims{UserID: 7, Role: "admin", AdminRole: auth.RoleDepartmentCoordinator, Department: "CSE", InstitutionID: memstore.DefaultInstitutionID}
+	if code := move(hod, `{"department": "ECE"}`); code != http.StatusForbidden {
+		t.Errorf("department coordinator: got %d, want %d", code, http.StatusForbidden)
+	}
+
+	coordinator := &auth.Claims{UserID: 8, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID}
+	if code := move(coordinator, `{"department": " "}`); code != http.StatusUnprocessableEntity {
+		t.Errorf("blank department: got %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	if code := move(coordinator, `{"department": "ECE"}`); code != http.StatusOK {
+		t.Fatalf("placement coordinator: got %d, want %d", code, http.StatusOK)
+	}
+
+	saved, err := app.models.Students.GetByID(t.Context(), student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Department == nil || *saved.Department != "ECE" {
+		t.Errorf("department = %v, want ECE", saved.Department)
+	}
+}
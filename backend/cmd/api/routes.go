@@ -6,78 +6,165 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// apiV1 prefixes every API route. The unversioned paths used before it are
+// still served through legacyPaths.
+const apiV1 = "/api/v1"
+
 func (app *application) routes() http.Handler {
+	return app.realIP(app.requestID(app.logRequests(app.recoverPanic(app.enableCORS(app.legacyPaths(app.router()))))))
+}
+
+// router registers every route. Each one needs an entry in apiOperations.
+func (app *application) router() *mux.Router {
 	router := mux.NewRouter()
+	router.Use(app.recordRoute)
+	router.NotFoundHandler = http.HandlerFunc(app.notFoundResponse)
+	router.MethodNotAllowedHandler = http.HandlerFunc(app.methodNotAllowedResponse)
+
+	// Every route is registered on one of these subrouters, and its guard
+	// decides who may call it. Anything registered elsewhere is refused.
+	guarded := map[*mux.Route]bool{}
+	guard := func(route *mux.Route, middleware mux.MiddlewareFunc) *mux.Router {
+		guarded[route] = true
+		sub := route.Subrouter()
+		sub.Use(middleware)
+		return sub
+	}
+
+	public := guard(router.NewRoute(), app.allowAnonymous)
+	authenticated := guard(router.NewRoute(), app.authenticate)
+	student := guard(router.PathPrefix(apiV1+"/student"), app.requireStudent)
+	admin := guard(router.PathPrefix(apiV1+"/admin"), app.requireStaff)
+	scraper := guard(router.NewRoute(), app.requireMetricsToken)
+
+	// Rate limits per route group, keyed by user once authenticated and by IP before
+	limits := app.config.RateLimit
+	signIn := public.PathPrefix(apiV1 + "/auth").Subrouter()
+	signIn.Use(app.rateLimit("auth", limits.Auth))
+	student.Use(app.rateLimit("student", limits.Student))
+	admin.Use(app.rateLimit("admin", limits.Admin))
+	upload := app.rateLimit("upload", limits.Upload)
+	export := app.rateLimit("export", limits.Export)
+
+	// Liveness and readiness probes
+	public.HandleFunc("/health", app.healthCheckHandler).Methods(http.MethodGet)
+	public.HandleFunc("/ready", app.readinessHandler).Methods(http.MethodGet)
+
+	// Prometheus metrics, protected by METRICS_TOKEN
+	scraper.Handle("/metrics", app.metricsHandler()).Methods(http.MethodGet)
 
-	// Health check
-	router.HandleFunc("/health", app.healthCheckHandler).Methods(http.MethodGet)
+	// Public keys for services that verify our access tokens
+	public.HandleFunc("/.well-known/jwks.json", app.jwksHandler).Methods(http.MethodGet)
+
+	// API reference
+	public.HandleFunc(apiV1+"/openapi.json", app.openAPIHandler).Methods(http.MethodGet)
+	public.HandleFunc(apiV1+"/docs", app.apiDocsHandler).Methods(http.MethodGet)
 
 	// ============================================
 	// AUTH ROUTES
 	// ============================================
-	router.HandleFunc("/auth/login", app.loginHandler).Methods(http.MethodGet)
-	router.HandleFunc("/auth/callback", app.callbackHandler).Methods(http.MethodGet)
-	router.HandleFunc("/auth/me", app.getCurrentUser).Methods(http.MethodGet)
+	public.HandleFunc(apiV1+"/auth/providers", app.listProvidersHandler).Methods(http.MethodGet)
+	signIn.HandleFunc("/login/{provider}", app.loginHandler).Methods(http.MethodGet)
+	signIn.HandleFunc("/callback/{provider}", app.callbackHandler).Methods(http.MethodGet)
+	// Legacy routes, kept for the redirect URI registered in Azure AD
+	signIn.HandleFunc("/login", app.loginHandler).Methods(http.MethodGet)
+	signIn.HandleFunc("/callback", app.callbackHandler).Methods(http.MethodGet)
+	signIn.HandleFunc("/exchange", app.exchangeHandler).Methods(http.MethodPost)
+	authenticated.HandleFunc(apiV1+"/auth/me", app.getCurrentUser).Methods(http.MethodGet)
+	signIn.HandleFunc("/refresh", app.refreshHandler).Methods(http.MethodPost)
+	// Logout checks the token itself so it can clear stale cookies on failure
+	public.HandleFunc(apiV1+"/auth/logout", app.logoutHandler).Methods(http.MethodPost)
 
 	// ============================================
 	// STUDENT ROUTES
 	// ============================================
-	router.HandleFunc("/api/student/profile", app.getStudentProfile).Methods(http.MethodGet)
-	router.HandleFunc("/api/student/profile", app.updateStudentProfile).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/personal", app.updatePersonalDetails).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/family", app.updateFamilyDetails).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/academics", app.updateAcademics).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/achievements", app.updateAchievements).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/aspirations", app.updateAspirations).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/skills", app.updateSkills).Methods(http.MethodPut)
-	router.HandleFunc("/api/student/profile/complete", app.completeProfile).Methods(http.MethodPost)
-	router.HandleFunc("/api/student/photo", app.uploadPhoto).Methods(http.MethodPost)
+	student.HandleFunc("/profile", app.getStudentProfile).Methods(http.MethodGet)
+	student.HandleFunc("/profile", app.updateStudentProfile).Methods(http.MethodPut)
+	student.HandleFunc("/profile/personal", app.updatePersonalDetails).Methods(http.MethodPut)
+	student.HandleFunc("/profile/family", app.updateFamilyDetails).Methods(http.MethodPut)
+	student.HandleFunc("/profile/academics", app.updateAcademics).Methods(http.MethodPut)
+	student.HandleFunc("/profile/achievements", app.updateAchievements).Methods(http.MethodPut)
+	student.HandleFunc("/profile/aspirations", app.updateAspirations).Methods(http.MethodPut)
+	student.HandleFunc("/profile/skills", app.updateSkills).Methods(http.MethodPut)
+	student.HandleFunc("/profile/complete", app.completeProfile).Methods(http.MethodPost)
+	student.Handle("/photo", upload(http.HandlerFunc(app.uploadPhoto))).Methods(http.MethodPost)
 
 	// ============================================
 	// ADMIN ROUTES
 	// ============================================
 
 	// Dashboard & Analytics
-	router.HandleFunc("/api/admin/dashboard", app.getDashboard).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/analytics/batch", app.getBatchStats).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/analytics/skills", app.getSkillStats).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/analytics/cgpa", app.getCGPADistribution).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/analytics/companies", app.getCompanyStats).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/activity", app.getRecentActivity).Methods(http.MethodGet)
+	admin.HandleFunc("/dashboard", app.getDashboard).Methods(http.MethodGet)
+	admin.HandleFunc("/analytics/batch", app.getBatchStats).Methods(http.MethodGet)
+	admin.HandleFunc("/analytics/skills", app.getSkillStats).Methods(http.MethodGet)
+	admin.HandleFunc("/analytics/cgpa", app.getCGPADistribution).Methods(http.MethodGet)
+	admin.HandleFunc("/analytics/companies", app.getCompanyStats).Methods(http.MethodGet)
+	admin.HandleFunc("/analytics/history", app.getAnalyticsHistory).Methods(http.MethodGet)
+	admin.HandleFunc("/activity", app.getRecentActivity).Methods(http.MethodGet)
 
 	// Student Management - IMPORTANT: specific routes before parameterized routes
-	router.HandleFunc("/api/admin/students/export", app.exportStudentsCSV).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/students/roll/{rollno}", app.getStudentByRollNo).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/students/{id:[0-9]+}/status", app.updateStudentStatus).Methods(http.MethodPut, http.MethodPatch)
-	router.HandleFunc("/api/admin/students/{id:[0-9]+}", app.getStudentByID).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/students", app.listStudents).Methods(http.MethodGet)
+	admin.Handle("/students/export", export(http.HandlerFunc(app.exportStudentsCSV))).Methods(http.MethodGet)
+	admin.Handle("/students/export/jobs", export(http.HandlerFunc(app.createExportJob))).Methods(http.MethodPost)
+	admin.HandleFunc("/students/roll/{rollno}", app.getStudentByRollNo).Methods(http.MethodGet)
+	admin.HandleFunc("/students/{id:[0-9]+}/status", app.updateStudentStatus).Methods(http.MethodPut, http.MethodPatch)
+	admin.HandleFunc("/students/{id:[0-9]+}/department", app.updateStudentDepartment).Methods(http.MethodPut, http.MethodPatch)
+	admin.HandleFunc("/students/{id:[0-9]+}/sessions", app.revokeStudentSessions).Methods(http.MethodDelete)
+	admin.HandleFunc("/students/{id:[0-9]+}/impersonate", app.impersonateStudent).Methods(http.MethodPost)
+	admin.HandleFunc("/students/{id:[0-9]+}", app.getStudentByID).Methods(http.MethodGet)
+	admin.HandleFunc("/students", app.listStudents).Methods(http.MethodGet)
 
 	// Placement Management
-	router.HandleFunc("/api/admin/placements/{id:[0-9]+}", app.updatePlacement).Methods(http.MethodPut)
-	router.HandleFunc("/api/admin/placements/{id:[0-9]+}", app.deletePlacement).Methods(http.MethodDelete)
-	router.HandleFunc("/api/admin/placements", app.listPlacements).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/placements", app.createPlacement).Methods(http.MethodPost)
+	admin.HandleFunc("/placements/{id:[0-9]+}", app.updatePlacement).Methods(http.MethodPut)
+	admin.HandleFunc("/placements/{id:[0-9]+}", app.deletePlacement).Methods(http.MethodDelete)
+	admin.HandleFunc("/placements", app.listPlacements).Methods(http.MethodGet)
+	admin.HandleFunc("/placements", app.createPlacement).Methods(http.MethodPost)
 
 	// Company Management - specific routes before parameterized routes
-	router.HandleFunc("/api/admin/companies/search", app.searchCompanies).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/companies/{id:[0-9]+}", app.updateCompany).Methods(http.MethodPut)
-	router.HandleFunc("/api/admin/companies/{id:[0-9]+}", app.deleteCompany).Methods(http.MethodDelete)
-	router.HandleFunc("/api/admin/companies", app.listCompanies).Methods(http.MethodGet)
-	router.HandleFunc("/api/admin/companies", app.createCompany).Methods(http.MethodPost)
+	admin.HandleFunc("/companies/search", app.searchCompanies).Methods(http.MethodGet)
+	admin.HandleFunc("/companies/{id:[0-9]+}", app.updateCompany).Methods(http.MethodPut)
+	admin.HandleFunc("/companies/{id:[0-9]+}", app.deleteCompany).Methods(http.MethodDelete)
+	admin.HandleFunc("/companies", app.listCompanies).Methods(http.MethodGet)
+	admin.HandleFunc("/companies", app.createCompany).Methods(http.MethodPost)
+
+	// Admin Account Management - specific routes before parameterized routes
+	admin.HandleFunc("/admins/audit", app.getAdminAuditLog).Methods(http.MethodGet)
+	admin.HandleFunc("/admins/{id:[0-9]+}/deactivate", app.deactivateAdmin).Methods(http.MethodPost)
+	admin.HandleFunc("/admins/{id:[0-9]+}/reactivate", app.reactivateAdmin).Methods(http.MethodPost)
+	admin.HandleFunc("/admins/{id:[0-9]+}/sessions", app.revokeAdminSessions).Methods(http.MethodDelete)
+	admin.HandleFunc("/admins/{id:[0-9]+}", app.updateAdmin).Methods(http.MethodPut, http.MethodPatch)
+	admin.HandleFunc("/admins", app.listAdmins).Methods(http.MethodGet)
+	admin.HandleFunc("/admins", app.inviteAdmin).Methods(http.MethodPost)
+
+	// API Keys for service accounts
+	admin.HandleFunc("/api-keys/{id:[0-9]+}", app.revokeAPIKey).Methods(http.MethodDelete)
+	admin.HandleFunc("/api-keys", app.listAPIKeys).Methods(http.MethodGet)
+	admin.HandleFunc("/api-keys", app.createAPIKey).Methods(http.MethodPost)
+
+	// ERP Integration
+	admin.HandleFunc("/academics/sync", app.syncAcademics).Methods(http.MethodPost)
+
+	// Background jobs
+	admin.HandleFunc("/jobs/{id:[0-9]+}/retry", app.retryJob).Methods(http.MethodPost)
+	admin.HandleFunc("/jobs/{id:[0-9]+}/download", app.downloadJobResult).Methods(http.MethodGet)
+	admin.HandleFunc("/jobs/{id:[0-9]+}", app.getJob).Methods(http.MethodGet)
+	admin.HandleFunc("/jobs", app.listJobs).Methods(http.MethodGet)
+
+	// Scheduled maintenance
+	admin.HandleFunc("/schedule/runs", app.listScheduleRuns).Methods(http.MethodGet)
+	admin.HandleFunc("/schedule", app.getSchedule).Methods(http.MethodGet)
+
+	// Institutions (group admins only)
+	admin.HandleFunc("/institutions/{id:[0-9]+}", app.updateInstitution).Methods(http.MethodPut, http.MethodPatch)
+	admin.HandleFunc("/institutions", app.listInstitutions).Methods(http.MethodGet)
+	admin.HandleFunc("/institutions", app.createInstitution).Methods(http.MethodPost)
 
 	// ============================================
 	// COMMON ROUTES
 	// ============================================
-	router.HandleFunc("/api/skills", app.getSkills).Methods(http.MethodGet)
-	router.HandleFunc("/api/batches", app.getBatches).Methods(http.MethodGet)
+	authenticated.HandleFunc(apiV1+"/skills", app.getSkills).Methods(http.MethodGet)
+	authenticated.HandleFunc(apiV1+"/batches", app.getBatches).Methods(http.MethodGet)
 
-	return app.enableCORS(router)
-}
+	app.failClosed(router, guarded)
 
-func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-	app.writeJSON(w, http.StatusOK, envelope{
-		"status":  "healthy",
-		"service": "placement-api",
-		"env":     app.config.env,
-	}, nil)
+	return router
 }
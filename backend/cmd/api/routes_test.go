@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/config"
+	"github.com/gorilla/mux"
+)
+
+func newTestApplication() *application {
+	return &application{
+		config:  config.Default(),
+		logger:  newLogger(io.Discard, "error"),
+		metrics: newMetrics(),
+	}
+}
+
+func TestGuardedRoutesRequireAuthentication(t *testing.T) {
+	app := newTestApplication()
+	var logs strings.Builder
+	app.logger = newLogger(&logs, "warn")
+
+	handler := app.routes()
+	if logs.Len() > 0 {
+		t.Fatalf("routes() reported unguarded routes:\n%s", logs.String())
+	}
+
+	for _, path := range []string{"/api/admin/dashboard", "/api/student/profile", "/api/skills", "/auth/me"} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s without a token: got %d, want %d", path, rr.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestUnguardedRouteFailsClosed(t *testing.T) {
+	app := newTestApplication()
+	router := mux.NewRouter()
+	guarded := map[*mux.Route]bool{}
+
+	publicRoute := router.NewRoute()
+	guarded[publicRoute] = true
+	public := publicRoute.Subrouter()
+
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	public.HandleFunc("/open", ok)
+	router.HandleFunc("/forgotten", ok)
+
+	app.failClosed(router, guarded)
+
+	tests := map[string]int{
+		"/open":      http.StatusOK,
+		"/forgotten": http.StatusForbidden,
+	}
+
+	for path, want := range tests {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if rr.Code != want {
+			t.Errorf("GET %s: got %d, want %d", path, rr.Code, want)
+		}
+	}
+}
+
+func TestVersionedErrorEnvelope(t *testing.T) {
+	handler := newTestApplication().routes()
+
+	tests := []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{http.MethodGet, apiV1 + "/skills", http.StatusUnauthorized, codeAuthenticationNeeded},
+		{http.MethodGet, apiV1 + "/no-such-route", http.StatusNotFound, codeNotFound},
+		{http.MethodDelete, apiV1 + "/auth/providers", http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+		var body struct {
+			Error apiError `json:"error"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s: %v in %s", tt.method, tt.path, err, rr.Body.String())
+		}
+		if rr.Code != tt.status || body.Error.Code != tt.code || body.Error.Message == "" || body.Error.RequestID == "" {
+			t.Errorf("%s %s: got %d %+v, want %d %s", tt.method, tt.path, rr.Code, body.Error, tt.status, tt.code)
+		}
+		if rr.Header().Get("Deprecation") != "" {
+			t.Errorf("%s %s: versioned path marked deprecated", tt.method, tt.path)
+		}
+	}
+}
+
+func TestLegacyPaths(t *testing.T) {
+	app := newTestApplication()
+	app.config.API.LegacySunset = "2027-06-30"
+	handler := app.routes()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/skills", nil))
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("GET /api/skills: got %d, want %d", rr.Code, http.StatusUnauthorized)
+	}
+	if rr.Header().Get("Deprecation") != "true" || rr.Header().Get("Sunset") != "Wed, 30 Jun 2027 00:00:00 GMT" {
+		t.Errorf("missing deprecation headers: %v", rr.Header())
+	}
+	if got := rr.Header().Get("Link"); got != `</api/v1/skills>; rel="successor-version"` {
+		t.Errorf("Link: got %q", got)
+	}
+
+	// Legacy clients read the message from "error"
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body["error"].(string); !ok || body["code"] != codeAuthenticationNeeded {
+		t.Errorf("legacy error envelope changed shape: %v", body)
+	}
+
+	// Probes are not versioned
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rr.Code != http.StatusOK || rr.Header().Get("Deprecation") != "" {
+		t.Errorf("GET /health: got %d, Deprecation %q", rr.Code, rr.Header().Get("Deprecation"))
+	}
+
+	app.config.API.LegacyPaths = false
+	handler = app.routes()
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/auth/providers", nil))
+	if rr.Code != http.StatusGone || !strings.Contains(rr.Body.String(), codeEndpointRetired) {
+		t.Errorf("retired path: got %d %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/ratelimit"
+	"github.com/VJ-2303/placement-profiling-system/internal/schedule"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+// Scheduled task names. A name keys the run history, so never rename one.
+const (
+	taskRecomputeEligibility = "recompute-eligibility"
+	taskDashboardSnapshot    = "dashboard-snapshot"
+	taskProfileReminders     = "profile-reminders"
+	taskPurgeUploadFiles     = "purge-upload-files"
+	taskPurgeExpired         = "purge-expired-records"
+)
+
+// staleTempFile is the age after which a dot-prefixed file under the uploads
+// directory is assumed to be left over from a crash. Temporary files are
+// renamed into place or removed within seconds.
+const staleTempFile = time.Hour
+
+// registerTasks adds the maintenance tasks to the scheduler. Cron
+// expressions are read in SCHEDULE_TIMEZONE.
+func (app *application) registerTasks(s *schedule.Scheduler) error {
+	tasks := []schedule.Task{
+		{Name: taskRecomputeEligibility, Spec: "0 * * * *", Run: app.recomputeEligibility,
+			Description: "Recompute every student's placement eligibility from the configured rule"},
+		{Name: taskDashboardSnapshot, Spec: "55 23 * * *", Run: app.snapshotDashboards,
+			Description: "Save each institution's dashboard stats for trend charts"},
+		{Name: taskProfileReminders, Spec: "0 9 * * 1", Run: app.sendProfileReminders,
+			Description: "Remind students with incomplete profiles to finish them"},
+		{Name: taskPurgeUploadFiles, Spec: "30 2 * * *", Run: app.purgeUploadFiles,
+			Description: "Delete leftover temporary upload files and expired export files"},
+		{Name: taskPurgeExpired, Spec: "45 2 * * *", Run: app.purgeExpiredRecords,
+			Description: "Delete expired sessions, login codes and idle rate limit buckets"},
+	}
+
+	for _, task := range tasks {
+		if err := s.Add(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taskNames lists the registered tasks, for validating ?task=
+func taskNames() []string {
+	return []string{taskRecomputeEligibility, taskDashboardSnapshot, taskProfileReminders, taskPurgeUploadFiles, taskPurgeExpired}
+}
+
+// ============================================
+// MAINTENANCE TASKS
+// ============================================
+
+// recomputeEligibility applies the eligibility rule to every student
+func (app *application) recomputeEligibility(ctx context.Context) (string, error) {
+	rule := models.EligibilityRule{
+		MinCGPA:     app.config.Eligibility.MinCGPA,
+		MaxBacklogs: app.config.Eligibility.MaxBacklogs,
+	}
+
+	changed, err := app.models.Students.RecomputeEligibility(ctx, rule)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d students changed eligibility", changed), nil
+}
+
+// snapshotDashboards saves today's dashboard stats for each active
+// institution. It runs just before midnight so the snapshot closes the day.
+func (app *application) snapshotDashboards(ctx context.Context) (string, error) {
+	institutions, err := app.models.Institutions.GetAll(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	today := time.Now().In(app.config.Schedule.Location)
+	saved := 0
+	var errs []error
+	for _, inst := range institutions {
+		if !inst.IsActive {
+			continue
+		}
+
+		// One institution failing should not cost the others their snapshot
+		stats, err := app.models.Analytics.GetDashboardStats(ctx, &inst.ID, nil, nil)
+		if err == nil {
+			err = app.models.Analytics.SaveDashboardSnapshot(ctx, inst.ID, today, stats)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("institution %s: %w", inst.Code, err))
+			continue
+		}
+		saved++
+	}
+
+	return fmt.Sprintf("saved %d snapshots for %s", saved, today.Format(time.DateOnly)), errors.Join(errs...)
+}
+
+// sendProfileReminders notifies every student whose profile is not yet submitted
+func (app *application) sendProfileReminders(ctx context.Context) (string, error) {
+	incomplete := false
+	result, err := app.models.Students.Export(ctx, models.StudentFilter{ProfileCompleted: &incomplete})
+	if err != nil {
+		return "", err
+	}
+
+	sent := 0
+	for _, student := range result.Students {
+		if ctx.Err() != nil {
+			break
+		}
+		body := fmt.Sprintf("Hi %s,\n\nYour placement profile is not complete yet. Finish it at %s so placement coordinators can consider you for upcoming drives.",
+			student.Name, app.config.Frontend.URL)
+		if err := app.notifier.Notify(ctx, student.OfficialEmail, "Complete your placement profile", body); err != nil {
+			return fmt.Sprintf("reminded %d of %d students", sent, len(result.Students)), err
+		}
+		sent++
+	}
+
+	return fmt.Sprintf("reminded %d of %d students", sent, len(result.Students)), ctx.Err()
+}
+
+// purgeUploadFiles removes temporary files left under the uploads directory
+// by a crash, and export files older than the export retention. Only the
+// leader's disk is cleaned.
+func (app *application) purgeUploadFiles(ctx context.Context) (string, error) {
+	now := time.Now()
+	temps, exports := 0, 0
+
+	err := filepath.WalkDir(app.config.Uploads.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// The directory does not exist until the first upload
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed since the directory was read
+		}
+		age := now.Sub(info.ModTime())
+
+		switch {
+		case strings.HasPrefix(d.Name(), ".") && age > staleTempFile:
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			temps++
+		case filepath.Dir(path) == app.exportDir() && strings.HasPrefix(d.Name(), "job-") && age > app.config.Schedule.ExportRetention:
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			exports++
+		}
+		return nil
+	})
+
+	return fmt.Sprintf("removed %d temporary files and %d expired exports", temps, exports), err
+}
+
+// purgeExpiredRecords deletes rows that can no longer be used
+func (app *application) purgeExpiredRecords(ctx context.Context) (string, error) {
+	now := time.Now()
+
+	sessions, err := app.models.Sessions.DeleteExpired(ctx, now)
+	if err != nil {
+		return "", fmt.Errorf("sessions: %w", err)
+	}
+	codes, err := app.models.AuthCodes.DeleteExpired(ctx, now)
+	if err != nil {
+		return "", fmt.Errorf("auth codes: %w", err)
+	}
+	summary := fmt.Sprintf("deleted %d sessions and %d login codes", sessions, codes)
+
+	// Limits are per minute, so an hour-old bucket has long been full again
+	if limiter, ok := app.rateLimiter.(*ratelimit.PostgresLimiter); ok {
+		buckets, err := limiter.Cleanup(ctx, time.Hour)
+		if err != nil {
+			return summary, fmt.Errorf("rate limit buckets: %w", err)
+		}
+		summary += fmt.Sprintf(" and %d rate limit buckets", buckets)
+	}
+
+	return summary, nil
+}
+
+// ============================================
+// NOTIFICATIONS
+// ============================================
+
+// notifier delivers messages to students
+type notifier interface {
+	Notify(ctx context.Context, to, subject, body string) error
+}
+
+// logNotifier writes each message to the log instead of delivering it; it
+// stands in until a mail transport is configured
+type logNotifier struct {
+	logger *slog.Logger
+}
+
+func (n logNotifier) Notify(ctx context.Context, to, subject, body string) error {
+	n.logger.InfoContext(ctx, "notification not delivered; no mail transport is configured", "to", to, "subject", subject)
+	return nil
+}
+
+// ============================================
+// SCHEDULE ADMINISTRATION
+// ============================================
+
+// getSchedule lists the scheduled tasks with their next and last runs, and
+// whether this replica is the one running them
+func (app *application) getSchedule(w http.ResponseWriter, r *http.Request) {
+	if _, err := app.requirePermission(r, auth.PermJobsManage); err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	tasks, err := app.scheduler.Status(r.Context())
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{
+		"enabled":  app.config.Schedule.Enabled,
+		"leader":   app.scheduler.IsLeader(),
+		"instance": app.scheduler.Instance(),
+		"timezone": app.config.Schedule.Location.String(),
+		"tasks":    tasks,
+	}, nil)
+}
+
+// listScheduleRuns lists recent task runs, newest first
+func (app *application) listScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	if _, err := app.requirePermission(r, auth.PermJobsManage); err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+
+	qs := r.URL.Query()
+	task := qs.Get("task")
+	limit := app.readInt(qs, "limit", 50)
+
+	v := validator.New()
+	v.OneOf("task", &task, taskNames()...)
+	v.IntRange("limit", &limit, 1, 200)
+	if !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	runs, err := app.scheduler.History.List(r.Context(), task, limit)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"runs": runs}, nil)
+}
+
+// getAnalyticsHistory returns the daily dashboard snapshots of one
+// institution. Snapshots cover every department, so department coordinators
+// cannot read them.
+func (app *application) getAnalyticsHistory(w http.ResponseWriter, r *http.Request) {
+	claims, err := app.requirePermission(r, auth.PermAnalyticsRead)
+	if err != nil {
+		app.authErrorResponse(w, r, err)
+		return
+	}
+	if app.departmentScope(claims) != nil {
+		app.forbiddenResponse(w, r)
+		return
+	}
+
+	days := app.readInt(r.URL.Query(), "days", 30)
+	v := validator.New()
+	if v.IntRange("days", &days, 1, 366); !v.Valid() {
+		app.validationErrorResponse(w, r, v.Errors)
+		return
+	}
+
+	institutionID, err := app.targetInstitution(r, claims)
+	if err != nil {
+		if errors.Is(err, errUnknownInstitution) {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	since := time.Now().In(app.config.Schedule.Location).AddDate(0, 0, -days+1)
+	snapshots, err := app.models.Analytics.GetDashboardSnapshots(r.Context(), institutionID, since)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	app.writeJSONWithETag(w, r, envelope{"snapshots": snapshots})
+}
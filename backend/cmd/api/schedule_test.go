@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+)
+
+// recordingNotifier keeps the addresses it was asked to notify
+type recordingNotifier struct {
+	to []string
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, to, subject, body string) error {
+	n.to = append(n.to, to)
+	return nil
+}
+
+func newTaskApplication(t *testing.T) *application {
+	app := newTestApplication()
+	app.models = memstore.New().Models()
+	app.config.Uploads.Dir = t.TempDir()
+	app.config.Schedule.Location = time.UTC
+	return app
+}
+
+func TestPurgeUploadFiles(t *testing.T) {
+	app := newTaskApplication(t)
+
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	files := map[string]time.Time{
+		"photos/1_1.jpg":            old,
+		"photos/.upload-stale":      old,
+		"exports/.export-stale":     time.Now().Add(-2 * time.Hour),
+		"exports/.export-writing":   time.Now(),
+		"exports/job-1.csv":         old,
+		"exports/job-2.csv":         time.Now(),
+		"exports/students_2024.csv": old,
+	}
+	for name, modified := range files {
+		path := filepath.Join(app.config.Uploads.Dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modified, modified)
+	}
+
+	summary, err := app.purgeUploadFiles(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "removed 2 temporary files and 1 expired exports" {
+		t.Errorf("summary: %q", summary)
+	}
+
+	for name := range files {
+		_, err := os.Stat(filepath.Join(app.config.Uploads.Dir, name))
+		removed := name == "photos/.upload-stale" || name == "exports/.export-stale" || name == "exports/job-1.csv"
+		if removed != os.IsNotExist(err) {
+			t.Errorf("%s: removed %v, want %v", name, os.IsNotExist(err), removed)
+		}
+	}
+
+	// A missing uploads directory is not an error
+	app.config.Uploads.Dir = filepath.Join(t.TempDir(), "missing")
+	if _, err := app.purgeUploadFiles(t.Context()); err != nil {
+		t.Errorf("missing uploads directory: %v", err)
+	}
+}
+
+func TestProfileReminders(t *testing.T) {
+	app := newTaskApplication(t)
+	notifier := &recordingNotifier{}
+	app.notifier = notifier
+
+	for _, name := range []string{"asha", "bala"} {
+		student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: name + "@kct.ac.in", Name: name}
+		if err := app.models.Students.Insert(t.Context(), student); err != nil {
+			t.Fatal(err)
+		}
+		if name == "asha" {
+			if err := app.models.Students.SetProfileCompleted(t.Context(), student.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	summary, err := app.sendProfileReminders(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(notifier.to, ",") != "bala@kct.ac.in" {
+		t.Errorf("reminded %v, want only bala", notifier.to)
+	}
+	if summary != "reminded 1 of 1 students" {
+		t.Errorf("summary: %q", summary)
+	}
+}
+
+func TestDashboardHistory(t *testing.T) {
+	app := newTaskApplication(t)
+	student := &models.Student{InstitutionID: memstore.DefaultInstitutionID, OfficialEmail: "asha@kct.ac.in", Name: "Asha"}
+	if err := app.models.Students.Insert(t.Context(), student); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.snapshotDashboards(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+	// An old snapshot outside the requested window
+	old := time.Now().AddDate(0, 0, -10)
+	app.models.Analytics.SaveDashboardSnapshot(t.Context(), memstore.DefaultInstitutionID, old, &models.DashboardStats{})
+
+	claims := &auth.Claims{UserID: 7, Role: "admin", AdminRole: auth.RolePlacementCoordinator, InstitutionID: memstore.DefaultInstitutionID}
+	r := app.contextSetClaims(httptest.NewRequest(http.MethodGet, "/api/v1/admin/analytics/history?days=7", nil), claims)
+	rr := httptest.NewRecorder()
+	app.getAnalyticsHistory(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	var body struct{ Snapshots []models.DashboardSnapshot }
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Snapshots) != 1 || body.Snapshots[0].Stats.TotalStudents != 1 {
+		t.Errorf("snapshots: %+v", body.Snapshots)
+	}
+
+	r = app.contextSetClaims(httptest.NewRequest(http.MethodGet, "/api/v1/admin/analytics/history?days=0", nil), claims)
+	rr = httptest.NewRecorder()
+	app.getAnalyticsHistory(rr, r)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("days=0: got %d, want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"syscall"
+	"time"
+)
+
+// Build information, set at link time:
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse HEAD) -X main.buildTime=$(date -u +%FT%TZ)"
+var (
+	version   = "dev"
+	commit    = ""
+	buildTime = ""
+)
+
+// buildInfo describes the running binary for /health. The commit and build
+// time fall back to what the Go toolchain stamped when built inside a checkout.
+func buildInfo() envelope {
+	info := envelope{
+		"version":    version,
+		"commit":     commit,
+		"build_time": buildTime,
+		"go_version": runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && commit == "":
+				info["commit"] = s.Value
+			case s.Key == "vcs.time" && buildTime == "":
+				info["build_time"] = s.Value
+			}
+		}
+	}
+	return info
+}
+
+// serve runs the server, the job workers and the scheduler until SIGINT or
+// SIGTERM, then drains and shuts down the server and stops the workers and
+// the scheduler
+func (app *application) serve(srv *http.Server) error {
+	shutdownError := make(chan error)
+
+	workersDone := make(chan struct{})
+	stopWorkers := app.startJobRunner(workersDone)
+	schedulerDone := make(chan struct{})
+	stopScheduler := app.startScheduler(schedulerDone)
+
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		s := <-quit
+
+		app.logger.Info("shutting down server", "signal", s.String(),
+			"drain", app.config.ReadinessDrain.String(), "timeout", app.config.ShutdownTimeout.String())
+
+		shutdownError <- app.shutdown(srv)
+	}()
+
+	app.logger.Info("starting server", "env", app.config.Env, "addr", srv.Addr, "version", version)
+
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	shutdownErr := <-shutdownError
+
+	// Interrupted jobs go back on the queue for another worker, and the
+	// leader lock is released for another replica to take
+	stopWorkers()
+	stopScheduler()
+	<-workersDone
+	<-schedulerDone
+
+	if shutdownErr != nil {
+		return fmt.Errorf("requests still running after %s: %w", app.config.ShutdownTimeout, shutdownErr)
+	}
+
+	app.logger.Info("stopped server", "addr", srv.Addr)
+	return nil
+}
+
+// shutdown fails readiness, keeps serving for the readiness drain so the load
+// balancer notices and stops routing here, then stops accepting connections
+// and waits up to the shutdown timeout for in-flight requests
+func (app *application) shutdown(srv *http.Server) error {
+	app.draining.Store(true)
+	time.Sleep(app.config.ReadinessDrain)
+
+	ctx, cancel := context.WithTimeout(context.Background(), app.config.ShutdownTimeout)
+	defer cancel()
+
+	return srv.Shutdown(ctx)
+}
+
+// startJobRunner runs the job workers in the background, closing done once
+// they have stopped. The returned function stops them.
+func (app *application) startJobRunner(done chan<- struct{}) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	if app.jobRunner == nil {
+		close(done)
+		return cancel
+	}
+
+	go func() {
+		defer close(done)
+		app.jobRunner.Run(ctx)
+	}()
+	return cancel
+}
+
+// startScheduler runs the scheduler in the background when it is enabled,
+// closing done once it has stopped. The returned function stops it.
+func (app *application) startScheduler(done chan<- struct{}) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	if app.scheduler == nil || !app.config.Schedule.Enabled {
+		close(done)
+		return cancel
+	}
+
+	go func() {
+		defer close(done)
+		app.scheduler.Run(ctx)
+	}()
+	return cancel
+}
+
+// ============================================
+// PROBES
+// ============================================
+
+// healthCheckHandler is the liveness probe: it only shows the process is serving
+func (app *application) healthCheckHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, envelope{
+		"status":  "healthy",
+		"service": "placement-api",
+		"env":     app.config.Env,
+		"build":   buildInfo(),
+	}, nil)
+}
+
+// readinessHandler reports whether this instance can take traffic: Postgres
+// answers, photo storage is writable and the server is not shutting down
+func (app *application) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if app.draining.Load() {
+		app.writeJSON(w, http.StatusServiceUnavailable, envelope{"status": "shutting down"}, nil)
+		return
+	}
+
+	checks := envelope{"database": "ok", "storage": "ok"}
+	ready := true
+
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	if err := app.models.DB.PingContext(ctx); err != nil {
+		app.logger.WarnContext(r.Context(), "readiness: database unavailable", "error", err)
+		checks["database"] = "unavailable"
+		ready = false
+	}
+
+	if err := checkWritable(app.photoDir()); err != nil {
+		app.logger.WarnContext(r.Context(), "readiness: upload storage not writable", "dir", app.photoDir(), "error", err)
+		checks["storage"] = "not writable"
+		ready = false
+	}
+
+	if !ready {
+		app.writeJSON(w, http.StatusServiceUnavailable, envelope{"status": "not ready", "checks": checks}, nil)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, envelope{"status": "ready", "checks": checks}, nil)
+}
+
+// checkWritable creates dir if needed and writes and removes a probe file in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(dir, ".ready-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+
+	_, err = f.WriteString("ok")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(name); err == nil {
+		err = removeErr
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthReportsBuildInfo(t *testing.T) {
+	handler := newTestApplication().routes()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("got %d, want 200", rr.Code)
+	}
+
+	var body struct {
+		Status string `json:"status"`
+		Build  struct {
+			Version   string `json:"version"`
+			GoVersion string `json:"go_version"`
+		} `json:"build"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "healthy" || body.Build.Version != version || body.Build.GoVersion == "" {
+		t.Errorf("unexpected health body: %s", rr.Body.String())
+	}
+}
+
+func TestReadyFailsWhileDraining(t *testing.T) {
+	app := newTestApplication()
+	app.draining.Store(true)
+
+	rr := httptest.NewRecorder()
+	app.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want 503", rr.Code)
+	}
+}
+
+func TestShutdownServesThroughReadinessDrain(t *testing.T) {
+	app := newTestApplication()
+	app.config.ReadinessDrain = 300 * time.Millisecond
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: app.routes()}
+	go srv.Serve(ln)
+
+	done := make(chan error, 1)
+	go func() { done <- app.shutdown(srv) }()
+	for !app.draining.Load() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The load balancer still reaches the server and sees it is leaving
+	res, err := http.Get("http://" + ln.Addr().String() + "/ready")
+	if err != nil {
+		t.Fatalf("server stopped serving before the drain ended: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/ready during the drain: got %d, want 503", res.StatusCode)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+	if _, err := http.Get("http://" + ln.Addr().String() + "/ready"); err == nil {
+		t.Error("server still accepting connections after shutdown")
+	}
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "uploads", "photos")
+	if err := checkWritable(dir); err != nil {
+		t.Fatalf("writable directory: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("probe file was left behind: %v", entries)
+	}
+
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkWritable(file); err == nil {
+		t.Error("expected an error when the path is a file")
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/auth"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+// Permitted values of the Postgres enums and free-text columns that only make
+// sense with a fixed vocabulary (see migrations/001_initial_schema.sql)
+var (
+	genders           = []string{"male", "female", "other"}
+	residenceTypes    = []string{"day_scholar", "hosteler"}
+	bloodGroups       = []string{"A+", "A-", "B+", "B-", "AB+", "AB-", "O+", "O-"}
+	placementStatuses = []string{
+		string(models.PlacementStatusNotPlaced),
+		string(models.PlacementStatusInProcess),
+		string(models.PlacementStatusPlaced),
+		string(models.PlacementStatusHigherStudies),
+		string(models.PlacementStatusEntrepreneur),
+	}
+
+	yearRX = regexp.MustCompile(`^[0-9]{4}$`)
+)
+
+// Bounds shared by several sections
+const (
+	minSchoolYear = 1980
+	maxCGPA       = 10
+	maxPercentage = 100
+	maxPackageLPA = 1000
+)
+
+// ============================================
+// STUDENT PROFILE SECTIONS
+// ============================================
+
+// validateStudentProfile checks PUT /student/profile
+func validateStudentProfile(v *validator.Validator, input *studentProfileInput) {
+	v.MaxLength("name", &input.Name, 255)
+	v.MaxLength("roll_no", input.RollNo, 20)
+	v.MaxLength("register_no", input.RegisterNo, 20)
+	if input.BatchID != nil {
+		v.Check(*input.BatchID > 0, "batch_id", "must be a positive integer")
+	}
+	if !validator.Blank(input.PhotoURL) {
+		v.Check(validator.IsURL(*input.PhotoURL) || isUploadedPhotoPath(*input.PhotoURL), "photo_url",
+			"must be a valid http or https URL or an uploaded photo path")
+	}
+}
+
+// isUploadedPhotoPath reports whether path points into our own photo uploads.
+// Protocol-relative URLs such as //evil.example/x.png start with a slash too
+// but load from another host, so they are rejected.
+func isUploadedPhotoPath(path string) bool {
+	return strings.HasPrefix(path, uploadedPhotoPrefix) && !strings.HasPrefix(path, "//") && !strings.Contains(path, "..")
+}
+
+// validatePersonalDetails checks the contact, identity and address fields
+func validatePersonalDetails(v *validator.Validator, input *personalDetailsInput) {
+	v.MaxLength("name", &input.Name, 255)
+	v.MaxLength("roll_no", input.RollNo, 20)
+	v.Matches("batch_year", input.BatchYear, yearRX, "must be a four digit year")
+
+	v.Date("date_of_birth", input.DateOfBirth)
+	if dob, err := time.Parse(time.DateOnly, ptrToString(input.DateOfBirth)); err == nil {
+		v.Check(dob.Before(time.Now()), "date_of_birth", "must be in the past")
+	}
+	v.OneOf("gender", input.Gender, genders...)
+	v.OneOf("blood_group", input.BloodGroup, bloodGroups...)
+	v.OneOf("residence_type", input.ResidenceType, residenceTypes...)
+
+	v.Matches("mobile_number", input.MobileNumber, validator.MobileRX, "must be a 10 digit mobile number")
+	v.Matches("alt_mobile_number", input.AlternateMobile, validator.MobileRX, "must be a 10 digit mobile number")
+	if !validator.Blank(input.MobileNumber) && !validator.Blank(input.AlternateMobile) {
+		v.Check(*input.MobileNumber != *input.AlternateMobile, "alt_mobile_number", "must differ from mobile_number")
+	}
+	v.Email("personal_email", input.PersonalEmail)
+	v.URL("linkedin_url", input.LinkedinURL)
+	v.URL("github_url", input.GithubURL)
+	v.URL("portfolio_url", input.PortfolioURL)
+	v.Matches("aadhaar_no", input.AadhaarNumber, validator.AadhaarRX, "must be a 12 digit Aadhaar number")
+
+	v.MaxLength("city", input.City, 100)
+	v.MaxLength("state", input.State, 100)
+	v.Matches("pincode", input.Pincode, validator.PincodeRX, "must be a 6 digit pincode")
+}
+
+// validateFamilyDetails checks parent and guardian contacts
+func validateFamilyDetails(v *validator.Validator, input *familyDetailsInput) {
+	for key, value := range map[string]*string{
+		"father_name":            input.FatherName,
+		"father_occupation":      input.FatherOccupation,
+		"father_company_details": input.FatherCompany,
+		"mother_name":            input.MotherName,
+		"mother_occupation":      input.MotherOccupation,
+		"mother_company":         input.MotherCompany,
+		"guardian_name":          input.GuardianName,
+	} {
+		v.MaxLength(key, value, 255)
+	}
+	for key, value := range map[string]*string{
+		"father_mobile":   input.FatherMobile,
+		"mother_mobile":   input.MotherMobile,
+		"guardian_mobile": input.GuardianMobile,
+	} {
+		v.Matches(key, value, validator.MobileRX, "must be a 10 digit mobile number")
+	}
+	v.Email("father_email", input.FatherEmail)
+	v.Email("mother_email", input.MotherEmail)
+	v.MaxLength("annual_income", input.FatherAnnualIncome, 50)
+	v.MaxLength("guardian_relation", input.GuardianRelation, 50)
+	v.OneOf("residence_type", input.ResidenceType, residenceTypes...)
+
+	if !validator.Blank(input.GuardianMobile) || !validator.Blank(input.GuardianRelation) {
+		v.Check(!validator.Blank(input.GuardianName), "guardian_name", "must be provided with guardian contact details")
+	}
+}
+
+// validateAcademics checks marks, CGPA and the flags that need a companion field
+func validateAcademics(v *validator.Validator, input *academicsInput) {
+	maxYear := time.Now().Year() + 1
+
+	v.FloatRange("tenth_percentage", input.TenthPercentage, 0, maxPercentage)
+	v.MaxLength("tenth_board", input.TenthBoard, 100)
+	v.IntRange("tenth_year", input.TenthYear, minSchoolYear, maxYear)
+	v.MaxLength("tenth_school", input.TenthSchool, 255)
+
+	v.FloatRange("twelfth_percentage", input.TwelfthPercentage, 0, maxPercentage)
+	v.MaxLength("twelfth_board", input.TwelfthBoard, 100)
+	v.IntRange("twelfth_year", input.TwelfthYear, minSchoolYear, maxYear)
+	v.MaxLength("twelfth_school", input.TwelfthSchool, 255)
+	if input.TenthYear != nil && input.TwelfthYear != nil {
+		v.Check(*input.TwelfthYear > *input.TenthYear, "twelfth_year", "must be after tenth_year")
+	}
+
+	v.FloatRange("diploma_percentage", input.DiplomaPercentage, 0, maxPercentage)
+	v.MaxLength("diploma_branch", input.DiplomaBranch, 100)
+	v.MaxLength("diploma_college", input.DiplomaCollege, 255)
+	if input.HasDiploma {
+		v.Check(input.DiplomaPercentage != nil, "diploma_percentage", "must be provided when has_diploma is true")
+	}
+
+	for i, cgpa := range []*float64{
+		input.CGPASem1, input.CGPASem2, input.CGPASem3, input.CGPASem4,
+		input.CGPASem5, input.CGPASem6, input.CGPASem7, input.CGPASem8,
+	} {
+		v.FloatRange(fmt.Sprintf("cgpa_sem%d", i+1), cgpa, 0, maxCGPA)
+	}
+	v.FloatRange("cgpa_overall", input.CGPAOverall, 0, maxCGPA)
+
+	v.IntRange("current_backlogs", &input.CurrentBacklogs, 0, 100)
+	if input.CurrentBacklogs > 0 {
+		v.Check(input.HistoryOfBacklogs, "has_backlog_history", "must be true when current_backlogs is above zero")
+	}
+	if input.HasGapYear {
+		v.Required("gap_year_reason", input.GapYearReason)
+	}
+}
+
+// validateAchievements checks the competitive programming and hackathon counts
+func validateAchievements(v *validator.Validator, input *achievementsInput) {
+	v.IntRange("leetcode_rating", input.LeetcodeRating, 0, 5000)
+	v.IntRange("problems_solved", input.ProblemsSolved, 0, 100000)
+	v.IntRange("hackathons_participated", &input.HackathonsParticipated, 0, 1000)
+	v.IntRange("hackathons_won", &input.HackathonsWon, 0, 1000)
+	v.Check(input.HackathonsWon <= input.HackathonsParticipated, "hackathons_won", "must not exceed hackathons_participated")
+}
+
+// validateAspirations checks the expected package, in LPA
+func validateAspirations(v *validator.Validator, input *aspirationsInput) {
+	v.FloatRange("expected_package", input.ExpectedPackage, 0, maxPackageLPA)
+}
+
+// validateSkills checks each listed skill, keyed by its index in the array
+func validateSkills(v *validator.Validator, input *skillsInput) {
+	seen := make(map[int]bool, len(input.Skills))
+	for i, s := range input.Skills {
+		key := fmt.Sprintf("skills[%d]", i)
+		v.Check(s.SkillID > 0, key+".skill_id", "must be a positive integer")
+		v.Check(!seen[s.SkillID], key+".skill_id", "is listed more than once")
+		v.IntRange(key+".proficiency_level", &s.ProficiencyLevel, 1, 5)
+		seen[s.SkillID] = true
+	}
+}
+
+// ============================================
+// ADMIN INPUTS
+// ============================================
+
+// validateStudentDepartment checks PUT /admin/students/{id}/department
+func validateStudentDepartment(v *validator.Validator, input *studentDepartmentInput) {
+	v.Required("department", &input.Department)
+	v.MaxLength("department", &input.Department, 100)
+}
+
+// validateStudentStatus accepts either status field as a placement_status value
+func validateStudentStatus(v *validator.Validator, input *studentStatusInput) {
+	v.OneOf("status", &input.Status, placementStatuses...)
+	v.OneOf("placement_status", &input.PlacementStatus, placementStatuses...)
+}
+
+// validatePlacement checks a placement built from a create or update body
+func validatePlacement(v *validator.Validator, p *models.PlacementRecord) {
+	if p.CompanyID != nil {
+		v.Check(*p.CompanyID > 0, "company_id", "must be a positive integer")
+	} else {
+		v.Required("company_name", &p.CompanyName)
+	}
+	v.MaxLength("company_name", &p.CompanyName, 255)
+	v.MaxLength("job_role", p.JobRole, 255)
+	if p.PackageLPA != nil {
+		v.Check(*p.PackageLPA > 0 && *p.PackageLPA <= maxPackageLPA, "package_lpa", fmt.Sprintf("must be above 0 and at most %d", maxPackageLPA))
+	}
+	v.MaxLength("package_ctc", p.PackageCTC, 100)
+	v.Date("joining_date", p.JoiningDate)
+	v.Date("offer_date", p.OfferDate)
+	joining, joinErr := time.Parse(time.DateOnly, ptrToString(p.JoiningDate))
+	offer, offerErr := time.Parse(time.DateOnly, ptrToString(p.OfferDate))
+	if joinErr == nil && offerErr == nil {
+		v.Check(!joining.Before(offer), "joining_date", "must not be before offer_date")
+	}
+	v.MaxLength("offer_type", p.OfferType, 50)
+	v.MaxLength("job_location", p.JobLocation, 255)
+}
+
+// validateCompany checks a company as it will be stored
+func validateCompany(v *validator.Validator, c *models.Company) {
+	v.Required("name", &c.Name)
+	v.MaxLength("name", &c.Name, 255)
+	v.URL("website", c.Website)
+	v.URL("logo_url", c.LogoURL)
+	v.MaxLength("industry", c.Industry, 100)
+	v.MaxLength("company_type", c.CompanyType, 50)
+	v.MaxLength("hr_name", c.HRName, 255)
+	v.Email("hr_email", c.HREmail)
+	v.Matches("hr_phone", c.HRPhone, validator.PhoneRX, "must be a valid phone number")
+	v.MaxLength("headquarters", c.Headquarters, 255)
+}
+
+// ============================================
+// ADMIN ACCOUNTS AND API KEYS
+// ============================================
+
+// validateInviteAdmin checks POST /admin/admins once defaults are applied
+func validateInviteAdmin(v *validator.Validator, input *inviteAdminInput) {
+	v.Required("name", &input.Name)
+	v.MaxLength("name", &input.Name, 255)
+	v.Required("email", &input.Email)
+	v.Email("email", &input.Email)
+	v.Matches("phone", input.Phone, validator.PhoneRX, "must be a valid phone number")
+	v.MaxLength("designation", &input.Designation, 100)
+	v.MaxLength("department", input.Department, 100)
+	validateAdminRole(v, input.Role, input.Department)
+}
+
+// validateUpdateAdmin checks the fields present in PUT /admin/admins/{id}
+func validateUpdateAdmin(v *validator.Validator, input *updateAdminInput) {
+	if input.Name != nil {
+		v.Required("name", input.Name)
+	}
+	v.MaxLength("name", input.Name, 255)
+	v.Matches("phone", input.Phone, validator.PhoneRX, "must be a valid phone number")
+	v.MaxLength("designation", input.Designation, 100)
+	v.MaxLength("department", input.Department, 100)
+}
+
+// validateAdminRole checks the role is known and department coordinators have a department
+func validateAdminRole(v *validator.Validator, role string, department *string) {
+	roles := make([]string, 0, len(auth.AdminRoles()))
+	for _, r := range auth.AdminRoles() {
+		roles = append(roles, string(r))
+	}
+	v.OneOf("role", &role, roles...)
+	v.Check(role != "", "role", "must be provided")
+	if auth.AdminRole(role).IsDepartmentScoped() {
+		v.Check(!validator.Blank(department), "department", "must be provided for department coordinators")
+	}
+}
+
+// validateAPIKey checks POST /admin/api-keys
+func validateAPIKey(v *validator.Validator, input *createAPIKeyInput) {
+	v.Required("name", &input.Name)
+	v.MaxLength("name", &input.Name, 100)
+
+	scopes := make([]string, 0, len(auth.APIKeyScopes()))
+	for _, scope := range auth.APIKeyScopes() {
+		scopes = append(scopes, string(scope))
+	}
+	v.Check(len(input.Scopes) > 0, "scopes", "must list at least one scope")
+	for i, scope := range input.Scopes {
+		v.Check(auth.ValidAPIKeyScope(auth.Permission(scope)), fmt.Sprintf("scopes[%d]", i), "must be one of "+strings.Join(scopes, ", "))
+	}
+
+	v.IntRange("rate_limit_per_minute", input.RateLimitPerMinute, 1, maxAPIKeyRateLimit)
+	if input.ExpiresAt != nil {
+		v.Check(input.ExpiresAt.After(time.Now()), "expires_at", "must be in the future")
+	}
+}
+
+// validateAcademicSyncBatch checks the size of POST /admin/academics/sync;
+// each record is checked on its own by validateAcademicSync
+func validateAcademicSyncBatch(v *validator.Validator, input *academicSyncInput) {
+	v.Check(len(input.Records) > 0, "records", "must not be empty")
+	v.Check(len(input.Records) <= maxSyncRecords, "records", fmt.Sprintf("must not contain more than %d records", maxSyncRecords))
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/validator"
+)
+
+func TestValidateAcademics(t *testing.T) {
+	cgpa, tenth := 10.5, 101.0
+	input := academicsInput{
+		TenthPercentage: &tenth,
+		CGPAOverall:     &cgpa,
+		CurrentBacklogs: -1,
+		HasDiploma:      true,
+		HasGapYear:      true,
+	}
+
+	v := validator.New()
+	validateAcademics(v, &input)
+
+	for _, key := range []string{"tenth_percentage", "cgpa_overall", "current_backlogs", "diploma_percentage", "gap_year_reason"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("%s: expected an error, got %v", key, v.Errors)
+		}
+	}
+
+	// An empty section is a valid partial save
+	v = validator.New()
+	validateAcademics(v, &academicsInput{})
+	if !v.Valid() {
+		t.Errorf("empty section was rejected: %v", v.Errors)
+	}
+}
+
+func TestValidateStudentProfilePhotoURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://cdn.kct.ac.in/photos/1.jpg": true,
+		"/uploads/photos/1_1700000000.jpg":   true,
+		"//evil.example/x.png":               false,
+		"/uploads/photos/../../etc/passwd":   false,
+		"/somewhere/else.png":                false,
+		"javascript:alert(1)":                false,
+	}
+	for url, ok := range tests {
+		v := validator.New()
+		validateStudentProfile(v, &studentProfileInput{PhotoURL: &url})
+		if _, failed := v.Errors["photo_url"]; failed == ok {
+			t.Errorf("%s: accepted %v, want %v", url, !failed, ok)
+		}
+	}
+}
+
+func TestValidatePersonalDetails(t *testing.T) {
+	gender, mobile, pincode, blank := "unknown", "12345", "6000", ""
+	input := personalDetailsInput{
+		Gender:        &gender,
+		MobileNumber:  &mobile,
+		Pincode:       &pincode,
+		ResidenceType: &blank, // forms send "" for an untouched select
+	}
+
+	v := validator.New()
+	validatePersonalDetails(v, &input)
+
+	for _, key := range []string{"gender", "mobile_number", "pincode"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("%s: expected an error, got %v", key, v.Errors)
+		}
+	}
+	if _, ok := v.Errors["residence_type"]; ok {
+		t.Error("blank residence_type was rejected")
+	}
+}
+
+func TestValidatePlacement(t *testing.T) {
+	offer, joining := "2026-03-01", "2026-01-15"
+	v := validator.New()
+	validatePlacement(v, &models.PlacementRecord{OfferDate: &offer, JoiningDate: &joining})
+
+	if _, ok := v.Errors["company_name"]; !ok {
+		t.Errorf("placement without a company was accepted: %v", v.Errors)
+	}
+	if _, ok := v.Errors["joining_date"]; !ok {
+		t.Errorf("joining before the offer was accepted: %v", v.Errors)
+	}
+}
+
+func TestValidateInviteAdmin(t *testing.T) {
+	input := inviteAdminInput{Email: "priya.kct.ac.in", Role: "department_coordinator"}
+
+	v := validator.New()
+	validateInviteAdmin(v, &input)
+
+	for _, key := range []string{"name", "email", "department"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("%s: expected an error, got %v", key, v.Errors)
+		}
+	}
+
+	v = validator.New()
+	validateInviteAdmin(v, &inviteAdminInput{Name: "Priya", Email: "priya@kct.ac.in", Role: "janitor"})
+	if _, ok := v.Errors["role"]; !ok || len(v.Errors) != 1 {
+		t.Errorf("unknown role: got %v", v.Errors)
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	limit, past := 0, time.Now().Add(-time.Hour)
+	input := createAPIKeyInput{
+		Scopes:             []string{"students:read", "admins:manage"},
+		RateLimitPerMinute: &limit,
+		ExpiresAt:          &past,
+	}
+
+	v := validator.New()
+	validateAPIKey(v, &input)
+
+	for _, key := range []string{"name", "scopes[1]", "rate_limit_per_minute", "expires_at"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("%s: expected an error, got %v", key, v.Errors)
+		}
+	}
+	if _, ok := v.Errors["scopes[0]"]; ok {
+		t.Error("a valid scope was rejected")
+	}
+
+	v = validator.New()
+	validateAPIKey(v, &createAPIKeyInput{Name: "ERP"})
+	if _, ok := v.Errors["scopes"]; !ok {
+		t.Errorf("no scopes: got %v", v.Errors)
+	}
+}
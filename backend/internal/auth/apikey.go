@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// RoleService is the Claims.Role of requests authenticated with an API key
+	RoleService = "service"
+
+	apiKeyPrefix = "pps_"
+)
+
+// GenerateAPIKey returns a new key, its display prefix and the hash to store.
+// Keys look like pps_<8 hex chars>_<secret>; the prefix is pps_<8 hex chars>.
+func GenerateAPIKey() (key, prefix string, hash []byte, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", nil, err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey hashes an API key for storage and lookup
+func HashAPIKey(key string) []byte {
+	hash := sha256.Sum256([]byte(key))
+	return hash[:]
+}
+
+// LooksLikeAPIKey reports whether a credential has the API key format
+func LooksLikeAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyPrefix)
+}
+
+// APIKeyScopes lists the permissions that can be granted to an API key.
+// Managing admins (and therefore API keys) always needs a human.
+func APIKeyScopes() []Permission {
+	return []Permission{
+		PermStudentsRead, PermStudentsWrite, PermAcademicsWrite,
+		PermPlacementsRead, PermPlacementsWrite,
+		PermCompaniesRead, PermCompaniesWrite,
+		PermAnalyticsRead,
+	}
+}
+
+// ValidAPIKeyScope reports whether p may be granted to an API key
+func ValidAPIKeyScope(p Permission) bool {
+	for _, scope := range APIKeyScopes() {
+		if scope == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, prefix+"_") || !LooksLikeAPIKey(key) {
+		t.Errorf("key %q does not start with prefix %q", key, prefix)
+	}
+	if !bytes.Equal(hash, HashAPIKey(key)) {
+		t.Error("returned hash does not match HashAPIKey")
+	}
+
+	other, _, _, err := GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Error("two generated keys are identical")
+	}
+}
+
+func TestServiceClaimsCan(t *testing.T) {
+	claims := &Claims{Role: RoleService, Scopes: []Permission{PermStudentsRead, PermAcademicsWrite}}
+
+	if !claims.Can(PermAcademicsWrite) {
+		t.Error("scoped permission was denied")
+	}
+	if claims.Can(PermStudentsWrite) {
+		t.Error("unscoped permission was granted")
+	}
+
+	// Scopes only count for service accounts
+	student := &Claims{Role: "student", Scopes: []Permission{PermStudentsRead}}
+	if student.Can(PermStudentsRead) {
+		t.Error("student was granted an admin permission")
+	}
+}
+
+func TestAPIKeyScopesExcludeAdminManagement(t *testing.T) {
+	if ValidAPIKeyScope(PermAdminsManage) {
+		t.Error("admins:manage must not be grantable to API keys")
+	}
+	if ValidAPIKeyScope("students:delete") {
+		t.Error("unknown scope accepted")
+	}
+}
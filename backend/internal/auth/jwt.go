@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,58 +16,224 @@ var (
 	ErrInvalidToken = errors.New("invalid token")
 )
 
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// AuthCodeTTL is the default for how long the frontend has to exchange a login code
+	AuthCodeTTL = time.Minute
+	// ImpersonationTokenTTL is the default for how long an admin can view the app as a student
+	ImpersonationTokenTTL = 10 * time.Minute
+)
+
+// JWTService issues and validates access tokens. With a key set it signs with
+// the active asymmetric key and publishes the public keys as a JWKS; otherwise
+// it falls back to HS256 with Secret. While switching over, HS256 tokens
+// issued before the switch verify until LegacyUntil, and never after it.
 type JWTService struct {
-	Secret []byte
+	Secret                []byte
+	Keys                  *KeySet
+	LegacyUntil           time.Time
+	Issuer                string
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	ImpersonationTokenTTL time.Duration
 }
 
 func NewJWTService(secret string) *JWTService {
 	return &JWTService{
-		Secret: []byte(secret),
+		Secret:                []byte(secret),
+		AccessTokenTTL:        DefaultAccessTokenTTL,
+		RefreshTokenTTL:       DefaultRefreshTokenTTL,
+		ImpersonationTokenTTL: ImpersonationTokenTTL,
 	}
 }
 
+// NewAsymmetricJWTService signs with keys.Active. HS256 tokens signed with
+// legacySecret verify until legacyUntil; pass "" and the zero time to refuse them.
+func NewAsymmetricJWTService(keys *KeySet, issuer, legacySecret string, legacyUntil time.Time) *JWTService {
+	j := NewJWTService(legacySecret)
+	j.Keys = keys
+	j.LegacyUntil = legacyUntil
+	j.Issuer = issuer
+	return j
+}
+
 type Claims struct {
-	UserID int64  `json:"student_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID     int64     `json:"student_id"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role"`
+	AdminRole  AdminRole `json:"admin_role,omitempty"`
+	Department string    `json:"department,omitempty"`
+	// InstitutionID is the institution the user belongs to
+	InstitutionID int64 `json:"inst,omitempty"`
+	SessionID     int64 `json:"sid,omitempty"`
+	// Actor names the admin behind an impersonation token (RFC 8693 "act")
+	Actor *Actor `json:"act,omitempty"`
+	// Scopes is only set for API key requests and is never put in a JWT
+	Scopes []Permission `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func (j *JWTService) GenerateToken(studentID int64, email string, role string) (string, error) {
-	claims := Claims{
-		UserID: studentID,
-		Email:  email,
-		Role:   role,
+// Actor identifies the admin acting as another user
+type Actor struct {
+	Subject string `json:"sub"`
+	AdminID int64  `json:"admin_id"`
+	Email   string `json:"email"`
+}
+
+// IsImpersonation reports whether the token was minted for an admin viewing
+// the app as someone else. Such tokens are read-only.
+func (c *Claims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// TokenUser describes the user an access token is issued to
+type TokenUser struct {
+	ID         int64
+	Email      string
+	Role       string // student or admin
+	AdminRole  AdminRole
+	Department string
+	// InstitutionID is the institution the user belongs to
+	InstitutionID int64
+}
+
+// Can reports whether the token holder has an admin permission, either
+// through their admin role or an API key scope
+func (c *Claims) Can(p Permission) bool {
+	switch c.Role {
+	case "admin":
+		return c.AdminRole.Can(p)
+	case RoleService:
+		return slices.Contains(c.Scopes, p)
+	}
+	return false
+}
+
+// GenerateToken issues a short-lived access token bound to a session
+func (j *JWTService) GenerateToken(user TokenUser, sessionID int64) (string, error) {
+	return j.sign(j.newClaims(user, sessionID, j.AccessTokenTTL))
+}
+
+// GenerateImpersonationToken issues a token that lets actor see the app as
+// user. It is bound to the admin's own session, so signing out ends it too.
+func (j *JWTService) GenerateImpersonationToken(user TokenUser, actor Actor, adminSessionID int64) (string, error) {
+	claims := j.newClaims(user, adminSessionID, j.ImpersonationTokenTTL)
+	claims.Actor = &actor
+	return j.sign(claims)
+}
+
+func (j *JWTService) newClaims(user TokenUser, sessionID int64, ttl time.Duration) Claims {
+	return Claims{
+		UserID:        user.ID,
+		Email:         user.Email,
+		Role:          user.Role,
+		AdminRole:     user.AdminRole,
+		Department:    user.Department,
+		InstitutionID: user.InstitutionID,
+		SessionID:     sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			Issuer:    j.Issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.Secret)
-	if err != nil {
-		return "", err
+// sign signs claims with the active key, or the HMAC secret without a key set
+func (j *JWTService) sign(claims Claims) (string, error) {
+	if j.Keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(j.Secret)
 	}
 
-	return tokenString, nil
+	active := j.Keys.Active
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.Algorithm), claims)
+	token.Header["kid"] = active.ID
+
+	return token.SignedString(active.Private)
 }
 
+// ValidateToken verifies a token's signature and expiry and, when an issuer
+// is configured, that the key set signed it for this service
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey,
+		jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	// Other services and environments may share the key set; only HS256
+	// tokens, which predate it and never carried an issuer, are exempt
+	if _, legacy := token.Method.(*jwt.SigningMethodHMAC); !legacy && j.Issuer != "" {
+		if claims.Issuer != j.Issuer {
+			return nil, fmt.Errorf("%w: issued by %q", ErrInvalidToken, claims.Issuer)
+		}
+	}
+
+	return claims, nil
+}
+
+// verificationKey picks the key for a token: the HMAC secret for HS256, or
+// the key set entry named by kid, whose algorithm must match the header.
+// Once a key set is configured, HS256 is only accepted until LegacyUntil.
+func (j *JWTService) verificationKey(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if len(j.Secret) == 0 || (j.Keys != nil && !time.Now().Before(j.LegacyUntil)) {
 			return nil, ErrInvalidToken
 		}
 		return j.Secret, nil
-	})
+	}
 
-	if err != nil {
-		return nil, err
+	if j.Keys == nil {
+		return nil, ErrInvalidToken
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.Keys.Key(kid)
+	if !ok || key.Algorithm != token.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.Public, nil
+}
+
+// JWKS returns the public verification keys; empty when signing with HS256
+func (j *JWTService) JWKS() JSONWebKeySet {
+	if j.Keys == nil {
+		return JSONWebKeySet{Keys: []JSONWebKey{}}
 	}
+	return j.Keys.JWKS()
+}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
+// GenerateRefreshToken returns an opaque refresh token and the hash to store for it
+func (j *JWTService) GenerateRefreshToken() (string, []byte, error) {
+	return opaqueToken()
+}
+
+// GenerateAuthCode returns a one-time login code and the hash to store for it
+func GenerateAuthCode() (string, []byte, error) {
+	return opaqueToken()
+}
+
+func opaqueToken() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
 	}
 
-	return nil, ErrInvalidToken
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken hashes a refresh token or auth code for storage and lookup
+func HashRefreshToken(token string) []byte {
+	hash := sha256.Sum256([]byte(token))
+	return hash[:]
 }
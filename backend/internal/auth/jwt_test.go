@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImpersonationToken(t *testing.T) {
+	service := NewJWTService("test-secret")
+
+	student := TokenUser{ID: 42, Email: "student@kct.ac.in", Role: "student"}
+	actor := Actor{Subject: "admin:7", AdminID: 7, Email: "officer@kct.ac.in"}
+
+	token, err := service.GenerateImpersonationToken(student, actor, 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !claims.IsImpersonation() || *claims.Actor != actor {
+		t.Errorf("got actor %+v, want %+v", claims.Actor, actor)
+	}
+	if claims.UserID != 42 || claims.Role != "student" || claims.SessionID != 99 {
+		t.Errorf("got claims %+v", claims)
+	}
+
+	ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time)
+	if ttl != ImpersonationTokenTTL {
+		t.Errorf("got lifetime %v, want %v", ttl, ImpersonationTokenTTL)
+	}
+
+	// Regular tokens carry no actor
+	regular, err := service.GenerateToken(student, 99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err = service.ValidateToken(regular)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.IsImpersonation() {
+		t.Error("regular token was marked as impersonation")
+	}
+	if ttl := claims.ExpiresAt.Sub(claims.IssuedAt.Time); ttl > DefaultAccessTokenTTL+time.Second {
+		t.Errorf("regular token lifetime %v", ttl)
+	}
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoActiveKey = errors.New("active signing key not found or has no private key")
+)
+
+// SigningKey is one entry of the token key set. Retired keys can be kept with
+// only their public half so tokens they signed still verify during rotation.
+type SigningKey struct {
+	ID        string
+	Algorithm string // RS256 or EdDSA
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// KeySet holds every key we accept and the one we currently sign with
+type KeySet struct {
+	Active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// LoadKeySet reads every .pem file in dir. The file name (without .pem or
+// .pub.pem) is the key ID. Files may hold a PKCS#8 private key or a PKIX
+// public key; activeKID must name a private key.
+func LoadKeySet(dir, activeKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{keys: map[string]*SigningKey{}}
+	for _, path := range paths {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseSigningKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		// A private key file wins over a public one with the same ID
+		if existing, ok := set.keys[kid]; ok && existing.Private != nil {
+			continue
+		}
+		set.keys[kid] = key
+	}
+
+	active, ok := set.keys[activeKID]
+	if !ok || active.Private == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoActiveKey, activeKID)
+	}
+	set.Active = active
+
+	return set, nil
+}
+
+// Key returns the verification key for kid
+func (s *KeySet) Key(kid string) (*SigningKey, bool) {
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// JWKS returns the public half of every key, for /.well-known/jwks.json
+func (s *KeySet) JWKS() JSONWebKeySet {
+	ids := make([]string, 0, len(s.keys))
+	for kid := range s.keys {
+		ids = append(ids, kid)
+	}
+	sort.Strings(ids)
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, kid := range ids {
+		key := s.keys[kid]
+		jwk := JSONWebKey{KeyID: kid, Use: "sig", Algorithm: key.Algorithm}
+
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func parseSigningKey(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &SigningKey{ID: kid}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		key.Private = signer
+		key.Public = signer.Public()
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = parsed
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q, expected PKCS#8 \"PRIVATE KEY\" or \"PUBLIC KEY\"", block.Type)
+	}
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.Algorithm = "RS256"
+	case ed25519.PublicKey:
+		key.Algorithm = "EdDSA"
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	return key, nil
+}
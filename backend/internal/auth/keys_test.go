@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePrivateKey(t *testing.T, dir, name string, key any) {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, name, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, name string, key any) {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, dir, name, "PUBLIC KEY", der)
+}
+
+var testUser = TokenUser{ID: 7, Email: "student@kct.ac.in", Role: "student"}
+
+func TestAsymmetricSigningWithRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "2026-01.pem", oldKey)
+
+	keys, err := LoadKeySet(dir, "2026-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldService := NewAsymmetricJWTService(keys, "placement-api", "", time.Time{})
+
+	oldToken, err := oldService.GenerateToken(testUser, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotate: a new RSA key becomes active and the old key is kept public-only
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(dir, "2026-01.pem"))
+	writePublicKey(t, dir, "2026-01.pub.pem", oldKey.Public())
+	writePrivateKey(t, dir, "2026-07.pem", newKey)
+
+	keys, err = LoadKeySet(dir, "2026-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewAsymmetricJWTService(keys, "placement-api", "", time.Time{})
+
+	newToken, err := service.GenerateToken(testUser, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{"old key": oldToken, "new key": newToken} {
+		claims, err := service.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if claims.UserID != testUser.ID || claims.Issuer != "placement-api" {
+			t.Errorf("%s: unexpected claims %+v", name, claims)
+		}
+	}
+
+	// Another service or environment signing with the same keys is refused
+	otherToken, err := NewAsymmetricJWTService(keys, "placement-api-staging", "", time.Time{}).GenerateToken(testUser, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateToken(otherToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token for another issuer: got %v, want ErrInvalidToken", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "2026-07" || parsed.Method.Alg() != "RS256" {
+		t.Errorf("unexpected header %v", parsed.Header)
+	}
+
+	// A retired key can't become the active one without its private half
+	if _, err := LoadKeySet(dir, "2026-01"); !errors.Is(err, ErrNoActiveKey) {
+		t.Errorf("got error %v, want ErrNoActiveKey", err)
+	}
+}
+
+func TestJWKSRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "ed.pem", edKey)
+	writePrivateKey(t, dir, "rsa.pem", rsaKey)
+
+	keys, err := LoadKeySet(dir, "ed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := keys.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("got %d keys, want 2", len(set.Keys))
+	}
+
+	for _, jwk := range set.Keys {
+		key, _ := keys.Key(jwk.KeyID)
+		got, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: %v", jwk.KeyID, err)
+		}
+		if !reflect.DeepEqual(got, key.Public) {
+			t.Errorf("%s: JWKS key does not match the loaded key", jwk.KeyID)
+		}
+		if jwk.Algorithm != key.Algorithm {
+			t.Errorf("%s: got alg %q, want %q", jwk.KeyID, jwk.Algorithm, key.Algorithm)
+		}
+	}
+}
+
+func TestValidateTokenRejectsUntrustedAlgorithms(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePrivateKey(t, dir, "k1.pem", rsaKey)
+
+	keys, err := LoadKeySet(dir, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewAsymmetricJWTService(keys, "", "", time.Time{})
+
+	claims := Claims{
+		UserID: 1,
+		Role:   "admin",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	// HS256 signed with the public key, the classic algorithm-confusion attack
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmacToken.Header["kid"] = "k1"
+	forged, err := hmacToken.SignedString(publicDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateToken(forged); err == nil {
+		t.Error("HS256 token accepted without a legacy secret")
+	}
+
+	// Unsigned tokens
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateToken(none); err == nil {
+		t.Error("unsigned token accepted")
+	}
+
+	// Legacy HS256 tokens verify while the old secret is still configured and
+	// its deadline has not passed
+	legacy := NewJWTService("legacy-secret")
+	legacyToken, err := legacy.GenerateToken(testUser, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewAsymmetricJWTService(keys, "placement-api", "legacy-secret", time.Now().Add(time.Minute)).ValidateToken(legacyToken); err != nil {
+		t.Errorf("legacy token rejected: %v", err)
+	}
+
+	// ...and not once the deadline passes, or without one
+	for _, until := range []time.Time{time.Now().Add(-time.Second), {}} {
+		if _, err := NewAsymmetricJWTService(keys, "", "legacy-secret", until).ValidateToken(legacyToken); err == nil {
+			t.Errorf("legacy token accepted with deadline %v", until)
+		}
+	}
+}
@@ -2,73 +2,55 @@ package auth
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
-	"io"
-	"net/http"
+	"fmt"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/microsoft"
 )
 
-var (
-	ErrFailedToGetUserInfo = errors.New("failed to get user info from Microsoft")
-)
+// MicrosoftProviderName is the provider used by the legacy /auth/login and /auth/callback routes
+const MicrosoftProviderName = "microsoft"
 
 type MicrosoftOAuth struct {
-	Config *oauth2.Config
+	Config   *oauth2.Config
+	Verifier *IDTokenVerifier
+	domains  []string
 }
 
-type MicrosoftUserInfo struct {
-	ID                string `json:"id"`
-	DisplayName       string `json:"displayName"`
-	Mail              string `json:"mail"`
-	UserPrincipalName string `json:"userPrincipalName"`
+// MicrosoftIssuer is the v2.0 issuer Azure AD puts in id_tokens for a tenant
+func MicrosoftIssuer(tenantID string) string {
+	return fmt.Sprintf("https://login.microsoftonline.com/%s/v2.0", tenantID)
 }
 
-func NewMicrosoftOAuth(clientID, clientSecret, redirectURL string) *MicrosoftOAuth {
+// NewMicrosoftOAuth pins both the login endpoints and id_token validation to a single tenant
+func NewMicrosoftOAuth(clientID, clientSecret, tenantID, redirectURL string, allowedDomains []string) *MicrosoftOAuth {
 	return &MicrosoftOAuth{
 		Config: &oauth2.Config{
 			ClientID:     clientID,
 			ClientSecret: clientSecret,
 			RedirectURL:  redirectURL,
-			Scopes:       []string{"openid", "profile", "email", "User.Read"},
-			Endpoint:     microsoft.AzureADEndpoint("common"),
+			Scopes:       []string{"openid", "profile", "email"},
+			Endpoint:     microsoft.AzureADEndpoint(tenantID),
 		},
+		Verifier: NewIDTokenVerifier(MicrosoftIssuer(tenantID), clientID, tenantID),
+		domains:  allowedDomains,
 	}
 }
 
-func (m *MicrosoftOAuth) GetAuthURL(state string) string {
-	return m.Config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+func (m *MicrosoftOAuth) Name() string {
+	return MicrosoftProviderName
 }
 
-func (m *MicrosoftOAuth) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
-	return m.Config.Exchange(ctx, code)
+func (m *MicrosoftOAuth) AllowedDomains() []string {
+	return m.domains
 }
 
-func (m *MicrosoftOAuth) GetUserInfo(ctx context.Context, token *oauth2.Token) (*MicrosoftUserInfo, error) {
-	client := m.Config.Client(ctx, token)
-
-	resp, err := client.Get("https://graph.microsoft.com/v1.0/me")
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, ErrFailedToGetUserInfo
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var userInfo MicrosoftUserInfo
-	err = json.Unmarshal(body, &userInfo)
-	if err != nil {
-		return nil, err
-	}
+// AuthCodeURL builds the authorize URL; nonce is echoed back in the id_token
+func (m *MicrosoftOAuth) AuthCodeURL(_ context.Context, state, nonce string) (string, error) {
+	return m.Config.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
 
-	return &userInfo, nil
+// Authenticate exchanges the code and returns the identity from the verified id_token
+func (m *MicrosoftOAuth) Authenticate(ctx context.Context, code, nonce string) (*Identity, error) {
+	return authenticateIDToken(ctx, MicrosoftProviderName, m.Config, m.Verifier, code, nonce)
 }
@@ -0,0 +1,307 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNonceMismatch    = errors.New("id_token nonce does not match")
+	ErrTenantMismatch   = errors.New("id_token was issued for a different tenant")
+	ErrUnknownKey       = errors.New("id_token signed with an unknown key")
+	ErrEmailNotVerified = errors.New("identity provider reports the email as unverified")
+)
+
+// minKeyRefreshInterval stops a flood of tokens with made-up key IDs from
+// hammering the issuer's JWKS endpoint
+const minKeyRefreshInterval = time.Minute
+
+// IDTokenClaims are the OpenID Connect claims we rely on
+type IDTokenClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     *bool  `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Nonce             string `json:"nonce"`
+	TenantID          string `json:"tid"`
+	ObjectID          string `json:"oid"`
+	jwt.RegisteredClaims
+}
+
+// IDTokenVerifier validates id_tokens against an issuer's discovery document
+// and published signing keys. Discovery happens lazily on first use.
+type IDTokenVerifier struct {
+	Issuer   string
+	ClientID string
+	// TenantID, when set, must match the token's tid claim
+	TenantID   string
+	HTTPClient *http.Client
+
+	mu          sync.RWMutex
+	metadata    *ProviderMetadata
+	keys        map[string]crypto.PublicKey
+	keysFetched time.Time
+}
+
+func NewIDTokenVerifier(issuer, clientID, tenantID string) *IDTokenVerifier {
+	return &IDTokenVerifier{
+		Issuer:     strings.TrimSuffix(issuer, "/"),
+		ClientID:   clientID,
+		TenantID:   tenantID,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// ProviderMetadata is the subset of the OpenID discovery document we use
+type ProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover fetches and caches the issuer's discovery document
+func (v *IDTokenVerifier) Discover(ctx context.Context) (*ProviderMetadata, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.discoverLocked(ctx)
+}
+
+func (v *IDTokenVerifier) discoverLocked(ctx context.Context) (*ProviderMetadata, error) {
+	if v.metadata != nil {
+		return v.metadata, nil
+	}
+
+	var metadata ProviderMetadata
+	if err := v.getJSON(ctx, v.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != v.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", metadata.Issuer, v.Issuer)
+	}
+	if metadata.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: no jwks_uri")
+	}
+
+	v.metadata = &metadata
+	return v.metadata, nil
+}
+
+// Verify checks the token's signature, issuer, audience, expiry, nonce and tenant
+func (v *IDTokenVerifier) Verify(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	claims := &IDTokenClaims{}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(v.Issuer),
+		jwt.WithAudience(v.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	if v.TenantID != "" && !strings.EqualFold(claims.TenantID, v.TenantID) {
+		return nil, ErrTenantMismatch
+	}
+
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	return claims, nil
+}
+
+// EmailAddress returns the user's email, falling back to the UPN for accounts without a mailbox
+func (c *IDTokenClaims) EmailAddress() string {
+	if c.Email != "" {
+		return c.Email
+	}
+	return c.PreferredUsername
+}
+
+// key returns the public key for kid, refetching the JWKS once if the key is
+// unknown so that issuer key rotation is picked up without a restart
+func (v *IDTokenVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	fetched := v.keysFetched
+	v.mu.RUnlock()
+
+	if ok {
+		return key, nil
+	}
+
+	if !fetched.IsZero() && time.Since(fetched) < minKeyRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+
+	if err := v.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	v.mu.RLock()
+	key, ok = v.keys[kid]
+	v.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+func (v *IDTokenVerifier) refreshKeys(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	metadata, err := v.discoverLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set JSONWebKeySet
+	if err := v.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return fmt.Errorf("fetching jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// Skip key types we don't understand rather than failing every login
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	v.keys = keys
+	v.keysFetched = time.Now()
+	return nil
+}
+
+func (v *IDTokenVerifier) getJSON(ctx context.Context, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(dst)
+}
+
+// ============================================
+// JSON WEB KEYS
+// ============================================
+
+// JSONWebKey is a single public key in a JWKS document
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is a JWKS document
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// PublicKey decodes the JWK into a Go public key
+func (k JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+
+	case "OKP":
+		if k.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "test-client"
+	testTenantID = "11111111-2222-3333-4444-555555555555"
+	testNonce    = "test-nonce"
+)
+
+// fakeIssuer is a minimal OpenID provider serving discovery, a JWKS and a token endpoint
+type fakeIssuer struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PrivateKey
+	jwksCalls int
+	// idToken is returned from the token endpoint for any code
+	idToken string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	f := &fakeIssuer{keys: map[string]*rsa.PrivateKey{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 f.server.URL,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.jwksCalls++
+
+		var set JSONWebKeySet
+		for kid, key := range f.keys {
+			set.Keys = append(set.Keys, JSONWebKey{
+				KeyType: "RSA",
+				KeyID:   kid,
+				Use:     "sig",
+				N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     f.idToken,
+		})
+	})
+
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+
+	f.addKey(t, "key-1")
+	return f
+}
+
+func (f *fakeIssuer) addKey(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.mu.Lock()
+	f.keys[kid] = key
+	f.mu.Unlock()
+	return key
+}
+
+func (f *fakeIssuer) sign(t *testing.T, kid string, claims IDTokenClaims) string {
+	t.Helper()
+
+	f.mu.Lock()
+	key := f.keys[kid]
+	f.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func (f *fakeIssuer) validClaims() IDTokenClaims {
+	now := time.Now()
+	return IDTokenClaims{
+		Email:    "student@kct.ac.in",
+		Name:     "Test Student",
+		Nonce:    testNonce,
+		TenantID: testTenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    f.server.URL,
+			Subject:   "subject-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+}
+
+func TestIDTokenVerifier(t *testing.T) {
+	issuer := newFakeIssuer(t)
+
+	tests := []struct {
+		name    string
+		mutate  func(c *IDTokenClaims)
+		nonce   string
+		wantErr error
+	}{
+		{name: "valid token", nonce: testNonce},
+		{
+			name:    "wrong audience",
+			mutate:  func(c *IDTokenClaims) { c.Audience = jwt.ClaimStrings{"someone-else"} },
+			nonce:   testNonce,
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "wrong issuer",
+			mutate:  func(c *IDTokenClaims) { c.Issuer = "https://evil.example.com" },
+			nonce:   testNonce,
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name: "expired",
+			mutate: func(c *IDTokenClaims) {
+				c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+			},
+			nonce:   testNonce,
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "nonce mismatch",
+			nonce:   "another-login",
+			wantErr: ErrNonceMismatch,
+		},
+		{
+			name:    "missing nonce",
+			mutate:  func(c *IDTokenClaims) { c.Nonce = "" },
+			nonce:   "",
+			wantErr: ErrNonceMismatch,
+		},
+		{
+			name:    "other tenant",
+			mutate:  func(c *IDTokenClaims) { c.TenantID = "99999999-0000-0000-0000-000000000000" },
+			nonce:   testNonce,
+			wantErr: ErrTenantMismatch,
+		},
+	}
+
+	verifier := NewIDTokenVerifier(issuer.server.URL, testClientID, testTenantID)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.validClaims()
+			if tt.mutate != nil {
+				tt.mutate(&claims)
+			}
+
+			got, err := verifier.Verify(context.Background(), issuer.sign(t, "key-1", claims), tt.nonce)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.EmailAddress() != "student@kct.ac.in" {
+				t.Errorf("got email %q", got.EmailAddress())
+			}
+		})
+	}
+}
+
+func TestIDTokenVerifierRejectsForeignSignature(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	verifier := NewIDTokenVerifier(issuer.server.URL, testClientID, testTenantID)
+
+	// Same kid, but signed by a key the issuer never published
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, issuer.validClaims())
+	token.Header["kid"] = "key-1"
+	raw, err := token.SignedString(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := verifier.Verify(context.Background(), raw, testNonce); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Fatalf("got error %v, want invalid signature", err)
+	}
+}
+
+func TestIDTokenVerifierPicksUpRotatedKeys(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	verifier := NewIDTokenVerifier(issuer.server.URL, testClientID, testTenantID)
+
+	if _, err := verifier.Verify(context.Background(), issuer.sign(t, "key-1", issuer.validClaims()), testNonce); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A key published after the first fetch is only found once the refresh window has passed
+	issuer.addKey(t, "key-2")
+	rotated := issuer.sign(t, "key-2", issuer.validClaims())
+
+	if _, err := verifier.Verify(context.Background(), rotated, testNonce); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got error %v, want ErrUnknownKey", err)
+	}
+
+	verifier.mu.Lock()
+	verifier.keysFetched = time.Now().Add(-2 * minKeyRefreshInterval)
+	verifier.mu.Unlock()
+
+	if _, err := verifier.Verify(context.Background(), rotated, testNonce); err != nil {
+		t.Fatalf("unexpected error after refresh: %v", err)
+	}
+
+	issuer.mu.Lock()
+	calls := issuer.jwksCalls
+	issuer.mu.Unlock()
+	if calls != 2 {
+		t.Errorf("got %d JWKS fetches, want 2", calls)
+	}
+}
+
+func TestMicrosoftIssuer(t *testing.T) {
+	got := MicrosoftIssuer(testTenantID)
+	want := "https://login.microsoftonline.com/" + testTenantID + "/v2.0"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
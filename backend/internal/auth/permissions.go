@@ -0,0 +1,94 @@
+package auth
+
+// Permission is a single capability checked by admin routes
+type Permission string
+
+const (
+	PermStudentsRead    Permission = "students:read"
+	PermStudentsWrite   Permission = "students:write"
+	PermAcademicsWrite  Permission = "academics:write"
+	PermPlacementsRead  Permission = "placements:read"
+	PermPlacementsWrite Permission = "placements:write"
+	PermCompaniesRead   Permission = "companies:read"
+	PermCompaniesWrite  Permission = "companies:write"
+	PermAnalyticsRead   Permission = "analytics:read"
+	PermAdminsManage    Permission = "admins:manage"
+	// PermStudentsImpersonate allows viewing the app as a student, read-only
+	PermStudentsImpersonate Permission = "students:impersonate"
+	// PermInstitutionsAll allows managing institutions and working across all of them
+	PermInstitutionsAll Permission = "institutions:all"
+	// PermJobsManage allows inspecting and retrying background jobs
+	PermJobsManage Permission = "jobs:manage"
+)
+
+// AdminRole is the role stored on an admin account
+type AdminRole string
+
+const (
+	RoleGroupAdmin            AdminRole = "group_admin"
+	RoleSuperAdmin            AdminRole = "super_admin"
+	RolePlacementCoordinator  AdminRole = "placement_coordinator"
+	RoleDepartmentCoordinator AdminRole = "department_coordinator"
+	RoleFacultyViewer         AdminRole = "faculty_viewer"
+)
+
+var rolePermissions = map[AdminRole][]Permission{
+	RoleGroupAdmin: {
+		PermStudentsRead, PermStudentsWrite, PermAcademicsWrite, PermStudentsImpersonate,
+		PermPlacementsRead, PermPlacementsWrite,
+		PermCompaniesRead, PermCompaniesWrite,
+		PermAnalyticsRead, PermAdminsManage, PermInstitutionsAll, PermJobsManage,
+	},
+	RoleSuperAdmin: {
+		PermStudentsRead, PermStudentsWrite, PermAcademicsWrite, PermStudentsImpersonate,
+		PermPlacementsRead, PermPlacementsWrite,
+		PermCompaniesRead, PermCompaniesWrite,
+		PermAnalyticsRead, PermAdminsManage, PermJobsManage,
+	},
+	RolePlacementCoordinator: {
+		PermStudentsRead, PermStudentsWrite, PermAcademicsWrite, PermStudentsImpersonate,
+		PermPlacementsRead, PermPlacementsWrite,
+		PermCompaniesRead, PermCompaniesWrite,
+		PermAnalyticsRead,
+	},
+	RoleDepartmentCoordinator: {
+		PermStudentsRead, PermStudentsWrite, PermStudentsImpersonate,
+		PermPlacementsRead, PermCompaniesRead,
+		PermAnalyticsRead,
+	},
+	RoleFacultyViewer: {
+		PermStudentsRead, PermPlacementsRead,
+		PermCompaniesRead, PermAnalyticsRead,
+	},
+}
+
+// Valid reports whether the role is one the system knows about
+func (r AdminRole) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports whether the role grants the permission
+func (r AdminRole) Can(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Permissions returns every permission the role grants
+func (r AdminRole) Permissions() []Permission {
+	return append([]Permission(nil), rolePermissions[r]...)
+}
+
+// IsDepartmentScoped reports whether the role only sees students of its own department
+func (r AdminRole) IsDepartmentScoped() bool {
+	return r == RoleDepartmentCoordinator
+}
+
+// AdminRoles lists every known role
+func AdminRoles() []AdminRole {
+	return []AdminRole{RoleGroupAdmin, RoleSuperAdmin, RolePlacementCoordinator, RoleDepartmentCoordinator, RoleFacultyViewer}
+}
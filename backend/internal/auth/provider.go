@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrMissingIDToken = errors.New("token response did not include an id_token")
+)
+
+// Identity is a user vouched for by an identity provider
+type Identity struct {
+	Provider string
+	Subject  string
+	Email    string
+	Name     string
+}
+
+// IdentityProvider is an OAuth/OIDC login source. Each provider only vouches
+// for email addresses in its AllowedDomains.
+type IdentityProvider interface {
+	Name() string
+	AllowedDomains() []string
+	AuthCodeURL(ctx context.Context, state, nonce string) (string, error)
+	Authenticate(ctx context.Context, code, nonce string) (*Identity, error)
+}
+
+// EmailInDomains reports whether email belongs to one of domains
+func EmailInDomains(email string, domains []string) bool {
+	domain := EmailDomain(email)
+	if domain == "" {
+		return false
+	}
+
+	for _, d := range domains {
+		if strings.EqualFold(strings.TrimPrefix(d, "@"), domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// EmailDomain returns the lower-cased domain of an email address, or "" if it has none
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+// ============================================
+// GENERIC OIDC PROVIDER
+// ============================================
+
+// OIDCProvider is any OpenID Connect issuer configured by discovery URL,
+// e.g. Google Workspace or a Keycloak realm
+type OIDCProvider struct {
+	name     string
+	domains  []string
+	Config   *oauth2.Config
+	Verifier *IDTokenVerifier
+}
+
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string, allowedDomains []string) *OIDCProvider {
+	return &OIDCProvider{
+		name:    name,
+		domains: allowedDomains,
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       []string{"openid", "profile", "email"},
+		},
+		Verifier: NewIDTokenVerifier(issuer, clientID, ""),
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) AllowedDomains() []string {
+	return p.domains
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	return config.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+func (p *OIDCProvider) Authenticate(ctx context.Context, code, nonce string) (*Identity, error) {
+	config, err := p.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return authenticateIDToken(ctx, p.name, config, p.Verifier, code, nonce)
+}
+
+// oauthConfig fills in the endpoints from the issuer's discovery document
+func (p *OIDCProvider) oauthConfig(ctx context.Context) (*oauth2.Config, error) {
+	metadata, err := p.Verifier.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := *p.Config
+	config.Endpoint = oauth2.Endpoint{
+		AuthURL:  metadata.AuthorizationEndpoint,
+		TokenURL: metadata.TokenEndpoint,
+	}
+	return &config, nil
+}
+
+// authenticateIDToken exchanges the code and builds an identity from the verified id_token
+func authenticateIDToken(ctx context.Context, provider string, config *oauth2.Config, verifier *IDTokenVerifier, code, nonce string) (*Identity, error) {
+	token, err := config.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	claims, err := verifier.Verify(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Identity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    strings.ToLower(claims.EmailAddress()),
+		Name:     claims.Name,
+	}, nil
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestOIDCProviderAuthenticate(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := NewOIDCProvider("keycloak", issuer.server.URL, testClientID, "secret",
+		"http://localhost:4000/auth/callback/keycloak", []string{"college.edu"})
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Path != "/authorize" || parsed.Query().Get("nonce") != testNonce || parsed.Query().Get("state") != "state-1" {
+		t.Errorf("unexpected auth URL %q", authURL)
+	}
+
+	claims := issuer.validClaims()
+	claims.Email = "Faculty@College.edu"
+	issuer.idToken = issuer.sign(t, "key-1", claims)
+
+	identity, err := provider.Authenticate(context.Background(), "code", testNonce)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if identity.Provider != "keycloak" || identity.Subject != "subject-1" || identity.Email != "faculty@college.edu" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	unverified := false
+	claims.EmailVerified = &unverified
+	issuer.idToken = issuer.sign(t, "key-1", claims)
+
+	if _, err := provider.Authenticate(context.Background(), "code", testNonce); !errors.Is(err, ErrEmailNotVerified) {
+		t.Fatalf("got error %v, want ErrEmailNotVerified", err)
+	}
+}
+
+func TestEmailInDomains(t *testing.T) {
+	domains := []string{"kct.ac.in", "@college.edu"}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"student@kct.ac.in", true},
+		{"Student@KCT.AC.IN", true},
+		{"faculty@college.edu", true},
+		{"someone@evil-kct.ac.in", false},
+		{"someone@kct.ac.in.evil.com", false},
+		{"no-at-sign", false},
+	}
+
+	for _, tt := range tests {
+		if got := EmailInDomains(tt.email, domains); got != tt.want {
+			t.Errorf("EmailInDomains(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	tests := map[string]string{
+		"student@KCT.ac.in": "kct.ac.in",
+		"a@b@college.edu":   "college.edu",
+		"no-at-sign":        "",
+		"trailing@":         "",
+	}
+
+	for email, want := range tests {
+		if got := EmailDomain(email); got != want {
+			t.Errorf("EmailDomain(%q) = %q, want %q", email, got, want)
+		}
+	}
+}
@@ -0,0 +1,539 @@
+// Package config loads the API settings from an optional YAML file and the
+// environment, applies defaults and validates everything up front so a bad
+// deploy fails at startup instead of on the first request that needs it.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the effective configuration. Environment variables override the
+// file, which overrides the defaults.
+type Config struct {
+	Port     int    `yaml:"port"`
+	Env      string `yaml:"env"` // development, staging or production
+	LogLevel string `yaml:"log_level"`
+	// AllowedDomain is the home institution's email domain, e.g. kct.ac.in
+	AllowedDomain   string        `yaml:"allowed_domain"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// ReadinessDrain is how long /ready reports 503 before the server stops
+	// accepting connections, so the load balancer can take it out of rotation
+	ReadinessDrain time.Duration `yaml:"readiness_drain"`
+
+	API       API       `yaml:"api"`
+	DB        DB        `yaml:"db"`
+	Microsoft Microsoft `yaml:"microsoft"`
+	OIDC      []OIDC    `yaml:"oidc"`
+	JWT       JWT       `yaml:"jwt"`
+	Tokens    Tokens    `yaml:"tokens"`
+	Frontend  Frontend  `yaml:"frontend"`
+	CORS      CORS      `yaml:"cors"`
+	Uploads   Uploads   `yaml:"uploads"`
+	Metrics   Metrics   `yaml:"metrics"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	Jobs      Jobs      `yaml:"jobs"`
+	Schedule  Schedule  `yaml:"schedule"`
+	// Eligibility is the rule the scheduler applies to is_eligible_for_placement
+	Eligibility Eligibility `yaml:"eligibility"`
+	Analytics   Analytics   `yaml:"analytics"`
+}
+
+// API controls the unversioned paths that predate /api/v1
+type API struct {
+	// LegacyPaths keeps /auth/... and /api/... working alongside /api/v1
+	LegacyPaths bool `yaml:"legacy_paths"`
+	// LegacySunset is the date (YYYY-MM-DD) announced in the Sunset header
+	// of legacy responses; empty announces no date
+	LegacySunset string `yaml:"legacy_sunset"`
+}
+
+// SunsetDate parses LegacySunset; ok is false when it is not set
+func (a API) SunsetDate() (date time.Time, ok bool, err error) {
+	if a.LegacySunset == "" {
+		return time.Time{}, false, nil
+	}
+	date, err = time.Parse(time.DateOnly, a.LegacySunset)
+	return date, err == nil, err
+}
+
+type DB struct {
+	DSN          string        `yaml:"dsn"`
+	MaxOpenConns int           `yaml:"max_open_conns"`
+	MaxIdleConns int           `yaml:"max_idle_conns"`
+	MaxIdleTime  time.Duration `yaml:"max_idle_time"`
+
+	// Query timeouts by kind of work. A query is also cancelled when the
+	// request that started it is.
+	ReadTimeout      time.Duration `yaml:"read_timeout"`
+	WriteTimeout     time.Duration `yaml:"write_timeout"`
+	AnalyticsTimeout time.Duration `yaml:"analytics_timeout"`
+	ExportTimeout    time.Duration `yaml:"export_timeout"`
+	// SlowQuery logs model calls that take longer, with the request ID; 0
+	// turns the log off
+	SlowQuery time.Duration `yaml:"slow_query"`
+}
+
+type Microsoft struct {
+	ClientID       string   `yaml:"client_id"`
+	ClientSecret   string   `yaml:"client_secret"`
+	TenantID       string   `yaml:"tenant_id"`
+	RedirectURL    string   `yaml:"redirect_url"`
+	AllowedDomains []string `yaml:"allowed_domains"`
+}
+
+// OIDC describes an additional OpenID Connect login provider
+type OIDC struct {
+	Name           string   `yaml:"name"`
+	Issuer         string   `yaml:"issuer"`
+	ClientID       string   `yaml:"client_id"`
+	ClientSecret   string   `yaml:"client_secret"`
+	RedirectURL    string   `yaml:"redirect_url"`
+	AllowedDomains []string `yaml:"allowed_domains"`
+}
+
+type JWT struct {
+	Secret    string `yaml:"secret"`
+	KeysDir   string `yaml:"keys_dir"`
+	ActiveKID string `yaml:"active_kid"`
+	Issuer    string `yaml:"issuer"`
+	// AcceptLegacyHS256Until is the RFC 3339 time until which HS256 tokens
+	// signed with Secret still verify after switching to keys_dir. It is
+	// required while both are set, so the secret cannot linger unnoticed.
+	AcceptLegacyHS256Until string `yaml:"accept_legacy_hs256_until"`
+}
+
+// maxLegacyHS256Window bounds how far ahead AcceptLegacyHS256Until may be;
+// access tokens live minutes, so a day is ample time to finish the switch
+const maxLegacyHS256Window = 24 * time.Hour
+
+// LegacyHS256Deadline parses AcceptLegacyHS256Until; ok is false when it is not set
+func (j JWT) LegacyHS256Deadline() (until time.Time, ok bool, err error) {
+	if j.AcceptLegacyHS256Until == "" {
+		return time.Time{}, false, nil
+	}
+	until, err = time.Parse(time.RFC3339, j.AcceptLegacyHS256Until)
+	return until, err == nil, err
+}
+
+// Tokens sets how long each kind of credential stays valid
+type Tokens struct {
+	AccessTTL        time.Duration `yaml:"access_ttl"`
+	RefreshTTL       time.Duration `yaml:"refresh_ttl"`
+	AuthCodeTTL      time.Duration `yaml:"auth_code_ttl"`
+	ImpersonationTTL time.Duration `yaml:"impersonation_ttl"`
+}
+
+type Frontend struct {
+	URL string `yaml:"url"`
+}
+
+// CORS lists the browser origins allowed to call the API with credentials.
+// When empty it defaults to the frontend URL plus common local dev servers.
+type CORS struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+type Uploads struct {
+	// Dir holds uploaded files; photos go in its photos subdirectory
+	Dir        string `yaml:"dir"`
+	MaxPhotoMB int    `yaml:"max_photo_mb"`
+}
+
+type Metrics struct {
+	// Token is the bearer token Prometheus scrapes /metrics with
+	Token string `yaml:"token"`
+}
+
+type RateLimit struct {
+	Backend        string   `yaml:"backend"` // memory or postgres
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// Requests per minute for each route group; 0 disables the limit
+	Auth    int `yaml:"auth"`
+	Student int `yaml:"student"`
+	Admin   int `yaml:"admin"`
+	Upload  int `yaml:"upload"`
+	Export  int `yaml:"export"`
+
+	// TrustedProxyNets is TrustedProxies parsed by Load
+	TrustedProxyNets []*net.IPNet `yaml:"-"`
+}
+
+type Jobs struct {
+	// Workers is how many background jobs this process runs at once; 0 leaves
+	// the queue to other replicas
+	Workers      int           `yaml:"workers"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	// Lease bounds one attempt; a job running longer is handed to another worker
+	Lease time.Duration `yaml:"lease"`
+}
+
+type Schedule struct {
+	// Enabled lets this process compete to run scheduled maintenance; only
+	// the replica holding the leader lock runs it
+	Enabled bool `yaml:"enabled"`
+	// Timezone the cron expressions are read in, e.g. Asia/Kolkata
+	Timezone string `yaml:"timezone"`
+	// ExportRetention is how long finished export files are kept
+	ExportRetention time.Duration `yaml:"export_retention"`
+
+	// Location is Timezone loaded by Load
+	Location *time.Location `yaml:"-"`
+}
+
+type Eligibility struct {
+	// MinCGPA is the lowest overall CGPA that is eligible; 0 disables the cutoff
+	MinCGPA     float64 `yaml:"min_cgpa"`
+	MaxBacklogs int     `yaml:"max_backlogs"`
+}
+
+type Analytics struct {
+	// CacheTTL is the longest a cached aggregate is served. Writes through
+	// this process clear the cache at once; the TTL covers writes made by
+	// other replicas. 0 turns the cache off.
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// devOrigins are allowed alongside the frontend URL when CORS is not configured
+var devOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://localhost:8080",
+	"http://127.0.0.1:5500", // VS Code Live Server
+}
+
+// Default returns the settings used for anything neither the file nor the
+// environment sets
+func Default() Config {
+	return Config{
+		Port:            4000,
+		Env:             "development",
+		LogLevel:        "info",
+		AllowedDomain:   "kct.ac.in",
+		ShutdownTimeout: 30 * time.Second,
+		ReadinessDrain:  5 * time.Second,
+		API:             API{LegacyPaths: true},
+		DB: DB{
+			MaxOpenConns: 25,
+			MaxIdleConns: 25,
+			MaxIdleTime:  15 * time.Minute,
+
+			ReadTimeout:      5 * time.Second,
+			WriteTimeout:     5 * time.Second,
+			AnalyticsTimeout: 10 * time.Second,
+			ExportTimeout:    30 * time.Second,
+			SlowQuery:        500 * time.Millisecond,
+		},
+		Microsoft: Microsoft{
+			RedirectURL: "http://localhost:4000/api/v1/auth/callback",
+		},
+		Tokens: Tokens{
+			AccessTTL:        15 * time.Minute,
+			RefreshTTL:       30 * 24 * time.Hour,
+			AuthCodeTTL:      time.Minute,
+			ImpersonationTTL: 10 * time.Minute,
+		},
+		Frontend: Frontend{URL: "http://localhost:5500"},
+		Uploads:  Uploads{Dir: "./uploads", MaxPhotoMB: 5},
+		RateLimit: RateLimit{
+			Backend: "memory",
+			Auth:    20,
+			Student: 120,
+			Admin:   300,
+			Upload:  10,
+			Export:  5,
+		},
+		Jobs:      Jobs{Workers: 2, PollInterval: 2 * time.Second, Lease: 10 * time.Minute},
+		Schedule:  Schedule{Enabled: true, Timezone: "Asia/Kolkata", ExportRetention: 7 * 24 * time.Hour},
+		Analytics: Analytics{CacheTTL: 2 * time.Minute},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if
+// path is not empty) and the environment, and validates the result. Every
+// malformed variable, or else every invalid setting, is reported at once.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+	cfg.fillDerived()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	nets, err := ParseCIDRs(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	cfg.RateLimit.TrustedProxyNets = nets
+
+	loc, err := time.LoadLocation(cfg.Schedule.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Schedule.Location = loc
+
+	return &cfg, nil
+}
+
+// loadFile decodes the YAML file over the defaults; unknown keys are errors
+// so a misspelt setting is not silently ignored
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// fillDerived sets defaults that depend on other settings
+func (c *Config) fillDerived() {
+	if len(c.Microsoft.AllowedDomains) == 0 && c.AllowedDomain != "" {
+		c.Microsoft.AllowedDomains = []string{c.AllowedDomain}
+	}
+
+	for i := range c.OIDC {
+		c.OIDC[i].Name = strings.ToLower(c.OIDC[i].Name)
+		if c.OIDC[i].RedirectURL == "" {
+			c.OIDC[i].RedirectURL = c.Microsoft.RedirectURL + "/" + c.OIDC[i].Name
+		}
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		c.CORS.AllowedOrigins = append([]string{c.Frontend.URL}, devOrigins...)
+	}
+}
+
+// ============================================
+// VALIDATION
+// ============================================
+
+// Validate checks every setting and returns all problems joined together
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Port > 0 && c.Port < 65536, "port: %d is not a valid TCP port", c.Port)
+	check(slices.Contains([]string{"development", "staging", "production"}, c.Env),
+		"env: must be development, staging or production, got %q", c.Env)
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.LogLevel),
+		"log_level: must be debug, info, warn or error, got %q", c.LogLevel)
+	check(c.ShutdownTimeout > 0, "shutdown_timeout: must be positive")
+	check(c.ReadinessDrain >= 0 && c.ReadinessDrain <= time.Minute, "readiness_drain: must be between 0 and 1m")
+
+	// API
+	if _, _, err := c.API.SunsetDate(); err != nil {
+		errs = append(errs, fmt.Errorf("api.legacy_sunset: must be a date such as 2027-06-30, got %q", c.API.LegacySunset))
+	}
+
+	// Database
+	check(c.DB.DSN != "", "db.dsn: DATABASE_URL or DB_DSN is required")
+	if strings.Contains(c.DB.DSN, "://") {
+		u, err := url.Parse(c.DB.DSN)
+		check(err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") && u.Host != "",
+			"db.dsn: must be a postgres:// URL or a key=value connection string")
+	}
+	check(c.DB.MaxOpenConns > 0, "db.max_open_conns: must be at least 1")
+	check(c.DB.MaxIdleConns >= 0 && c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns: must be between 0 and max_open_conns (%d)", c.DB.MaxOpenConns)
+	check(c.DB.MaxIdleTime >= 0, "db.max_idle_time: must not be negative")
+	check(c.DB.ReadTimeout > 0 && c.DB.WriteTimeout > 0 && c.DB.AnalyticsTimeout > 0 && c.DB.ExportTimeout > 0,
+		"db: read_timeout, write_timeout, analytics_timeout and export_timeout must be positive")
+	check(c.DB.SlowQuery >= 0, "db.slow_query: must not be negative (use 0 to disable the log)")
+
+	// Sign-in
+	check(c.Microsoft.ClientID != "", "microsoft.client_id: MICROSOFT_CLIENT_ID or CLIENT_ID is required")
+	check(c.Microsoft.ClientSecret != "", "microsoft.client_secret: MICROSOFT_CLIENT_SECRET or CLIENT_SECRET is required")
+	check(c.Microsoft.TenantID != "", "microsoft.tenant_id: MICROSOFT_TENANT_ID is required")
+	errs = append(errs, checkURL("microsoft.redirect_url", c.Microsoft.RedirectURL, false))
+	errs = append(errs, c.checkCallbackPath("microsoft.redirect_url", c.Microsoft.RedirectURL))
+	check(len(c.Microsoft.AllowedDomains) > 0, "microsoft.allowed_domains: at least one domain is required")
+
+	seen := map[string]bool{}
+	for _, p := range c.OIDC {
+		field := "oidc." + p.Name
+		check(p.Name != "", "oidc: every provider needs a name")
+		check(p.Name != "microsoft", "%s: microsoft is configured with the microsoft settings, not as an OIDC provider", field)
+		check(!seen[p.Name], "%s: listed twice", field)
+		seen[p.Name] = true
+
+		errs = append(errs, checkURL(field+".issuer", p.Issuer, false))
+		check(p.ClientID != "", "%s.client_id: is required", field)
+		check(p.ClientSecret != "", "%s.client_secret: is required", field)
+		errs = append(errs, checkURL(field+".redirect_url", p.RedirectURL, false))
+		errs = append(errs, c.checkCallbackPath(field+".redirect_url", p.RedirectURL))
+		check(len(p.AllowedDomains) > 0, "%s.allowed_domains: at least one domain is required", field)
+	}
+
+	// Tokens
+	check(c.JWT.KeysDir != "" || c.JWT.Secret != "", "jwt: JWT_KEYS_DIR or JWT_SECRET is required")
+	check(c.JWT.KeysDir == "" || c.JWT.ActiveKID != "", "jwt.active_kid: is required when keys_dir is set")
+	if until, ok, err := c.JWT.LegacyHS256Deadline(); err != nil {
+		errs = append(errs, fmt.Errorf("jwt.accept_legacy_hs256_until: must be a time such as 2026-10-01T18:00:00+05:30, got %q", c.JWT.AcceptLegacyHS256Until))
+	} else if c.JWT.KeysDir != "" && c.JWT.Secret != "" {
+		check(ok, "jwt.accept_legacy_hs256_until: is required when both keys_dir and secret are set")
+		check(!ok || time.Until(until) <= maxLegacyHS256Window,
+			"jwt.accept_legacy_hs256_until: must be within %s of startup", maxLegacyHS256Window)
+	}
+	check(c.Tokens.AccessTTL > 0, "tokens.access_ttl: must be positive")
+	check(c.Tokens.RefreshTTL > c.Tokens.AccessTTL, "tokens.refresh_ttl: must be longer than access_ttl")
+	check(c.Tokens.AuthCodeTTL > 0 && c.Tokens.AuthCodeTTL <= 10*time.Minute,
+		"tokens.auth_code_ttl: must be between 1s and 10m")
+	check(c.Tokens.ImpersonationTTL > 0, "tokens.impersonation_ttl: must be positive")
+
+	// HTTP
+	errs = append(errs, checkURL("frontend.url", c.Frontend.URL, false))
+	for _, origin := range c.CORS.AllowedOrigins {
+		errs = append(errs, checkURL("cors.allowed_origins", origin, true))
+	}
+	check(c.Uploads.Dir != "", "uploads.dir: is required")
+	check(c.Uploads.MaxPhotoMB > 0 && c.Uploads.MaxPhotoMB <= 50, "uploads.max_photo_mb: must be between 1 and 50")
+
+	// Rate limits
+	check(c.RateLimit.Backend == "memory" || c.RateLimit.Backend == "postgres",
+		"rate_limit.backend: must be memory or postgres, got %q", c.RateLimit.Backend)
+	check(c.RateLimit.Auth >= 0 && c.RateLimit.Student >= 0 && c.RateLimit.Admin >= 0 &&
+		c.RateLimit.Upload >= 0 && c.RateLimit.Export >= 0,
+		"rate_limit: limits must not be negative (use 0 to disable one)")
+	if _, err := ParseCIDRs(c.RateLimit.TrustedProxies); err != nil {
+		errs = append(errs, fmt.Errorf("rate_limit.trusted_proxies: %w", err))
+	}
+
+	// Background jobs
+	check(c.Jobs.Workers >= 0 && c.Jobs.Workers <= 64, "jobs.workers: must be between 0 and 64")
+	check(c.Jobs.PollInterval >= 100*time.Millisecond, "jobs.poll_interval: must be at least 100ms")
+	check(c.Jobs.Lease >= time.Minute, "jobs.lease: must be at least 1m")
+
+	// Scheduled maintenance
+	if _, err := time.LoadLocation(c.Schedule.Timezone); err != nil || c.Schedule.Timezone == "" {
+		errs = append(errs, fmt.Errorf("schedule.timezone: %q is not a known time zone", c.Schedule.Timezone))
+	}
+	check(c.Schedule.ExportRetention >= time.Hour, "schedule.export_retention: must be at least 1h")
+	check(c.Eligibility.MinCGPA >= 0 && c.Eligibility.MinCGPA <= 10, "eligibility.min_cgpa: must be between 0 and 10")
+	check(c.Eligibility.MaxBacklogs >= 0, "eligibility.max_backlogs: must not be negative")
+	check(c.Analytics.CacheTTL >= 0, "analytics.cache_ttl: must not be negative (use 0 to disable the cache)")
+
+	return errors.Join(errs...)
+}
+
+// checkURL requires an absolute http or https URL. Origins must not have a
+// path, since browsers send only scheme, host and port.
+func checkURL(field, value string, origin bool) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s: %q is not an absolute http(s) URL", field, value)
+	}
+	if origin && (u.Path != "" || u.RawQuery != "") {
+		return fmt.Errorf("%s: %q must be an origin without a path", field, value)
+	}
+	return nil
+}
+
+// checkCallbackPath rejects a sign-in callback on a legacy path once legacy
+// paths are switched off, since the provider would redirect to a 410
+func (c *Config) checkCallbackPath(field, value string) error {
+	if c.API.LegacyPaths {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || strings.HasPrefix(u.Path, "/api/v1/") {
+		return nil
+	}
+	return fmt.Errorf("%s: %q must use the /api/v1/auth/callback path when api.legacy_paths is off", field, value)
+}
+
+// ParseCIDRs parses addresses and CIDR ranges; a bare address matches only itself
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid address %q", value)
+			}
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			value = fmt.Sprintf("%s/%d", value, bits)
+		}
+
+		_, n, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q", value)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// ============================================
+// PRINTING
+// ============================================
+
+const redacted = "[redacted]"
+
+// Redacted returns a copy safe to print: secrets are masked and the database
+// password is removed from the DSN
+func (c Config) Redacted() Config {
+	mask := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	if u, err := url.Parse(c.DB.DSN); err == nil && u.User != nil {
+		c.DB.DSN = u.Redacted()
+	} else {
+		c.DB.DSN = mask(c.DB.DSN)
+	}
+	c.Microsoft.ClientSecret = mask(c.Microsoft.ClientSecret)
+	c.JWT.Secret = mask(c.JWT.Secret)
+	c.Metrics.Token = mask(c.Metrics.Token)
+
+	c.OIDC = slices.Clone(c.OIDC)
+	for i := range c.OIDC {
+		c.OIDC[i].ClientSecret = mask(c.OIDC[i].ClientSecret)
+	}
+	return c
+}
+
+// Write prints the effective configuration as YAML with secrets redacted
+func (c Config) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loadEnv applies environment variables over the current values. Unset and
+// empty variables leave the value alone; malformed ones are errors.
+func (c *Config) loadEnv() error {
+	e := &envReader{}
+
+	e.int(&c.Port, "PORT")
+	e.str(&c.Env, "ENV")
+	e.str(&c.LogLevel, "LOG_LEVEL")
+	e.str(&c.AllowedDomain, "ALLOWED_DOMAIN")
+	e.duration(&c.ShutdownTimeout, time.Second, "SHUTDOWN_TIMEOUT_SECONDS")
+	e.duration(&c.ReadinessDrain, time.Second, "READINESS_DRAIN_SECONDS")
+
+	e.bool(&c.API.LegacyPaths, "LEGACY_API_PATHS")
+	e.str(&c.API.LegacySunset, "LEGACY_API_SUNSET")
+
+	// Database - both DATABASE_URL (Neon/Railway style) and DB_DSN
+	e.str(&c.DB.DSN, "DATABASE_URL", "DB_DSN")
+	e.int(&c.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	e.int(&c.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	e.duration(&c.DB.MaxIdleTime, time.Minute, "DB_MAX_IDLE_TIME_MINUTES")
+	e.duration(&c.DB.ReadTimeout, 0, "DB_READ_TIMEOUT")
+	e.duration(&c.DB.WriteTimeout, 0, "DB_WRITE_TIMEOUT")
+	e.duration(&c.DB.AnalyticsTimeout, 0, "DB_ANALYTICS_TIMEOUT")
+	e.duration(&c.DB.ExportTimeout, 0, "DB_EXPORT_TIMEOUT")
+	e.duration(&c.DB.SlowQuery, 0, "DB_SLOW_QUERY")
+
+	// Microsoft OAuth - both naming conventions
+	e.str(&c.Microsoft.ClientID, "MICROSOFT_CLIENT_ID", "CLIENT_ID")
+	e.str(&c.Microsoft.ClientSecret, "MICROSOFT_CLIENT_SECRET", "CLIENT_SECRET")
+	e.str(&c.Microsoft.TenantID, "MICROSOFT_TENANT_ID")
+	e.str(&c.Microsoft.RedirectURL, "MICROSOFT_REDIRECT_URL", "OAUTH_REDIRECT_URL")
+	e.list(&c.Microsoft.AllowedDomains, "MICROSOFT_ALLOWED_DOMAINS")
+
+	// Additional OIDC providers, e.g. OIDC_PROVIDERS=google,keycloak. Each
+	// name adds a provider or overrides one from the file.
+	var names []string
+	e.list(&names, "OIDC_PROVIDERS")
+	for _, name := range names {
+		name = strings.ToLower(name)
+		p := c.oidcProvider(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		e.str(&p.Issuer, prefix+"ISSUER")
+		e.str(&p.ClientID, prefix+"CLIENT_ID")
+		e.str(&p.ClientSecret, prefix+"CLIENT_SECRET")
+		e.str(&p.RedirectURL, prefix+"REDIRECT_URL")
+		e.list(&p.AllowedDomains, prefix+"ALLOWED_DOMAINS")
+	}
+
+	e.str(&c.JWT.Secret, "JWT_SECRET")
+	e.str(&c.JWT.KeysDir, "JWT_KEYS_DIR")
+	e.str(&c.JWT.ActiveKID, "JWT_ACTIVE_KID")
+	e.str(&c.JWT.Issuer, "JWT_ISSUER")
+	e.str(&c.JWT.AcceptLegacyHS256Until, "JWT_ACCEPT_LEGACY_HS256_UNTIL")
+
+	e.duration(&c.Tokens.AccessTTL, 0, "ACCESS_TOKEN_TTL")
+	e.duration(&c.Tokens.RefreshTTL, 0, "REFRESH_TOKEN_TTL")
+	e.duration(&c.Tokens.AuthCodeTTL, 0, "AUTH_CODE_TTL")
+	e.duration(&c.Tokens.ImpersonationTTL, 0, "IMPERSONATION_TOKEN_TTL")
+
+	e.str(&c.Frontend.URL, "FRONTEND_URL")
+	e.list(&c.CORS.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+	e.str(&c.Uploads.Dir, "UPLOAD_DIR")
+	e.int(&c.Uploads.MaxPhotoMB, "UPLOAD_MAX_PHOTO_MB")
+	e.str(&c.Metrics.Token, "METRICS_TOKEN")
+
+	e.str(&c.RateLimit.Backend, "RATE_LIMIT_BACKEND")
+	e.list(&c.RateLimit.TrustedProxies, "TRUSTED_PROXIES")
+	e.int(&c.RateLimit.Auth, "RATE_LIMIT_AUTH")
+	e.int(&c.RateLimit.Student, "RATE_LIMIT_STUDENT")
+	e.int(&c.RateLimit.Admin, "RATE_LIMIT_ADMIN")
+	e.int(&c.RateLimit.Upload, "RATE_LIMIT_UPLOAD")
+	e.int(&c.RateLimit.Export, "RATE_LIMIT_EXPORT")
+
+	e.int(&c.Jobs.Workers, "JOB_WORKERS")
+	e.duration(&c.Jobs.PollInterval, 0, "JOB_POLL_INTERVAL")
+	e.duration(&c.Jobs.Lease, 0, "JOB_LEASE")
+
+	e.bool(&c.Schedule.Enabled, "SCHEDULE_ENABLED")
+	e.str(&c.Schedule.Timezone, "SCHEDULE_TIMEZONE")
+	e.duration(&c.Schedule.ExportRetention, 0, "EXPORT_RETENTION")
+	e.float(&c.Eligibility.MinCGPA, "ELIGIBILITY_MIN_CGPA")
+	e.int(&c.Eligibility.MaxBacklogs, "ELIGIBILITY_MAX_BACKLOGS")
+
+	e.duration(&c.Analytics.CacheTTL, 0, "ANALYTICS_CACHE_TTL")
+
+	return errors.Join(e.errs...)
+}
+
+// oidcProvider returns the provider called name, adding it if the file did not define it
+func (c *Config) oidcProvider(name string) *OIDC {
+	for i := range c.OIDC {
+		if strings.EqualFold(c.OIDC[i].Name, name) {
+			return &c.OIDC[i]
+		}
+	}
+	c.OIDC = append(c.OIDC, OIDC{Name: name})
+	return &c.OIDC[len(c.OIDC)-1]
+}
+
+// envReader copies environment variables into settings, collecting parse errors
+type envReader struct {
+	errs []error
+}
+
+// lookup returns the first of keys that is set and not empty
+func (e *envReader) lookup(keys ...string) (string, bool) {
+	for _, key := range keys {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func (e *envReader) str(dst *string, keys ...string) {
+	if value, ok := e.lookup(keys...); ok {
+		*dst = value
+	}
+}
+
+// list parses a comma-separated value
+func (e *envReader) list(dst *[]string, key string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func (e *envReader) int(dst *int, key string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a whole number", key, value))
+		return
+	}
+	*dst = n
+}
+
+func (e *envReader) float(dst *float64, key string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a number", key, value))
+		return
+	}
+	*dst = f
+}
+
+func (e *envReader) bool(dst *bool, key string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not true or false", key, value))
+		return
+	}
+	*dst = b
+}
+
+// duration parses a whole number of unit, or a Go duration such as 15m when unit is 0
+func (e *envReader) duration(dst *time.Duration, unit time.Duration, key string) {
+	value, ok := e.lookup(key)
+	if !ok {
+		return
+	}
+
+	if unit != 0 {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not a whole number", key, value))
+			return
+		}
+		*dst = time.Duration(n) * unit
+		return
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.errs = append(e.errs, fmt.Errorf("%s: %q is not a duration such as 15m or 720h", key, value))
+		return
+	}
+	*dst = d
+}
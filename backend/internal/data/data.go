@@ -7,14 +7,22 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-func OpenDB(dsn string) (*sql.DB, error) {
+// PoolConfig sizes the connection pool. Keep MaxOpenConns times the number of
+// replicas below the database's connection limit.
+type PoolConfig struct {
+	MaxOpenConns int
+	MaxIdleConns int
+	MaxIdleTime  time.Duration
+}
+
+func OpenDB(dsn string, pool PoolConfig) (*sql.DB, error) {
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, err
 	}
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxIdleTime(15 * time.Minute)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxIdleTime(pool.MaxIdleTime)
 
 	err = db.Ping()
 	if err != nil {
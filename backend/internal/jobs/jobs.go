@@ -0,0 +1,128 @@
+// Package jobs runs background work from a durable queue. Jobs are stored in
+// Postgres and claimed with SELECT ... FOR UPDATE SKIP LOCKED, so workers on
+// every replica can poll the same table. A failed job is retried with
+// exponential backoff; once it has used up its attempts it is kept as dead
+// until someone retries it.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusDead      Status = "dead"
+)
+
+var (
+	ErrNotFound = errors.New("job not found")
+	// ErrNotDead is returned when retrying a job that has not failed for good
+	ErrNotDead = errors.New("only dead jobs can be retried")
+	// ErrLeaseLost is returned when recording the outcome of an attempt that
+	// no longer holds its job, because the lease expired and the job was
+	// claimed again
+	ErrLeaseLost = errors.New("job lease lost to another attempt")
+)
+
+// DefaultMaxAttempts applies to jobs enqueued without MaxAttempts
+const DefaultMaxAttempts = 5
+
+// Job is one unit of background work
+type Job struct {
+	ID            int64           `json:"id"`
+	InstitutionID *int64          `json:"institution_id"`
+	Kind          string          `json:"kind"`
+	Payload       json.RawMessage `json:"payload"`
+	Result        json.RawMessage `json:"result,omitempty"`
+	Status        Status          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"max_attempts"`
+	RunAt         time.Time       `json:"run_at"`
+	LockedBy      *string         `json:"locked_by,omitempty"`
+	LockedAt      *time.Time      `json:"locked_at,omitempty"`
+	LastError     *string         `json:"last_error"`
+	CreatedByType *string         `json:"created_by_type"`
+	CreatedByID   *int64          `json:"created_by_id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	FinishedAt    *time.Time      `json:"finished_at"`
+}
+
+// Filter narrows List; zero fields match everything
+type Filter struct {
+	InstitutionID *int64
+	Status        Status
+	Kind          string
+	Limit         int
+}
+
+// Queue stores jobs. PostgresQueue is the real one; MemoryQueue is for tests.
+//
+// Complete, Fail, Release and Bury take the job as Claim returned it and only
+// apply while it is still running under that worker and attempt number. Once
+// its lease has expired and another claim has taken it over, they return
+// ErrLeaseLost and leave the job alone.
+type Queue interface {
+	// Enqueue stores a new pending job and fills in its ID and defaults
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim marks the next due job of one of kinds as running by worker and
+	// returns it, or nil when nothing is due. Running jobs whose lock is
+	// older than lease are claimed again.
+	Claim(ctx context.Context, worker string, kinds []string, lease time.Duration) (*Job, error)
+	// Complete marks a job as succeeded with an optional JSON result
+	Complete(ctx context.Context, job *Job, result json.RawMessage) error
+	// Fail records an error and schedules the job to run again at retryAt
+	Fail(ctx context.Context, job *Job, message string, retryAt time.Time) error
+	// Release puts a claimed job back to pending without counting the attempt
+	Release(ctx context.Context, job *Job) error
+	// Bury records an error and marks the job as dead
+	Bury(ctx context.Context, job *Job, message string) error
+	// Retry gives a dead job a fresh set of attempts, starting now
+	Retry(ctx context.Context, id int64) error
+
+	Get(ctx context.Context, id int64) (*Job, error)
+	// List returns jobs newest first
+	List(ctx context.Context, filter Filter) ([]*Job, error)
+}
+
+// ============================================
+// ERRORS AND BACKOFF
+// ============================================
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error as one a retry cannot fix, such as a
+// malformed payload. The job is buried straight away.
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var p permanentError
+	return errors.As(err, &p)
+}
+
+// Backoff returns how long to wait before the next attempt: 30s after the
+// first failure, doubling each time up to an hour, with up to 20% jitter so
+// jobs that failed together do not retry together.
+func Backoff(attempts int) time.Duration {
+	const base, ceiling = 30 * time.Second, time.Hour
+
+	delay := ceiling
+	if attempts < 20 {
+		delay = min(base<<max(attempts-1, 0), ceiling)
+	}
+	return delay + time.Duration(rand.Int64N(int64(delay/5)+1))
+}
@@ -0,0 +1,298 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newTestRunner(q Queue) *Runner {
+	r := NewRunner(q, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.PollInterval = 10 * time.Millisecond
+	return r
+}
+
+// claim claims the next job of kind, failing the test when none is due
+func claim(t *testing.T, q Queue, kind string, lease time.Duration) *Job {
+	t.Helper()
+	job, err := q.Claim(context.Background(), "test", []string{kind}, lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job == nil {
+		t.Fatalf("no %s job was due", kind)
+	}
+	return job
+}
+
+func get(t *testing.T, q Queue, id int64) *Job {
+	t.Helper()
+	job, err := q.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func TestMemoryQueueClaim(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	q := NewMemoryQueue()
+	q.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	later := &Job{Kind: "mail", RunAt: now.Add(time.Minute)}
+	first := &Job{Kind: "mail"}
+	other := &Job{Kind: "export"}
+	for _, job := range []*Job{later, first, other} {
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	job := claim(t, q, "mail", time.Minute)
+	if job.ID != first.ID || job.Status != StatusRunning || job.Attempts != 1 {
+		t.Errorf("claimed %+v, want job %d running on attempt 1", job, first.ID)
+	}
+
+	// The other mail job is not due yet and export is not asked for
+	if job, _ := q.Claim(ctx, "test", []string{"mail"}, time.Minute); job != nil {
+		t.Errorf("claimed job %d before it was due", job.ID)
+	}
+
+	// A running job whose lease has expired is claimed again
+	now = now.Add(2 * time.Minute)
+	job = claim(t, q, "mail", time.Minute)
+	if job.ID != first.ID || job.Attempts != 2 {
+		t.Errorf("claimed %d on attempt %d, want the expired job %d on attempt 2", job.ID, job.Attempts, first.ID)
+	}
+	if job = claim(t, q, "mail", time.Minute); job.ID != later.ID {
+		t.Errorf("claimed %d, want %d once it was due", job.ID, later.ID)
+	}
+}
+
+func TestMemoryQueueRetry(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+
+	job := &Job{Kind: "mail"}
+	q.Enqueue(ctx, job)
+
+	if err := q.Retry(ctx, job.ID); !errors.Is(err, ErrNotDead) {
+		t.Errorf("retrying a pending job: got %v, want ErrNotDead", err)
+	}
+	if err := q.Retry(ctx, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("retrying a missing job: got %v, want ErrNotFound", err)
+	}
+
+	q.Bury(ctx, claim(t, q, "mail", time.Minute), "boom")
+	if err := q.Retry(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	got := get(t, q, job.ID)
+	if got.Status != StatusPending || got.Attempts != 0 || got.FinishedAt != nil {
+		t.Errorf("after retry: %+v", got)
+	}
+	if got.LastError == nil || *got.LastError != "boom" {
+		t.Errorf("retry dropped the last error: %v", got.LastError)
+	}
+}
+
+func TestMemoryQueueReclaim(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	q := NewMemoryQueue()
+	q.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	job := &Job{Kind: "mail"}
+	q.Enqueue(ctx, job)
+
+	stalled, _ := q.Claim(ctx, "a", []string{"mail"}, time.Minute)
+	now = now.Add(2 * time.Minute)
+	reclaimed, _ := q.Claim(ctx, "b", []string{"mail"}, time.Minute)
+	if stalled == nil || reclaimed == nil || reclaimed.ID != job.ID {
+		t.Fatalf("claims: %+v then %+v", stalled, reclaimed)
+	}
+
+	// The stalled attempt finishing late must not touch the new attempt
+	outcomes := map[string]func() error{
+		"complete": func() error { return q.Complete(ctx, stalled, nil) },
+		"fail":     func() error { return q.Fail(ctx, stalled, "late", now) },
+		"release":  func() error { return q.Release(ctx, stalled) },
+		"bury":     func() error { return q.Bury(ctx, stalled, "late") },
+	}
+	for name, record := range outcomes {
+		if err := record(); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("%s from the stalled attempt: got %v, want ErrLeaseLost", name, err)
+		}
+	}
+	got := get(t, q, job.ID)
+	if got.Status != StatusRunning || got.Attempts != 2 || *got.LockedBy != "b" || got.LastError != nil {
+		t.Errorf("stalled attempt changed the job: %+v", got)
+	}
+
+	// Even a worker with the same name cannot finish a different attempt
+	sameName := *stalled
+	sameName.LockedBy = reclaimed.LockedBy
+	if err := q.Complete(ctx, &sameName, nil); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("earlier attempt under the same worker name: got %v, want ErrLeaseLost", err)
+	}
+
+	if err := q.Complete(ctx, reclaimed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Complete(ctx, reclaimed, nil); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("completing twice: got %v, want ErrLeaseLost", err)
+	}
+	if got := get(t, q, job.ID); got.Status != StatusSucceeded {
+		t.Errorf("reclaimed attempt did not complete: %+v", got)
+	}
+}
+
+func TestRunnerOutcomes(t *testing.T) {
+	q := NewMemoryQueue()
+	r := newTestRunner(q)
+	ctx := context.Background()
+
+	r.Handle("ok", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		return json.RawMessage(`{"rows":3}`), nil
+	})
+	r.Handle("flaky", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		return nil, errors.New("database is down")
+	})
+	r.Handle("bad", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		return nil, Permanent(errors.New("payload is not valid"))
+	})
+	r.Handle("panics", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		panic("nil map")
+	})
+
+	t.Run("success stores the result", func(t *testing.T) {
+		job := &Job{Kind: "ok"}
+		q.Enqueue(ctx, job)
+		r.runJob(ctx, claim(t, q, "ok", time.Minute))
+
+		got := get(t, q, job.ID)
+		if got.Status != StatusSucceeded || string(got.Result) != `{"rows":3}` || got.FinishedAt == nil {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("failures back off and then go dead", func(t *testing.T) {
+		job := &Job{Kind: "flaky", MaxAttempts: 2}
+		q.Enqueue(ctx, job)
+
+		before := time.Now()
+		r.runJob(ctx, claim(t, q, "flaky", time.Minute))
+		got := get(t, q, job.ID)
+		if got.Status != StatusPending || got.LastError == nil || *got.LastError != "database is down" {
+			t.Fatalf("after first failure: %+v", got)
+		}
+		if wait := got.RunAt.Sub(before); wait < 30*time.Second || wait > 40*time.Second {
+			t.Errorf("first retry in %v, want 30s plus jitter", wait)
+		}
+
+		// Make the retry due now rather than waiting out the backoff
+		q.mu.Lock()
+		q.jobs[job.ID].RunAt = time.Now()
+		q.mu.Unlock()
+		r.runJob(ctx, claim(t, q, "flaky", time.Minute))
+		if got := get(t, q, job.ID); got.Status != StatusDead || got.Attempts != 2 {
+			t.Errorf("after the last attempt: status %s attempts %d, want dead after 2", got.Status, got.Attempts)
+		}
+	})
+
+	t.Run("permanent errors go dead at once", func(t *testing.T) {
+		job := &Job{Kind: "bad"}
+		q.Enqueue(ctx, job)
+		r.runJob(ctx, claim(t, q, "bad", time.Minute))
+
+		if got := get(t, q, job.ID); got.Status != StatusDead || got.Attempts != 1 {
+			t.Errorf("status %s attempts %d, want dead after 1", got.Status, got.Attempts)
+		}
+	})
+
+	t.Run("panics are failures", func(t *testing.T) {
+		job := &Job{Kind: "panics"}
+		q.Enqueue(ctx, job)
+		r.runJob(ctx, claim(t, q, "panics", time.Minute))
+
+		got := get(t, q, job.ID)
+		if got.Status != StatusPending || got.LastError == nil || *got.LastError != "panic: nil map" {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("jobs past their attempts are not run again", func(t *testing.T) {
+		job := &Job{Kind: "ok", MaxAttempts: 1}
+		q.Enqueue(ctx, job)
+
+		// The only attempt's worker crashes and its lease expires
+		claim(t, q, "ok", time.Minute)
+		q.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { q.now = time.Now }()
+		r.runJob(ctx, claim(t, q, "ok", time.Minute))
+
+		if got := get(t, q, job.ID); got.Status != StatusDead || got.Result != nil {
+			t.Errorf("got %+v, want dead without running", got)
+		}
+	})
+}
+
+func TestRunnerShutdown(t *testing.T) {
+	q := NewMemoryQueue()
+	r := newTestRunner(q)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	r.Handle("slow", func(ctx context.Context, job *Job) (json.RawMessage, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	job := &Job{Kind: "slow"}
+	q.Enqueue(ctx, job)
+
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	<-started
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+
+	// The interrupted attempt is handed back without counting
+	if got := get(t, q, job.ID); got.Status != StatusPending || got.Attempts != 0 || got.LockedBy != nil {
+		t.Errorf("got %+v, want pending with no attempts", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		min      time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		got := Backoff(tt.attempts)
+		if got < tt.min || got > tt.min+tt.min/5 {
+			t.Errorf("Backoff(%d) = %v, want %v plus at most 20%%", tt.attempts, got, tt.min)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ============================================
+// IN-MEMORY QUEUE
+// ============================================
+
+// MemoryQueue keeps jobs in process memory. Jobs are lost on restart, so it
+// is only meant for tests.
+type MemoryQueue struct {
+	mu     sync.Mutex
+	jobs   map[int64]*Job
+	nextID int64
+	now    func() time.Time
+}
+
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		jobs: make(map[int64]*Job),
+		now:  time.Now,
+	}
+}
+
+func (q *MemoryQueue) Enqueue(_ context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.nextID++
+	job.ID = q.nextID
+	job.Status = StatusPending
+	job.Attempts = 0
+	if job.MaxAttempts < 1 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = json.RawMessage(`{}`)
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	job.CreatedAt, job.UpdatedAt = now, now
+
+	stored := *job
+	q.jobs[job.ID] = &stored
+	return nil
+}
+
+func (q *MemoryQueue) Claim(_ context.Context, worker string, kinds []string, lease time.Duration) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var next *Job
+	for _, job := range q.jobs {
+		if !slices.Contains(kinds, job.Kind) {
+			continue
+		}
+		due := job.Status == StatusPending && !job.RunAt.After(now)
+		expired := job.Status == StatusRunning && job.LockedAt != nil && job.LockedAt.Before(now.Add(-lease))
+		if !due && !expired {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) || (job.RunAt.Equal(next.RunAt) && job.ID < next.ID) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Status = StatusRunning
+	next.Attempts++
+	next.LockedBy = &worker
+	next.LockedAt = &now
+	next.UpdatedAt = now
+
+	claimed := *next
+	return &claimed, nil
+}
+
+func (q *MemoryQueue) Complete(_ context.Context, claimed *Job, result json.RawMessage) error {
+	return q.update(claimed, func(job *Job, now time.Time) {
+		job.Status = StatusSucceeded
+		job.Result = result
+		job.LastError = nil
+		job.FinishedAt = &now
+	})
+}
+
+func (q *MemoryQueue) Fail(_ context.Context, claimed *Job, message string, retryAt time.Time) error {
+	return q.update(claimed, func(job *Job, now time.Time) {
+		job.Status = StatusPending
+		job.LastError = &message
+		job.RunAt = retryAt
+	})
+}
+
+func (q *MemoryQueue) Release(_ context.Context, claimed *Job) error {
+	return q.update(claimed, func(job *Job, now time.Time) {
+		job.Status = StatusPending
+		job.Attempts = max(job.Attempts-1, 0)
+	})
+}
+
+func (q *MemoryQueue) Bury(_ context.Context, claimed *Job, message string) error {
+	return q.update(claimed, func(job *Job, now time.Time) {
+		job.Status = StatusDead
+		job.LastError = &message
+		job.FinishedAt = &now
+	})
+}
+
+func (q *MemoryQueue) Retry(_ context.Context, id int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if job.Status != StatusDead {
+		return ErrNotDead
+	}
+
+	now := q.now()
+	job.Status = StatusPending
+	job.Attempts = 0
+	job.RunAt = now
+	job.FinishedAt = nil
+	job.UpdatedAt = now
+	return nil
+}
+
+func (q *MemoryQueue) Get(_ context.Context, id int64) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	found := *job
+	return &found, nil
+}
+
+func (q *MemoryQueue) List(_ context.Context, filter Filter) ([]*Job, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := []*Job{}
+	for _, job := range q.jobs {
+		if filter.InstitutionID != nil && (job.InstitutionID == nil || *job.InstitutionID != *filter.InstitutionID) {
+			continue
+		}
+		if filter.Status != "" && job.Status != filter.Status {
+			continue
+		}
+		if filter.Kind != "" && job.Kind != filter.Kind {
+			continue
+		}
+		found := *job
+		jobs = append(jobs, &found)
+	}
+
+	// IDs increase with creation time, so this is newest first
+	slices.SortFunc(jobs, func(a, b *Job) int { return int(b.ID - a.ID) })
+	if len(jobs) > filter.Limit {
+		jobs = jobs[:filter.Limit]
+	}
+	return jobs, nil
+}
+
+// update applies fn to a claimed job and releases its lock, as long as the
+// attempt that claimed it still holds it
+func (q *MemoryQueue) update(claimed *Job, fn func(job *Job, now time.Time)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[claimed.ID]
+	if !ok || job.Status != StatusRunning || job.Attempts != claimed.Attempts ||
+		job.LockedBy == nil || claimed.LockedBy == nil || *job.LockedBy != *claimed.LockedBy {
+		return ErrLeaseLost
+	}
+
+	now := q.now()
+	fn(job, now)
+	job.LockedBy = nil
+	job.LockedAt = nil
+	job.UpdatedAt = now
+	return nil
+}
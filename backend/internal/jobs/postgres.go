@@ -0,0 +1,250 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================
+// POSTGRES QUEUE
+// ============================================
+
+// PostgresQueue keeps jobs in the jobs table. Timestamps come from the
+// database clock so replicas with drifting clocks agree on what is due.
+type PostgresQueue struct {
+	DB *sql.DB
+}
+
+func NewPostgresQueue(db *sql.DB) *PostgresQueue {
+	return &PostgresQueue{DB: db}
+}
+
+const jobColumns = `
+	id, institution_id, kind, payload, result, status, attempts, max_attempts, run_at,
+	locked_by, locked_at, last_error, created_by_type, created_by_id, created_at, updated_at, finished_at`
+
+func scanJob(row interface{ Scan(...any) error }) (*Job, error) {
+	var job Job
+	var payload, result []byte
+	err := row.Scan(
+		&job.ID, &job.InstitutionID, &job.Kind, &payload, &result, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt,
+		&job.LockedBy, &job.LockedAt, &job.LastError, &job.CreatedByType, &job.CreatedByID, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	job.Payload, job.Result = payload, result
+	return &job, nil
+}
+
+func (q *PostgresQueue) Enqueue(ctx context.Context, job *Job) error {
+	if job.MaxAttempts < 1 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if len(job.Payload) == 0 {
+		job.Payload = json.RawMessage(`{}`)
+	}
+	var runAt any
+	if !job.RunAt.IsZero() {
+		runAt = job.RunAt
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	row := q.DB.QueryRowContext(ctx, `
+		INSERT INTO jobs (institution_id, kind, payload, max_attempts, run_at, created_by_type, created_by_id)
+		VALUES ($1, $2, $3, $4, COALESCE($5, NOW()), $6, $7)
+		RETURNING `+jobColumns,
+		job.InstitutionID, job.Kind, []byte(job.Payload), job.MaxAttempts, runAt, job.CreatedByType, job.CreatedByID)
+
+	stored, err := scanJob(row)
+	if err != nil {
+		return err
+	}
+	*job = *stored
+	return nil
+}
+
+func (q *PostgresQueue) Claim(ctx context.Context, worker string, kinds []string, lease time.Duration) (*Job, error) {
+	if len(kinds) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// SKIP LOCKED lets concurrent workers pass over a row another worker is
+	// claiming instead of queueing behind it
+	row := q.DB.QueryRowContext(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_by = $1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE kind = ANY($2)
+			  AND ((status = 'pending' AND run_at <= NOW())
+			    OR (status = 'running' AND locked_at < NOW() - $3 * INTERVAL '1 second'))
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns,
+		worker, kinds, lease.Seconds())
+
+	job, err := scanJob(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// heldByAttempt matches a job only while the claimed attempt still holds it:
+// $1 is the job, $2 the worker and $3 the attempt number
+const heldByAttempt = `id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3`
+
+func (q *PostgresQueue) Complete(ctx context.Context, job *Job, result json.RawMessage) error {
+	var res any
+	if len(result) > 0 {
+		res = []byte(result)
+	}
+	return q.finishAttempt(ctx, job, `
+		UPDATE jobs
+		SET status = 'succeeded', result = $4, last_error = NULL, locked_by = NULL, locked_at = NULL,
+		    finished_at = NOW(), updated_at = NOW()
+		WHERE `+heldByAttempt, res)
+}
+
+func (q *PostgresQueue) Fail(ctx context.Context, job *Job, message string, retryAt time.Time) error {
+	return q.finishAttempt(ctx, job, `
+		UPDATE jobs
+		SET status = 'pending', last_error = $4, run_at = $5, locked_by = NULL, locked_at = NULL, updated_at = NOW()
+		WHERE `+heldByAttempt, message, retryAt)
+}
+
+func (q *PostgresQueue) Release(ctx context.Context, job *Job) error {
+	return q.finishAttempt(ctx, job, `
+		UPDATE jobs
+		SET status = 'pending', attempts = GREATEST(attempts - 1, 0), locked_by = NULL, locked_at = NULL, updated_at = NOW()
+		WHERE `+heldByAttempt)
+}
+
+func (q *PostgresQueue) Bury(ctx context.Context, job *Job, message string) error {
+	return q.finishAttempt(ctx, job, `
+		UPDATE jobs
+		SET status = 'dead', last_error = $4, locked_by = NULL, locked_at = NULL, finished_at = NOW(), updated_at = NOW()
+		WHERE `+heldByAttempt, message)
+}
+
+func (q *PostgresQueue) Retry(ctx context.Context, id int64) error {
+	err := q.finish(ctx, `
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = NOW(), finished_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'dead'`, id)
+	if !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	// Nothing updated: tell a missing job apart from one that is not dead
+	if _, err := q.Get(ctx, id); err != nil {
+		return err
+	}
+	return ErrNotDead
+}
+
+func (q *PostgresQueue) Get(ctx context.Context, id int64) (*Job, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	job, err := scanJob(q.DB.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return job, err
+}
+
+func (q *PostgresQueue) List(ctx context.Context, filter Filter) ([]*Job, error) {
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+
+	var conditions []string
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if filter.InstitutionID != nil {
+		add("institution_id = ?", *filter.InstitutionID)
+	}
+	if filter.Status != "" {
+		add("status = ?", filter.Status)
+	}
+	if filter.Kind != "" {
+		add("kind = ?", filter.Kind)
+	}
+
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += " ORDER BY created_at DESC, id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := q.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// finishAttempt runs an update guarded by heldByAttempt, reporting
+// ErrLeaseLost when the attempt no longer holds the job
+func (q *PostgresQueue) finishAttempt(ctx context.Context, job *Job, query string, args ...any) error {
+	var worker string
+	if job.LockedBy != nil {
+		worker = *job.LockedBy
+	}
+
+	err := q.finish(ctx, query, append([]any{job.ID, worker, job.Attempts}, args...)...)
+	if errors.Is(err, ErrNotFound) {
+		return ErrLeaseLost
+	}
+	return err
+}
+
+// finish runs an update of one job, reporting ErrNotFound if it is gone
+func (q *PostgresQueue) finish(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := q.DB.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+//go:build integration
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/data"
+	"github.com/VJ-2303/placement-profiling-system/internal/migrate"
+	"github.com/VJ-2303/placement-profiling-system/migrations"
+)
+
+// TestPostgresQueue needs a scratch database:
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags integration ./internal/jobs/
+func TestPostgresQueue(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := data.OpenDB(dsn, data.PoolConfig{MaxOpenConns: 10, MaxIdleConns: 10, MaxIdleTime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(db, list).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	q := NewPostgresQueue(db)
+	ctx := context.Background()
+	// A kind of its own keeps this run clear of jobs left by earlier ones
+	kind := fmt.Sprintf("test.%d", time.Now().UnixNano())
+
+	t.Run("concurrent claims never share a job", func(t *testing.T) {
+		const n = 20
+		for range n {
+			if err := q.Enqueue(ctx, &Job{Kind: kind}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		var mu sync.Mutex
+		seen := map[int64]bool{}
+		var wg sync.WaitGroup
+		for w := range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					job, err := q.Claim(ctx, fmt.Sprintf("worker-%d", w), []string{kind}, time.Minute)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if job == nil {
+						return
+					}
+					mu.Lock()
+					if seen[job.ID] {
+						t.Errorf("job %d claimed twice", job.ID)
+					}
+					seen[job.ID] = true
+					mu.Unlock()
+					q.Complete(ctx, job, nil)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(seen) != n {
+			t.Errorf("claimed %d jobs, want %d", len(seen), n)
+		}
+	})
+
+	t.Run("fail, bury and retry", func(t *testing.T) {
+		job := &Job{Kind: kind, MaxAttempts: 2}
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+
+		claimed, err := q.Claim(ctx, "test", []string{kind}, time.Minute)
+		if err != nil || claimed == nil || claimed.ID != job.ID {
+			t.Fatalf("claim: %v %+v", err, claimed)
+		}
+		if err := q.Fail(ctx, claimed, "boom", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if next, _ := q.Claim(ctx, "test", []string{kind}, time.Minute); next != nil {
+			t.Errorf("claimed job %d before its retry was due", next.ID)
+		}
+
+		if err := q.Retry(ctx, job.ID); err != ErrNotDead {
+			t.Errorf("retrying a pending job: got %v, want ErrNotDead", err)
+		}
+
+		// Make the retry due now rather than waiting out the backoff
+		if _, err := db.ExecContext(ctx, `UPDATE jobs SET run_at = NOW() WHERE id = $1`, job.ID); err != nil {
+			t.Fatal(err)
+		}
+		claimed, err = q.Claim(ctx, "test", []string{kind}, time.Minute)
+		if err != nil || claimed == nil || claimed.ID != job.ID {
+			t.Fatalf("second claim: %v %+v", err, claimed)
+		}
+		if err := q.Bury(ctx, claimed, "boom again"); err != nil {
+			t.Fatal(err)
+		}
+		if err := q.Retry(ctx, job.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := q.Get(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != StatusPending || got.Attempts != 0 || *got.LastError != "boom again" {
+			t.Errorf("after retry: %+v", got)
+		}
+		db.ExecContext(ctx, `UPDATE jobs SET status = 'dead' WHERE id = $1`, job.ID)
+	})
+
+	t.Run("a reclaimed attempt cannot record its outcome", func(t *testing.T) {
+		job := &Job{Kind: kind}
+		if err := q.Enqueue(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+
+		stalled, err := q.Claim(ctx, "a", []string{kind}, time.Minute)
+		if err != nil || stalled == nil || stalled.ID != job.ID {
+			t.Fatalf("first claim: %v %+v", err, stalled)
+		}
+		// A zero lease treats the running attempt as lost straight away
+		time.Sleep(10 * time.Millisecond)
+		reclaimed, err := q.Claim(ctx, "b", []string{kind}, 0)
+		if err != nil || reclaimed == nil || reclaimed.ID != job.ID || reclaimed.Attempts != 2 {
+			t.Fatalf("reclaim: %v %+v", err, reclaimed)
+		}
+
+		if err := q.Complete(ctx, stalled, nil); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("complete from the stalled attempt: got %v, want ErrLeaseLost", err)
+		}
+		if err := q.Fail(ctx, stalled, "late", time.Now()); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("fail from the stalled attempt: got %v, want ErrLeaseLost", err)
+		}
+		sameName := *stalled
+		sameName.LockedBy = reclaimed.LockedBy
+		if err := q.Release(ctx, &sameName); !errors.Is(err, ErrLeaseLost) {
+			t.Errorf("earlier attempt under the same worker name: got %v, want ErrLeaseLost", err)
+		}
+
+		if err := q.Complete(ctx, reclaimed, nil); err != nil {
+			t.Fatal(err)
+		}
+		got, err := q.Get(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != StatusSucceeded || got.Attempts != 2 || got.LastError != nil {
+			t.Errorf("after the reclaimed attempt: %+v", got)
+		}
+	})
+
+	if err := q.Retry(ctx, -1); err != ErrNotFound {
+		t.Errorf("retrying a missing job: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Handler does the work for one kind of job. The returned result is stored on
+// the job; an error schedules a retry unless it is wrapped with Permanent.
+// The context is cancelled when the lease runs out or the process shuts down.
+type Handler func(ctx context.Context, job *Job) (json.RawMessage, error)
+
+// ============================================
+// RUNNER
+// ============================================
+
+// Runner polls a queue from a pool of worker goroutines
+type Runner struct {
+	Queue Queue
+	// Workers is the number of jobs run at once by this process
+	Workers int
+	// PollInterval is how long an idle worker waits before looking again
+	PollInterval time.Duration
+	// Lease bounds a single attempt. A job still marked running after this
+	// long is assumed lost with its worker and is claimed again.
+	Lease  time.Duration
+	Logger *slog.Logger
+
+	name     string
+	handlers map[string]Handler
+}
+
+func NewRunner(queue Queue, logger *slog.Logger) *Runner {
+	host, _ := os.Hostname()
+	return &Runner{
+		Queue:        queue,
+		Workers:      2,
+		PollInterval: 2 * time.Second,
+		Lease:        10 * time.Minute,
+		Logger:       logger,
+		name:         fmt.Sprintf("%s-%d", host, os.Getpid()),
+		handlers:     make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler for kind. Jobs of kinds without a handler are
+// left for another process to claim.
+func (r *Runner) Handle(kind string, h Handler) {
+	r.handlers[kind] = h
+}
+
+// Kinds returns the registered job kinds
+func (r *Runner) Kinds() []string {
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// Run starts the workers and blocks until ctx is cancelled and every worker
+// has stopped. Jobs interrupted by the cancellation are released without
+// using up an attempt.
+func (r *Runner) Run(ctx context.Context) {
+	kinds := r.Kinds()
+	r.Logger.Info("job workers starting", "workers", r.Workers, "kinds", kinds, "lease", r.Lease.String())
+
+	var wg sync.WaitGroup
+	for i := range r.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx, fmt.Sprintf("%s/%d", r.name, i+1), kinds)
+		}()
+	}
+	wg.Wait()
+
+	r.Logger.Info("job workers stopped")
+}
+
+// work claims and runs jobs until ctx is cancelled, sleeping when the queue is empty
+func (r *Runner) work(ctx context.Context, worker string, kinds []string) {
+	for ctx.Err() == nil {
+		job, err := r.Queue.Claim(ctx, worker, kinds, r.Lease)
+		if err != nil && ctx.Err() == nil {
+			r.Logger.Error("claiming job failed", "worker", worker, "error", err)
+		}
+		if job != nil {
+			r.runJob(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.PollInterval):
+		}
+	}
+}
+
+// runJob runs one claimed attempt and records how it ended
+func (r *Runner) runJob(ctx context.Context, job *Job) {
+	log := r.Logger.With("job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+	// Recording the outcome must not be skipped because shutdown began
+	store := context.WithoutCancel(ctx)
+
+	// Attempts only exceeds the limit when the final attempt's lease expired,
+	// typically because its worker crashed; running it again could crash again
+	if job.Attempts > job.MaxAttempts {
+		log.Error("job dead", "error", "lease expired on the final attempt")
+		r.record(log, r.Queue.Bury(store, job, "lease expired on the final attempt"))
+		return
+	}
+
+	start := time.Now()
+	result, err := r.call(ctx, job)
+	log = log.With("duration_ms", time.Since(start).Milliseconds())
+
+	switch {
+	case err == nil:
+		log.Info("job succeeded")
+		r.record(log, r.Queue.Complete(store, job, result))
+
+	case ctx.Err() != nil:
+		log.Warn("job interrupted by shutdown", "error", err)
+		r.record(log, r.Queue.Release(store, job))
+
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		log.Error("job dead", "error", err)
+		r.record(log, r.Queue.Bury(store, job, err.Error()))
+
+	default:
+		retryAt := time.Now().Add(Backoff(job.Attempts))
+		log.Warn("job failed", "error", err, "retry_at", retryAt)
+		r.record(log, r.Queue.Fail(store, job, err.Error(), retryAt))
+	}
+}
+
+// call runs the job's handler under the lease, turning a panic into an error
+func (r *Runner) call(ctx context.Context, job *Job) (result json.RawMessage, err error) {
+	h, ok := r.handlers[job.Kind]
+	if !ok {
+		return nil, Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Lease)
+	defer cancel()
+
+	defer func() {
+		if p := recover(); p != nil {
+			r.Logger.Error("job panicked", "job_id", job.ID, "panic", p, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+// record logs a failure to store an attempt's outcome. A lost lease means
+// another worker has claimed the job again and now owns its outcome.
+func (r *Runner) record(log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, ErrLeaseLost):
+		log.Warn("job outcome discarded", "error", err)
+	case err != nil:
+		log.Error("recording job outcome failed", "error", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package migrate applies numbered SQL migrations and records them in the
+// schema_migrations table. Every change takes a Postgres advisory lock first,
+// so replicas that start together apply each migration exactly once.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockID is the advisory lock key held while migrations run; any constant
+// works as long as nothing else in the database uses it
+const lockID int64 = 0x706c6163656d6e74 // "placemnt"
+
+var fileRX = regexp.MustCompile(`^([0-9]+)_([a-z0-9_]+?)(\.down)?\.sql$`)
+
+// ErrSchemaBehind is wrapped by Check when migrations are pending
+var ErrSchemaBehind = errors.New("database schema is behind")
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	// Down is empty when the migration has no .down.sql script
+	Down string
+}
+
+// Status pairs a migration with when it was applied, if it has been
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load reads NNN_name.sql and NNN_name.down.sql files from fsys, sorted by
+// version. Other files are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	downs := make(map[int64]string)
+	for _, entry := range entries {
+		match := fileRX.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: invalid version", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] != "" {
+			downs[version] = string(body)
+			continue
+		}
+		if existing, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("%s: version %d is already used by %s", entry.Name(), version, existing.Name)
+		}
+		byVersion[version] = &Migration{Version: version, Name: match[2], Up: string(body)}
+	}
+
+	for version, down := range downs {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down script for version %d has no matching up script", version)
+		}
+		m.Down = down
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// ============================================
+// MIGRATOR
+// ============================================
+
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{DB: db, Migrations: migrations}
+}
+
+// Latest is the version the schema reaches once every migration is applied
+func (m *Migrator) Latest() int64 {
+	if len(m.Migrations) == 0 {
+		return 0
+	}
+	return m.Migrations[len(m.Migrations)-1].Version
+}
+
+// Up applies every pending migration in order, each in its own transaction,
+// and returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			if err := checkEmpty(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		for _, mig := range m.Migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, mig.Version, mig.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("applying %03d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the most recently applied migrations, newest first, and
+// returns the ones it reverted. It stops before a migration without a down
+// script rather than skipping it.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.Migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.Migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%03d_%s has no down script", mig.Version, mig.Name)
+			}
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("reverting %03d_%s: %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Baseline records every migration up to version as applied without running
+// it, for databases whose schema was created by hand before this runner
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	if version <= 0 || version > m.Latest() {
+		return fmt.Errorf("baseline version must be between 1 and %d", m.Latest())
+	}
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		for _, mig := range m.Migrations {
+			if mig.Version > version {
+				break
+			}
+			_, err := conn.ExecContext(ctx, `
+				INSERT INTO schema_migrations (version, name) VALUES ($1, $2)
+				ON CONFLICT (version) DO NOTHING`, mig.Version, mig.Name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.Migrations))
+	for i, mig := range m.Migrations {
+		statuses[i] = Status{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns an error wrapping ErrSchemaBehind when any migration is
+// pending. A database ahead of this binary passes, so an older release can
+// still run during a rollback.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, mig := range m.Migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%03d_%s", mig.Version, mig.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %d pending migration(s) %v", ErrSchemaBehind, len(pending), pending)
+	}
+	return nil
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+// applied reads schema_migrations without taking the lock; a database that
+// has never been migrated has nothing applied
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	var table sql.NullString
+	if err := m.DB.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations')::text`).Scan(&table); err != nil {
+		return nil, err
+	}
+	if !table.Valid {
+		return map[int64]time.Time{}, nil
+	}
+
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return appliedVersions(ctx, conn)
+}
+
+// locked runs fn on one connection holding the advisory lock, after making
+// sure schema_migrations exists
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Blocks until any other migrator finishes
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// checkEmpty refuses to run the first migration over tables created by hand,
+// which would fail halfway through with "already exists"
+func checkEmpty(ctx context.Context, conn *sql.Conn) error {
+	var tables int
+	err := conn.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'`).Scan(&tables)
+	if err != nil {
+		return err
+	}
+	if tables > 0 {
+		return errors.New("database has tables but no recorded migrations; run `migrate baseline VERSION` with the last migration already applied by hand")
+	}
+	return nil
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/VJ-2303/placement-profiling-system/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":       {Data: []byte("CREATE TABLE later ();")},
+		"002_second.sql":      {Data: []byte("CREATE TABLE second ();")},
+		"002_second.down.sql": {Data: []byte("DROP TABLE second;")},
+		"001_first.sql":       {Data: []byte("CREATE TABLE first ();")},
+		"migrations.go":       {Data: []byte("package migrations")},
+		"README.md":           {Data: []byte("notes")},
+	}
+
+	list, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versions []int64
+	for _, m := range list {
+		versions = append(versions, m.Version)
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[1] != 2 || versions[2] != 10 {
+		t.Fatalf("got versions %v, want [1 2 10]", versions)
+	}
+	if list[1].Name != "second" || list[1].Down != "DROP TABLE second;" {
+		t.Errorf("down script not attached: %+v", list[1])
+	}
+	if list[0].Down != "" {
+		t.Errorf("001 has no down script, got %q", list[0].Down)
+	}
+	if got := New(nil, list).Latest(); got != 10 {
+		t.Errorf("Latest: got %d, want 10", got)
+	}
+}
+
+func TestLoadRejectsBadSets(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"duplicate version": {
+			"001_a.sql": {Data: []byte("SELECT 1;")},
+			"001_b.sql": {Data: []byte("SELECT 1;")},
+		},
+		"orphan down script": {
+			"001_a.sql":      {Data: []byte("SELECT 1;")},
+			"002_b.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"version zero": {
+			"000_a.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	for name, fsys := range tests {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, m := range list {
+		if m.Version != int64(i+1) {
+			t.Errorf("%03d_%s: versions should run 1, 2, 3... without gaps", m.Version, m.Name)
+		}
+		if m.Down == "" {
+			t.Errorf("%03d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}
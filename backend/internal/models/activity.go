@@ -0,0 +1,93 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ActivityLog is an audit entry describing who did what to which record
+type ActivityLog struct {
+	ID            int64                  `json:"id"`
+	InstitutionID *int64                 `json:"institution_id"`
+	UserType      string                 `json:"user_type"` // student or admin
+	UserID        int64                  `json:"user_id"`
+	Action        string                 `json:"action"`
+	EntityType    *string                `json:"entity_type"`
+	EntityID      *int64                 `json:"entity_id"`
+	Details       map[string]interface{} `json:"details"`
+	IPAddress     *string                `json:"ip_address"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+type ActivityLogModel struct {
+	DB *DB
+}
+
+// Insert records an audit entry
+func (m ActivityLogModel) Insert(ctx context.Context, log *ActivityLog) error {
+	query := `
+		INSERT INTO activity_logs (institution_id, user_type, user_id, action, entity_type, entity_id, details, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::inet)
+		RETURNING id, created_at`
+
+	var details []byte
+	if log.Details != nil {
+		var err error
+		details, err = json.Marshal(log.Details)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query,
+		log.InstitutionID, log.UserType, log.UserID, log.Action, log.EntityType, log.EntityID, details, log.IPAddress,
+	).Scan(&log.ID, &log.CreatedAt)
+}
+
+// GetByEntityType retrieves the latest audit entries for one kind of record,
+// limited to one institution unless institutionID is nil
+func (m ActivityLogModel) GetByEntityType(ctx context.Context, institutionID *int64, entityType string, limit int) ([]ActivityLog, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, institution_id, user_type, user_id, action, entity_type, entity_id, details, host(ip_address), created_at
+		FROM activity_logs
+		WHERE entity_type = $1 AND ($3::int IS NULL OR institution_id = $3)
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, entityType, limit, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []ActivityLog
+	for rows.Next() {
+		var l ActivityLog
+		var details []byte
+		if err := rows.Scan(
+			&l.ID, &l.InstitutionID, &l.UserType, &l.UserID, &l.Action, &l.EntityType, &l.EntityID,
+			&details, &l.IPAddress, &l.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if len(details) > 0 {
+			if err := json.Unmarshal(details, &l.Details); err != nil {
+				return nil, err
+			}
+		}
+		logs = append(logs, l)
+	}
+
+	return logs, rows.Err()
+}
@@ -4,39 +4,44 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 )
 
 // Admin represents a pre-registered admin user
 type Admin struct {
-	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	Phone       *string   `json:"phone"`
-	Designation string    `json:"designation"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            int64     `json:"id"`
+	InstitutionID int64     `json:"institution_id"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Phone         *string   `json:"phone"`
+	Designation   string    `json:"designation"`
+	Role          string    `json:"role"`
+	Department    *string   `json:"department"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type AdminModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
 // GetByEmail retrieves an admin by email (must be pre-registered)
-func (m AdminModel) GetByEmail(email string) (*Admin, error) {
+func (m AdminModel) GetByEmail(ctx context.Context, email string) (*Admin, error) {
 	query := `
-		SELECT id, name, email, phone, designation, is_active, created_at, updated_at
+		SELECT id, institution_id, name, email, phone, designation, role, department, is_active, created_at, updated_at
 		FROM admins
 		WHERE email = $1 AND is_active = true`
 
 	var admin Admin
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, email).Scan(
-		&admin.ID, &admin.Name, &admin.Email, &admin.Phone,
-		&admin.Designation, &admin.IsActive, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.ID, &admin.InstitutionID, &admin.Name, &admin.Email, &admin.Phone,
+		&admin.Designation, &admin.Role, &admin.Department, &admin.IsActive,
+		&admin.CreatedAt, &admin.UpdatedAt,
 	)
 
 	if err != nil {
@@ -50,19 +55,20 @@ func (m AdminModel) GetByEmail(email string) (*Admin, error) {
 }
 
 // GetByID retrieves an admin by ID
-func (m AdminModel) GetByID(id int64) (*Admin, error) {
+func (m AdminModel) GetByID(ctx context.Context, id int64) (*Admin, error) {
 	query := `
-		SELECT id, name, email, phone, designation, is_active, created_at, updated_at
+		SELECT id, institution_id, name, email, phone, designation, role, department, is_active, created_at, updated_at
 		FROM admins
 		WHERE id = $1`
 
 	var admin Admin
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&admin.ID, &admin.Name, &admin.Email, &admin.Phone,
-		&admin.Designation, &admin.IsActive, &admin.CreatedAt, &admin.UpdatedAt,
+		&admin.ID, &admin.InstitutionID, &admin.Name, &admin.Email, &admin.Phone,
+		&admin.Designation, &admin.Role, &admin.Department, &admin.IsActive,
+		&admin.CreatedAt, &admin.UpdatedAt,
 	)
 
 	if err != nil {
@@ -75,17 +81,18 @@ func (m AdminModel) GetByID(id int64) (*Admin, error) {
 	return &admin, nil
 }
 
-// GetAll retrieves all admins
-func (m AdminModel) GetAll() ([]Admin, error) {
+// GetAll retrieves all admins of an institution, or of every institution when institutionID is nil
+func (m AdminModel) GetAll(ctx context.Context, institutionID *int64) ([]Admin, error) {
 	query := `
-		SELECT id, name, email, phone, designation, is_active, created_at, updated_at
+		SELECT id, institution_id, name, email, phone, designation, role, department, is_active, created_at, updated_at
 		FROM admins
+		WHERE ($1::int IS NULL OR institution_id = $1)
 		ORDER BY name ASC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -95,8 +102,9 @@ func (m AdminModel) GetAll() ([]Admin, error) {
 	for rows.Next() {
 		var admin Admin
 		if err := rows.Scan(
-			&admin.ID, &admin.Name, &admin.Email, &admin.Phone,
-			&admin.Designation, &admin.IsActive, &admin.CreatedAt, &admin.UpdatedAt,
+			&admin.ID, &admin.InstitutionID, &admin.Name, &admin.Email, &admin.Phone,
+			&admin.Designation, &admin.Role, &admin.Department, &admin.IsActive,
+			&admin.CreatedAt, &admin.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -106,33 +114,87 @@ func (m AdminModel) GetAll() ([]Admin, error) {
 	return admins, rows.Err()
 }
 
-// Insert creates a new admin (for initial setup)
-func (m AdminModel) Insert(admin *Admin) error {
+// Insert creates a new admin (for initial setup or an invite)
+func (m AdminModel) Insert(ctx context.Context, admin *Admin) error {
 	query := `
-		INSERT INTO admins (name, email, phone, designation)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at`
+		INSERT INTO admins (institution_id, name, email, phone, designation, role, department)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, is_active, created_at, updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	return m.DB.QueryRowContext(ctx, query,
-		admin.Name, admin.Email, admin.Phone, admin.Designation,
-	).Scan(&admin.ID, &admin.CreatedAt, &admin.UpdatedAt)
+	err := m.DB.QueryRowContext(ctx, query,
+		admin.InstitutionID, admin.Name, admin.Email, admin.Phone, admin.Designation, admin.Role, admin.Department,
+	).Scan(&admin.ID, &admin.IsActive, &admin.CreatedAt, &admin.UpdatedAt)
+
+	if err != nil {
+		if strings.Contains(err.Error(), "admins_email_key") {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	return nil
 }
 
-// Update updates an admin's info
-func (m AdminModel) Update(admin *Admin) error {
+// Update updates an admin's info. It refuses to demote or deactivate the
+// last active super-admin of an institution so it can never be locked out.
+func (m AdminModel) Update(ctx context.Context, admin *Admin) error {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if admin.Role != "super_admin" || !admin.IsActive {
+		// Lock the active super-admins so two concurrent demotions can't both succeed
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id FROM admins
+			WHERE role = 'super_admin' AND is_active = true AND institution_id = $1
+			FOR UPDATE`, admin.InstitutionID)
+		if err != nil {
+			return err
+		}
+
+		var superAdmins []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			superAdmins = append(superAdmins, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(superAdmins) == 1 && superAdmins[0] == admin.ID {
+			return ErrLastSuperAdmin
+		}
+	}
+
 	query := `
 		UPDATE admins
-		SET name = $1, phone = $2, designation = $3, is_active = $4
-		WHERE id = $5
+		SET name = $1, phone = $2, designation = $3, role = $4, department = $5, is_active = $6
+		WHERE id = $7
 		RETURNING updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	return m.DB.QueryRowContext(ctx, query,
-		admin.Name, admin.Phone, admin.Designation, admin.IsActive, admin.ID,
+	err = tx.QueryRowContext(ctx, query,
+		admin.Name, admin.Phone, admin.Designation, admin.Role, admin.Department, admin.IsActive, admin.ID,
 	).Scan(&admin.UpdatedAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	return tx.Commit()
 }
@@ -2,7 +2,7 @@ package models
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -22,6 +22,12 @@ type DashboardStats struct {
 	TotalCompanies       int     `json:"total_companies"`
 }
 
+// DashboardSnapshot is an institution's dashboard as it stood on one day
+type DashboardSnapshot struct {
+	TakenOn time.Time      `json:"taken_on"`
+	Stats   DashboardStats `json:"stats"`
+}
+
 // BatchStats contains statistics per batch
 type BatchStats struct {
 	BatchYear         int     `json:"batch_year"`
@@ -60,14 +66,24 @@ type CompanyStats struct {
 	MaxPackage  float64 `json:"max_package"`
 }
 
+// InstitutionTotals are the headline counts for one institution
+type InstitutionTotals struct {
+	InstitutionCode    string
+	StudentsRegistered int
+	ProfilesCompleted  int
+	PlacementsRecorded int
+}
+
 type AnalyticsModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
-// GetDashboardStats retrieves main dashboard statistics
-func (m AnalyticsModel) GetDashboardStats(batchYear *int) (*DashboardStats, error) {
+// GetDashboardStats retrieves main dashboard statistics. Every analytics
+// query is limited to one institution unless institutionID is nil, and to
+// the students of one department unless department is nil.
+func (m AnalyticsModel) GetDashboardStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) (*DashboardStats, error) {
 	var stats DashboardStats
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
 	// Main student stats query
@@ -81,9 +97,10 @@ func (m AnalyticsModel) GetDashboardStats(batchYear *int) (*DashboardStats, erro
 			COUNT(*) FILTER (WHERE placement_status = 'higher_studies')
 		FROM students s
 		LEFT JOIN batches b ON s.batch_id = b.id
-		WHERE ($1::int IS NULL OR b.year = $1)`
+		WHERE ($1::int IS NULL OR b.year = $1) AND ($2::int IS NULL OR s.institution_id = $2)
+		  AND ($3::text IS NULL OR s.department = $3)`
 
-	err := m.DB.QueryRowContext(ctx, studentQuery, batchYear).Scan(
+	err := m.DB.QueryRowContext(ctx, studentQuery, batchYear, institutionID, department).Scan(
 		&stats.TotalStudents,
 		&stats.ProfilesCompleted,
 		&stats.StudentsPlaced,
@@ -113,9 +130,10 @@ func (m AnalyticsModel) GetDashboardStats(batchYear *int) (*DashboardStats, erro
 		FROM placements p
 		JOIN students s ON p.student_id = s.id
 		LEFT JOIN batches b ON s.batch_id = b.id
-		WHERE p.is_accepted = true AND ($1::int IS NULL OR b.year = $1)`
+		WHERE p.is_accepted = true AND ($1::int IS NULL OR b.year = $1)
+		  AND ($2::int IS NULL OR s.institution_id = $2) AND ($3::text IS NULL OR s.department = $3)`
 
-	err = m.DB.QueryRowContext(ctx, packageQuery, batchYear).Scan(
+	err = m.DB.QueryRowContext(ctx, packageQuery, batchYear, institutionID, department).Scan(
 		&stats.AvgPackage,
 		&stats.MaxPackage,
 		&stats.MinPackage,
@@ -124,9 +142,9 @@ func (m AnalyticsModel) GetDashboardStats(batchYear *int) (*DashboardStats, erro
 		return nil, err
 	}
 
-	// Company count
-	companyQuery := `SELECT COUNT(*) FROM companies WHERE is_active = true`
-	err = m.DB.QueryRowContext(ctx, companyQuery).Scan(&stats.TotalCompanies)
+	// Company count; companies are shared by every department
+	companyQuery := `SELECT COUNT(*) FROM companies WHERE is_active = true AND ($1::int IS NULL OR institution_id = $1)`
+	err = m.DB.QueryRowContext(ctx, companyQuery, institutionID).Scan(&stats.TotalCompanies)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +153,7 @@ func (m AnalyticsModel) GetDashboardStats(batchYear *int) (*DashboardStats, erro
 }
 
 // GetBatchWiseStats retrieves statistics per batch
-func (m AnalyticsModel) GetBatchWiseStats() ([]BatchStats, error) {
+func (m AnalyticsModel) GetBatchWiseStats(ctx context.Context, institutionID *int64, department *string) ([]BatchStats, error) {
 	query := `
 		SELECT 
 			b.year,
@@ -150,16 +168,16 @@ func (m AnalyticsModel) GetBatchWiseStats() ([]BatchStats, error) {
 			COALESCE(AVG(p.package_lpa) FILTER (WHERE p.is_accepted = true), 0),
 			COALESCE(MAX(p.package_lpa) FILTER (WHERE p.is_accepted = true), 0)
 		FROM batches b
-		LEFT JOIN students s ON s.batch_id = b.id
+		LEFT JOIN students s ON s.batch_id = b.id AND ($2::text IS NULL OR s.department = $2)
 		LEFT JOIN placements p ON s.id = p.student_id
-		WHERE b.is_active = true
+		WHERE b.is_active = true AND ($1::int IS NULL OR b.institution_id = $1)
 		GROUP BY b.year
 		ORDER BY b.year DESC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID, department)
 	if err != nil {
 		return nil, err
 	}
@@ -181,7 +199,7 @@ func (m AnalyticsModel) GetBatchWiseStats() ([]BatchStats, error) {
 }
 
 // GetSkillStats retrieves skill-wise statistics
-func (m AnalyticsModel) GetSkillStats() ([]SkillStats, error) {
+func (m AnalyticsModel) GetSkillStats(ctx context.Context, institutionID *int64, department *string) ([]SkillStats, error) {
 	query := `
 		SELECT 
 			sk.name,
@@ -190,19 +208,20 @@ func (m AnalyticsModel) GetSkillStats() ([]SkillStats, error) {
 			COUNT(*) FILTER (WHERE ss.proficiency = 'intermediate'),
 			COUNT(*) FILTER (WHERE ss.proficiency = 'advanced'),
 			COUNT(*) FILTER (WHERE ss.proficiency = 'expert'),
-			COUNT(*)
+			COUNT(ss.id)
 		FROM skills sk
-		LEFT JOIN student_skills ss ON sk.id = ss.skill_id
-		WHERE sk.is_active = true
-		GROUP BY sk.id, sk.name, sk.category
-		HAVING COUNT(*) > 0
-		ORDER BY COUNT(*) DESC
+		LEFT JOIN student_skills ss ON sk.id = ss.skill_id AND ($2::text IS NULL OR EXISTS (
+			SELECT 1 FROM students s WHERE s.id = ss.student_id AND s.department = $2))
+		WHERE sk.is_active = true AND ($1::int IS NULL OR sk.institution_id = $1)
+		GROUP BY sk.name, sk.category
+		HAVING COUNT(ss.id) > 0
+		ORDER BY COUNT(ss.id) DESC, sk.name
 		LIMIT 30`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID, department)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +243,7 @@ func (m AnalyticsModel) GetSkillStats() ([]SkillStats, error) {
 }
 
 // GetCGPADistribution retrieves CGPA distribution
-func (m AnalyticsModel) GetCGPADistribution(batchYear *int) ([]CGPADistribution, error) {
+func (m AnalyticsModel) GetCGPADistribution(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CGPADistribution, error) {
 	query := `
 		SELECT 
 			CASE 
@@ -241,6 +260,7 @@ func (m AnalyticsModel) GetCGPADistribution(batchYear *int) ([]CGPADistribution,
 		JOIN student_academics sa ON s.id = sa.student_id
 		LEFT JOIN batches b ON s.batch_id = b.id
 		WHERE sa.cgpa_overall IS NOT NULL AND ($1::int IS NULL OR b.year = $1)
+		  AND ($2::int IS NULL OR s.institution_id = $2) AND ($3::text IS NULL OR s.department = $3)
 		GROUP BY 1
 		ORDER BY 
 			CASE 
@@ -251,10 +271,10 @@ func (m AnalyticsModel) GetCGPADistribution(batchYear *int) ([]CGPADistribution,
 				ELSE 5
 			END`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, batchYear)
+	rows, err := m.DB.QueryContext(ctx, query, batchYear, institutionID, department)
 	if err != nil {
 		return nil, err
 	}
@@ -273,7 +293,7 @@ func (m AnalyticsModel) GetCGPADistribution(batchYear *int) ([]CGPADistribution,
 }
 
 // GetCompanyStats retrieves placement statistics by company
-func (m AnalyticsModel) GetCompanyStats(batchYear *int) ([]CompanyStats, error) {
+func (m AnalyticsModel) GetCompanyStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CompanyStats, error) {
 	query := `
 		SELECT 
 			COALESCE(c.name, p.company_name),
@@ -285,13 +305,14 @@ func (m AnalyticsModel) GetCompanyStats(batchYear *int) ([]CompanyStats, error)
 		JOIN students s ON p.student_id = s.id
 		LEFT JOIN batches b ON s.batch_id = b.id
 		WHERE p.is_accepted = true AND ($1::int IS NULL OR b.year = $1)
+		  AND ($2::int IS NULL OR s.institution_id = $2) AND ($3::text IS NULL OR s.department = $3)
 		GROUP BY COALESCE(c.name, p.company_name)
 		ORDER BY COUNT(*) DESC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, batchYear)
+	rows, err := m.DB.QueryContext(ctx, query, batchYear, institutionID, department)
 	if err != nil {
 		return nil, err
 	}
@@ -317,7 +338,7 @@ type RecentActivity struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
-func (m AnalyticsModel) GetRecentActivity(limit int) ([]RecentActivity, error) {
+func (m AnalyticsModel) GetRecentActivity(ctx context.Context, institutionID *int64, department *string, limit int) ([]RecentActivity, error) {
 	if limit <= 0 || limit > 50 {
 		limit = 10
 	}
@@ -325,21 +346,23 @@ func (m AnalyticsModel) GetRecentActivity(limit int) ([]RecentActivity, error) {
 	// This is a simplified version - in production you'd use activity_logs table
 	query := `
 		(SELECT 'registration' as type, name, 'New student registered' as details, created_at
-		 FROM students ORDER BY created_at DESC LIMIT $1)
+		 FROM students WHERE ($2::int IS NULL OR institution_id = $2) AND ($3::text IS NULL OR department = $3)
+		 ORDER BY created_at DESC LIMIT $1)
 		UNION ALL
 		(SELECT 'placed' as type, s.name, CONCAT('Placed at ', COALESCE(c.name, p.company_name)) as details, p.created_at
 		 FROM placements p
 		 JOIN students s ON p.student_id = s.id
 		 LEFT JOIN companies c ON p.company_id = c.id
-		 WHERE p.is_accepted = true
+		 WHERE p.is_accepted = true AND ($2::int IS NULL OR s.institution_id = $2)
+		   AND ($3::text IS NULL OR s.department = $3)
 		 ORDER BY p.created_at DESC LIMIT $1)
 		ORDER BY created_at DESC
 		LIMIT $1`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, limit)
+	rows, err := m.DB.QueryContext(ctx, query, limit, institutionID, department)
 	if err != nil {
 		return nil, err
 	}
@@ -357,20 +380,24 @@ func (m AnalyticsModel) GetRecentActivity(limit int) ([]RecentActivity, error) {
 	return activities, rows.Err()
 }
 
-// GetBatches retrieves all batches
+// GetBatches retrieves all batches; across institutions, batches of the same year are merged
 type Batch struct {
 	ID       int  `json:"id"`
 	Year     int  `json:"year"`
 	IsActive bool `json:"is_active"`
 }
 
-func (m AnalyticsModel) GetBatches() ([]Batch, error) {
-	query := `SELECT id, year, is_active FROM batches ORDER BY year DESC`
+func (m AnalyticsModel) GetBatches(ctx context.Context, institutionID *int64) ([]Batch, error) {
+	query := `
+		SELECT MIN(id), year, bool_or(is_active) FROM batches
+		WHERE ($1::int IS NULL OR institution_id = $1)
+		GROUP BY year
+		ORDER BY year DESC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -387,3 +414,88 @@ func (m AnalyticsModel) GetBatches() ([]Batch, error) {
 
 	return batches, rows.Err()
 }
+
+// GetInstitutionTotals counts students, completed profiles and accepted
+// placements for every institution
+func (m AnalyticsModel) GetInstitutionTotals(ctx context.Context) ([]InstitutionTotals, error) {
+	query := `
+		SELECT i.code,
+		       (SELECT COUNT(*) FROM students s WHERE s.institution_id = i.id),
+		       (SELECT COUNT(*) FROM students s WHERE s.institution_id = i.id AND s.is_profile_completed = true),
+		       (SELECT COUNT(*) FROM placements p JOIN students s ON p.student_id = s.id
+		        WHERE s.institution_id = i.id AND p.is_accepted = true)
+		FROM institutions i
+		ORDER BY i.code`
+
+	ctx, cancel := m.DB.withTimeout(ctx, AnalyticsQuery)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []InstitutionTotals
+	for rows.Next() {
+		var t InstitutionTotals
+		if err := rows.Scan(&t.InstitutionCode, &t.StudentsRegistered, &t.ProfilesCompleted, &t.PlacementsRecorded); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
+
+// SaveDashboardSnapshot records an institution's dashboard for a day,
+// replacing any snapshot already taken that day
+func (m AnalyticsModel) SaveDashboardSnapshot(ctx context.Context, institutionID int64, day time.Time, stats *DashboardStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO dashboard_snapshots (institution_id, taken_on, stats)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (institution_id, taken_on) DO UPDATE SET stats = EXCLUDED.stats, created_at = NOW()`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	_, err = m.DB.ExecContext(ctx, query, institutionID, day.Format(time.DateOnly), data)
+	return err
+}
+
+// GetDashboardSnapshots returns an institution's snapshots from since onwards, oldest first
+func (m AnalyticsModel) GetDashboardSnapshots(ctx context.Context, institutionID int64, since time.Time) ([]DashboardSnapshot, error) {
+	query := `
+		SELECT taken_on, stats FROM dashboard_snapshots
+		WHERE institution_id = $1 AND taken_on >= $2
+		ORDER BY taken_on`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, institutionID, since.Format(time.DateOnly))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []DashboardSnapshot{}
+	for rows.Next() {
+		var snap DashboardSnapshot
+		var data []byte
+		if err := rows.Scan(&snap.TakenOn, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &snap.Stats); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,294 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================
+// ANALYTICS CACHE
+// ============================================
+
+// AnalyticsCache keeps the results of the aggregate analytics queries so a
+// dashboard refreshed by many coordinators is computed once. Results are
+// keyed by query, institution, department and batch filter. Every write to students,
+// placements, companies or skills made through WithAnalyticsCache's stores
+// drops all of them, and TTL bounds how stale a result can be after writes
+// made elsewhere, such as by another replica.
+//
+// Cached results are shared between callers, who must not modify them.
+type AnalyticsCache struct {
+	AnalyticsStore // queries that are not cached pass straight through
+	TTL            time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+
+	hits, misses atomic.Uint64
+}
+
+// cacheEntry is one cached result. ready is closed once the first caller's
+// query has finished; callers arriving before then wait for it rather than
+// running the same query again.
+type cacheEntry struct {
+	ready   chan struct{}
+	expires time.Time
+	value   any
+	err     error
+}
+
+func NewAnalyticsCache(store AnalyticsStore, ttl time.Duration) *AnalyticsCache {
+	return &AnalyticsCache{
+		AnalyticsStore: store,
+		TTL:            ttl,
+		entries:        make(map[string]*cacheEntry),
+	}
+}
+
+// Invalidate drops every cached result
+func (c *AnalyticsCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*cacheEntry)
+}
+
+// Stats returns how many lookups were answered from the cache and how many ran the query
+func (c *AnalyticsCache) Stats() (hits, misses uint64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *AnalyticsCache) GetDashboardStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) (*DashboardStats, error) {
+	return cached(ctx, c, cacheKey("dashboard", institutionID, department, batchYear), func(ctx context.Context) (*DashboardStats, error) {
+		return c.AnalyticsStore.GetDashboardStats(ctx, institutionID, department, batchYear)
+	})
+}
+
+func (c *AnalyticsCache) GetBatchWiseStats(ctx context.Context, institutionID *int64, department *string) ([]BatchStats, error) {
+	return cached(ctx, c, cacheKey("batch", institutionID, department, nil), func(ctx context.Context) ([]BatchStats, error) {
+		return c.AnalyticsStore.GetBatchWiseStats(ctx, institutionID, department)
+	})
+}
+
+func (c *AnalyticsCache) GetSkillStats(ctx context.Context, institutionID *int64, department *string) ([]SkillStats, error) {
+	return cached(ctx, c, cacheKey("skills", institutionID, department, nil), func(ctx context.Context) ([]SkillStats, error) {
+		return c.AnalyticsStore.GetSkillStats(ctx, institutionID, department)
+	})
+}
+
+func (c *AnalyticsCache) GetCGPADistribution(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CGPADistribution, error) {
+	return cached(ctx, c, cacheKey("cgpa", institutionID, department, batchYear), func(ctx context.Context) ([]CGPADistribution, error) {
+		return c.AnalyticsStore.GetCGPADistribution(ctx, institutionID, department, batchYear)
+	})
+}
+
+func (c *AnalyticsCache) GetCompanyStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CompanyStats, error) {
+	return cached(ctx, c, cacheKey("companies", institutionID, department, batchYear), func(ctx context.Context) ([]CompanyStats, error) {
+		return c.AnalyticsStore.GetCompanyStats(ctx, institutionID, department, batchYear)
+	})
+}
+
+// cacheKey names a query and its filters; nil filters are written as "all".
+// The department is quoted since it is free text and may contain a slash.
+func cacheKey(query string, institutionID *int64, department *string, batchYear *int) string {
+	inst, dept, batch := "all", "all", "all"
+	if institutionID != nil {
+		inst = fmt.Sprint(*institutionID)
+	}
+	if department != nil {
+		dept = strconv.Quote(*department)
+	}
+	if batchYear != nil {
+		batch = fmt.Sprint(*batchYear)
+	}
+	return query + "/" + inst + "/" + dept + "/" + batch
+}
+
+// cached returns the result stored under key, running load when there is
+// none or it has expired. Errors are not cached.
+func cached[T any](ctx context.Context, c *AnalyticsCache, key string, load func(context.Context) (T, error)) (T, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok && !e.expired(time.Now()) {
+		c.mu.Unlock()
+		c.hits.Add(1)
+
+		select {
+		case <-e.ready:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if e.err == nil {
+			return e.value.(T), nil
+		}
+		// The query being waited on failed; run it again for this caller
+		return load(ctx)
+	}
+
+	e := &cacheEntry{ready: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	// Other callers may be waiting on this query, so it must not stop
+	// because the request that started it went away
+	value, err := load(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	e.value, e.err = value, err
+	e.expires = time.Now().Add(c.TTL)
+	if err != nil && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.ready)
+
+	return value, err
+}
+
+// expired reports whether the entry's result is too old. An entry still
+// being computed has not expired. The caller holds c.mu.
+func (e *cacheEntry) expired(now time.Time) bool {
+	select {
+	case <-e.ready:
+		return !now.Before(e.expires)
+	default:
+		return false
+	}
+}
+
+// ============================================
+// INVALIDATING STORES
+// ============================================
+
+// WithAnalyticsCache puts an AnalyticsCache in front of m.Analytics and wraps
+// the student, placement, company and skill stores so their writes drop it
+func WithAnalyticsCache(m Models, ttl time.Duration) (Models, *AnalyticsCache) {
+	cache := NewAnalyticsCache(m.Analytics, ttl)
+	m.Analytics = cache
+	m.Students = invalidatingStudents{m.Students, cache}
+	m.Placements = invalidatingPlacements{m.Placements, cache}
+	m.Companies = invalidatingCompanies{m.Companies, cache}
+	m.Skills = invalidatingSkills{m.Skills, cache}
+	return m, cache
+}
+
+// invalidate drops the cache after a write, whether or not it succeeded; a
+// failed write may still have changed something
+func invalidate(cache *AnalyticsCache, err error) error {
+	cache.Invalidate()
+	return err
+}
+
+// invalidatingStudents overrides the writes of a StudentStore; reads pass through
+type invalidatingStudents struct {
+	StudentStore
+	cache *AnalyticsCache
+}
+
+func (s invalidatingStudents) Insert(ctx context.Context, student *Student) error {
+	return invalidate(s.cache, s.StudentStore.Insert(ctx, student))
+}
+
+func (s invalidatingStudents) UpdateBasicInfo(ctx context.Context, student *Student) error {
+	return invalidate(s.cache, s.StudentStore.UpdateBasicInfo(ctx, student))
+}
+
+func (s invalidatingStudents) SetProfileCompleted(ctx context.Context, studentID int64) error {
+	return invalidate(s.cache, s.StudentStore.SetProfileCompleted(ctx, studentID))
+}
+
+func (s invalidatingStudents) UpdatePlacementStatus(ctx context.Context, studentID int64, status PlacementStatus) error {
+	return invalidate(s.cache, s.StudentStore.UpdatePlacementStatus(ctx, studentID, status))
+}
+
+func (s invalidatingStudents) UpsertPersonalDetails(ctx context.Context, details *StudentPersonalDetails) error {
+	return invalidate(s.cache, s.StudentStore.UpsertPersonalDetails(ctx, details))
+}
+
+func (s invalidatingStudents) UpsertFamilyDetails(ctx context.Context, details *StudentFamilyDetails) error {
+	return invalidate(s.cache, s.StudentStore.UpsertFamilyDetails(ctx, details))
+}
+
+func (s invalidatingStudents) UpsertAcademics(ctx context.Context, a *StudentAcademics) error {
+	return invalidate(s.cache, s.StudentStore.UpsertAcademics(ctx, a))
+}
+
+func (s invalidatingStudents) SyncAcademics(ctx context.Context, rec *AcademicSync) (int64, error) {
+	id, err := s.StudentStore.SyncAcademics(ctx, rec)
+	return id, invalidate(s.cache, err)
+}
+
+func (s invalidatingStudents) UpsertAchievements(ctx context.Context, a *StudentAchievements) error {
+	return invalidate(s.cache, s.StudentStore.UpsertAchievements(ctx, a))
+}
+
+func (s invalidatingStudents) UpsertAspirations(ctx context.Context, a *StudentAspirations) error {
+	return invalidate(s.cache, s.StudentStore.UpsertAspirations(ctx, a))
+}
+
+func (s invalidatingStudents) UpsertSkills(ctx context.Context, studentID int64, skills []StudentSkill) error {
+	return invalidate(s.cache, s.StudentStore.UpsertSkills(ctx, studentID, skills))
+}
+
+func (s invalidatingStudents) RecomputeEligibility(ctx context.Context, rule EligibilityRule) (int64, error) {
+	changed, err := s.StudentStore.RecomputeEligibility(ctx, rule)
+	if changed == 0 && err == nil {
+		return 0, nil
+	}
+	return changed, invalidate(s.cache, err)
+}
+
+// invalidatingPlacements overrides the writes of a PlacementStore
+type invalidatingPlacements struct {
+	PlacementStore
+	cache *AnalyticsCache
+}
+
+func (s invalidatingPlacements) Insert(ctx context.Context, p *PlacementRecord) error {
+	return invalidate(s.cache, s.PlacementStore.Insert(ctx, p))
+}
+
+func (s invalidatingPlacements) Update(ctx context.Context, p *PlacementRecord) error {
+	return invalidate(s.cache, s.PlacementStore.Update(ctx, p))
+}
+
+func (s invalidatingPlacements) Delete(ctx context.Context, id int64) error {
+	return invalidate(s.cache, s.PlacementStore.Delete(ctx, id))
+}
+
+func (s invalidatingPlacements) Verify(ctx context.Context, id int64, adminID int64) error {
+	return invalidate(s.cache, s.PlacementStore.Verify(ctx, id, adminID))
+}
+
+// invalidatingCompanies overrides the writes of a CompanyStore
+type invalidatingCompanies struct {
+	CompanyStore
+	cache *AnalyticsCache
+}
+
+func (s invalidatingCompanies) Insert(ctx context.Context, c *Company) error {
+	return invalidate(s.cache, s.CompanyStore.Insert(ctx, c))
+}
+
+func (s invalidatingCompanies) Update(ctx context.Context, c *Company) error {
+	return invalidate(s.cache, s.CompanyStore.Update(ctx, c))
+}
+
+func (s invalidatingCompanies) Delete(ctx context.Context, institutionID *int64, id int64) error {
+	return invalidate(s.cache, s.CompanyStore.Delete(ctx, institutionID, id))
+}
+
+// invalidatingSkills overrides the one write of a SkillStore; new skills
+// appear in the skill stats
+type invalidatingSkills struct {
+	SkillStore
+	cache *AnalyticsCache
+}
+
+func (s invalidatingSkills) Insert(ctx context.Context, skill *Skill) error {
+	return invalidate(s.cache, s.SkillStore.Insert(ctx, skill))
+}
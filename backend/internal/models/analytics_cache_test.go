@@ -0,0 +1,181 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+)
+
+func TestAnalyticsCacheInvalidation(t *testing.T) {
+	ctx := t.Context()
+	m, cache := models.WithAnalyticsCache(memstore.New().Models(), time.Hour)
+	c := newCohort(t, m)
+
+	totalStudents := func() int {
+		t.Helper()
+		stats, err := m.Analytics.GetDashboardStats(ctx, &c.inst, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return stats.TotalStudents
+	}
+
+	if got := totalStudents(); got != 4 {
+		t.Fatalf("total students = %d, want 4", got)
+	}
+	totalStudents()
+	if hits, misses := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("after two loads: %d hits, %d misses; want 1 and 1", hits, misses)
+	}
+
+	// Each filter is cached on its own
+	stats, err := m.Analytics.GetDashboardStats(ctx, &c.inst, nil, ptr(2025))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalStudents != 1 {
+		t.Errorf("2025 total students = %d, want 1", stats.TotalStudents)
+	}
+
+	newStudent(t, m, c.inst, "Ezhil")
+	if got := totalStudents(); got != 5 {
+		t.Errorf("after a new student: total students = %d, want 5", got)
+	}
+
+	placements, err := m.Placements.GetAll(ctx, &c.inst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range placements {
+		if p.CompanyName == "Acme" {
+			if err := m.Placements.Delete(ctx, p.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	companies, err := m.Analytics.GetCompanyStats(ctx, &c.inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 1 || companies[0].CompanyName != "Globex" {
+		t.Errorf("after deleting the Acme placement: company stats = %+v", companies)
+	}
+
+	stats, err = m.Analytics.GetDashboardStats(ctx, &c.inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Companies.Insert(ctx, &models.Company{InstitutionID: c.inst, Name: "Initech"}); err != nil {
+		t.Fatal(err)
+	}
+	after, err := m.Analytics.GetDashboardStats(ctx, &c.inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.TotalCompanies != stats.TotalCompanies+1 {
+		t.Errorf("after a new company: total companies = %d, want %d", after.TotalCompanies, stats.TotalCompanies+1)
+	}
+
+	// Reads leave the cache alone
+	_, missesBefore := cache.Stats()
+	if _, err := m.Students.List(ctx, models.StudentFilter{InstitutionID: &c.inst}); err != nil {
+		t.Fatal(err)
+	}
+	totalStudents()
+	if _, misses := cache.Stats(); misses != missesBefore {
+		t.Errorf("a student list cleared the cache")
+	}
+}
+
+// countingAnalytics counts dashboard queries. Queries block until release is
+// closed, and the first fails if failFirst is set.
+type countingAnalytics struct {
+	models.AnalyticsStore
+	calls     atomic.Int32
+	release   chan struct{}
+	failFirst bool
+}
+
+func (a *countingAnalytics) GetDashboardStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) (*models.DashboardStats, error) {
+	n := a.calls.Add(1)
+	if a.release != nil {
+		<-a.release
+	}
+	if a.failFirst && n == 1 {
+		return nil, errors.New("connection reset")
+	}
+	return &models.DashboardStats{TotalStudents: int(n)}, nil
+}
+
+func TestAnalyticsCacheSharesQueries(t *testing.T) {
+	store := &countingAnalytics{release: make(chan struct{})}
+	cache := models.NewAnalyticsCache(store, time.Hour)
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Go(func() {
+			stats, err := cache.GetDashboardStats(t.Context(), nil, nil, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = stats.TotalStudents
+		})
+	}
+
+	// Let every caller reach the cache before the query finishes
+	for {
+		hits, misses := cache.Stats()
+		if hits+misses == 5 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(store.release)
+	wg.Wait()
+
+	if n := store.calls.Load(); n != 1 {
+		t.Errorf("five concurrent loads ran %d queries, want 1", n)
+	}
+	for i, got := range results {
+		if got != 1 {
+			t.Errorf("caller %d got result %d, want the shared 1", i, got)
+		}
+	}
+}
+
+func TestAnalyticsCacheErrorsAndExpiry(t *testing.T) {
+	ctx := t.Context()
+
+	store := &countingAnalytics{failFirst: true}
+	cache := models.NewAnalyticsCache(store, time.Hour)
+	if _, err := cache.GetDashboardStats(ctx, nil, nil, nil); err == nil {
+		t.Fatal("the first query should fail")
+	}
+	stats, err := cache.GetDashboardStats(ctx, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("a failed query was cached: %v", err)
+	}
+	if stats.TotalStudents != 2 {
+		t.Errorf("got result %d, want 2 from a fresh query", stats.TotalStudents)
+	}
+
+	// With a TTL this short every load finds the last result expired
+	store = &countingAnalytics{}
+	cache = models.NewAnalyticsCache(store, time.Nanosecond)
+	for range 3 {
+		if _, err := cache.GetDashboardStats(ctx, nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := store.calls.Load(); n != 3 {
+		t.Errorf("expired results: ran %d queries, want 3", n)
+	}
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// APIKey is a credential for a service account. The key itself is never stored.
+type APIKey struct {
+	ID                 int64      `json:"id"`
+	InstitutionID      int64      `json:"institution_id"`
+	Name               string     `json:"name"`
+	Prefix             string     `json:"prefix"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+	LastUsedIP         *string    `json:"last_used_ip"`
+	CreatedBy          *int64     `json:"created_by"`
+	RevokedAt          *time.Time `json:"revoked_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+type APIKeyModel struct {
+	DB *DB
+}
+
+const apiKeyColumns = `id, institution_id, name, prefix, scopes, rate_limit_per_minute, expires_at,
+	last_used_at, last_used_ip, created_by, revoked_at, created_at`
+
+// Insert stores a new key under the hash of its secret
+func (m APIKeyModel) Insert(ctx context.Context, key *APIKey, keyHash []byte) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO api_keys (institution_id, name, prefix, key_hash, scopes, rate_limit_per_minute, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query,
+		key.InstitutionID, key.Name, key.Prefix, keyHash, scopes, key.RateLimitPerMinute, key.ExpiresAt, key.CreatedBy,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetActiveByHash finds a key that is neither revoked nor expired
+func (m APIKeyModel) GetActiveByHash(ctx context.Context, keyHash []byte) (*APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	key, err := scanAPIKey(m.DB.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// GetAll lists an institution's keys, or every key when institutionID is nil, newest first
+func (m APIKeyModel) GetAll(ctx context.Context, institutionID *int64) ([]*APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE ($1::int IS NULL OR institution_id = $1)
+		ORDER BY created_at DESC`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, institutionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke disables a key immediately. With an institutionID, keys of other
+// institutions are reported as not found.
+func (m APIKeyModel) Revoke(ctx context.Context, institutionID *int64, id int64) error {
+	query := `
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL AND ($2::int IS NULL OR institution_id = $2)`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, institutionID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed records key usage, at most once a minute to spare the table
+// from a write on every request
+func (m APIKeyModel) TouchLastUsed(ctx context.Context, id int64, ip string) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = NOW(), last_used_ip = NULLIF($2, '')
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, id, ip)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var key APIKey
+	var scopes []byte
+
+	err := row.Scan(
+		&key.ID, &key.InstitutionID, &key.Name, &key.Prefix, &scopes, &key.RateLimitPerMinute, &key.ExpiresAt,
+		&key.LastUsedAt, &key.LastUsedIP, &key.CreatedBy, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(scopes, &key.Scopes); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// AuthCode is a one-time code handed to the frontend after an OAuth login
+type AuthCode struct {
+	UserType  string
+	UserID    int64
+	ExpiresAt time.Time
+}
+
+type AuthCodeModel struct {
+	DB *DB
+}
+
+// Insert stores the hash of a new login code
+func (m AuthCodeModel) Insert(ctx context.Context, codeHash []byte, code *AuthCode) error {
+	query := `
+		INSERT INTO auth_codes (code_hash, user_type, user_id, expires_at)
+		VALUES ($1, $2, $3, $4)`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, query, codeHash, code.UserType, code.UserID, code.ExpiresAt)
+	return err
+}
+
+// Consume deletes a code and returns it, so a code can only ever be used once.
+// Unknown and expired codes both return ErrRecordNotFound.
+func (m AuthCodeModel) Consume(ctx context.Context, codeHash []byte) (*AuthCode, error) {
+	query := `
+		DELETE FROM auth_codes
+		WHERE code_hash = $1
+		RETURNING user_type, user_id, expires_at`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	var code AuthCode
+	err := m.DB.QueryRowContext(ctx, query, codeHash).Scan(&code.UserType, &code.UserID, &code.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if time.Now().After(code.ExpiresAt) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &code, nil
+}
+
+// DeleteExpired removes codes that were never exchanged
+func (m AuthCodeModel) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM auth_codes WHERE expires_at < $1`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
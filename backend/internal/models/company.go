@@ -9,41 +9,43 @@ import (
 
 // Company represents a recruiting company
 type Company struct {
-	ID           int64     `json:"id"`
-	Name         string    `json:"name"`
-	Website      *string   `json:"website"`
-	Industry     *string   `json:"industry"`
-	CompanyType  *string   `json:"company_type"` // product, service, startup, mnc
-	Description  *string   `json:"description"`
-	LogoURL      *string   `json:"logo_url"`
-	HRName       *string   `json:"hr_name"`
-	HREmail      *string   `json:"hr_email"`
-	HRPhone      *string   `json:"hr_phone"`
-	Headquarters *string   `json:"headquarters"`
-	Locations    *string   `json:"locations"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID            int64     `json:"id"`
+	InstitutionID int64     `json:"institution_id"`
+	Name          string    `json:"name"`
+	Website       *string   `json:"website"`
+	Industry      *string   `json:"industry"`
+	CompanyType   *string   `json:"company_type"` // product, service, startup, mnc
+	Description   *string   `json:"description"`
+	LogoURL       *string   `json:"logo_url"`
+	HRName        *string   `json:"hr_name"`
+	HREmail       *string   `json:"hr_email"`
+	HRPhone       *string   `json:"hr_phone"`
+	Headquarters  *string   `json:"headquarters"`
+	Locations     *string   `json:"locations"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type CompanyModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
-// GetAll retrieves all companies
-func (m CompanyModel) GetAll() ([]Company, error) {
+// GetAll retrieves all active companies of an institution, or of every
+// institution when institutionID is nil
+func (m CompanyModel) GetAll(ctx context.Context, institutionID *int64) ([]Company, error) {
 	query := `
-		SELECT id, name, website, industry, company_type, description, logo_url,
+		SELECT id, institution_id, name, website, industry, company_type, description, logo_url,
 		       hr_name, hr_email, hr_phone, headquarters, locations, is_active,
 		       created_at, updated_at
 		FROM companies
-		WHERE is_active = true
+		WHERE is_active = true AND ($1::int IS NULL OR institution_id = $1)
 		ORDER BY name ASC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +55,7 @@ func (m CompanyModel) GetAll() ([]Company, error) {
 	for rows.Next() {
 		var c Company
 		if err := rows.Scan(
-			&c.ID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
+			&c.ID, &c.InstitutionID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
 			&c.LogoURL, &c.HRName, &c.HREmail, &c.HRPhone, &c.Headquarters, &c.Locations,
 			&c.IsActive, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
@@ -66,20 +68,20 @@ func (m CompanyModel) GetAll() ([]Company, error) {
 }
 
 // GetByID retrieves a company by ID
-func (m CompanyModel) GetByID(id int64) (*Company, error) {
+func (m CompanyModel) GetByID(ctx context.Context, id int64) (*Company, error) {
 	query := `
-		SELECT id, name, website, industry, company_type, description, logo_url,
+		SELECT id, institution_id, name, website, industry, company_type, description, logo_url,
 		       hr_name, hr_email, hr_phone, headquarters, locations, is_active,
 		       created_at, updated_at
 		FROM companies
 		WHERE id = $1`
 
 	var c Company
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&c.ID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
+		&c.ID, &c.InstitutionID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
 		&c.LogoURL, &c.HRName, &c.HREmail, &c.HRPhone, &c.Headquarters, &c.Locations,
 		&c.IsActive, &c.CreatedAt, &c.UpdatedAt,
 	)
@@ -95,24 +97,24 @@ func (m CompanyModel) GetByID(id int64) (*Company, error) {
 }
 
 // Insert creates a new company
-func (m CompanyModel) Insert(c *Company) error {
+func (m CompanyModel) Insert(ctx context.Context, c *Company) error {
 	query := `
-		INSERT INTO companies (name, website, industry, company_type, description, logo_url,
+		INSERT INTO companies (institution_id, name, website, industry, company_type, description, logo_url,
 		                       hr_name, hr_email, hr_phone, headquarters, locations)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id, created_at, updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	return m.DB.QueryRowContext(ctx, query,
-		c.Name, c.Website, c.Industry, c.CompanyType, c.Description, c.LogoURL,
+		c.InstitutionID, c.Name, c.Website, c.Industry, c.CompanyType, c.Description, c.LogoURL,
 		c.HRName, c.HREmail, c.HRPhone, c.Headquarters, c.Locations,
 	).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
 }
 
 // Update updates a company
-func (m CompanyModel) Update(c *Company) error {
+func (m CompanyModel) Update(ctx context.Context, c *Company) error {
 	query := `
 		UPDATE companies
 		SET name = $1, website = $2, industry = $3, company_type = $4, description = $5,
@@ -121,7 +123,7 @@ func (m CompanyModel) Update(c *Company) error {
 		WHERE id = $13
 		RETURNING updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	return m.DB.QueryRowContext(ctx, query,
@@ -130,21 +132,21 @@ func (m CompanyModel) Update(c *Company) error {
 	).Scan(&c.UpdatedAt)
 }
 
-// Search searches companies by name
-func (m CompanyModel) Search(search string) ([]Company, error) {
+// Search searches an institution's companies by name; nil searches every institution
+func (m CompanyModel) Search(ctx context.Context, institutionID *int64, search string) ([]Company, error) {
 	query := `
-		SELECT id, name, website, industry, company_type, description, logo_url,
+		SELECT id, institution_id, name, website, industry, company_type, description, logo_url,
 		       hr_name, hr_email, hr_phone, headquarters, locations, is_active,
 		       created_at, updated_at
 		FROM companies
-		WHERE name ILIKE $1 AND is_active = true
+		WHERE name ILIKE $1 AND is_active = true AND ($2::int IS NULL OR institution_id = $2)
 		ORDER BY name ASC
 		LIMIT 20`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, "%"+search+"%")
+	rows, err := m.DB.QueryContext(ctx, query, "%"+search+"%", institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +156,7 @@ func (m CompanyModel) Search(search string) ([]Company, error) {
 	for rows.Next() {
 		var c Company
 		if err := rows.Scan(
-			&c.ID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
+			&c.ID, &c.InstitutionID, &c.Name, &c.Website, &c.Industry, &c.CompanyType, &c.Description,
 			&c.LogoURL, &c.HRName, &c.HREmail, &c.HRPhone, &c.Headquarters, &c.Locations,
 			&c.IsActive, &c.CreatedAt, &c.UpdatedAt,
 		); err != nil {
@@ -166,14 +168,15 @@ func (m CompanyModel) Search(search string) ([]Company, error) {
 	return companies, rows.Err()
 }
 
-// Delete deletes a company by ID
-func (m CompanyModel) Delete(id int64) error {
-	query := `DELETE FROM companies WHERE id = $1`
+// Delete deletes a company by ID. With an institutionID, companies of other
+// institutions are reported as not found.
+func (m CompanyModel) Delete(ctx context.Context, institutionID *int64, id int64) error {
+	query := `DELETE FROM companies WHERE id = $1 AND ($2::int IS NULL OR institution_id = $2)`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id)
+	result, err := m.DB.ExecContext(ctx, query, id, institutionID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// QueryClass groups model calls that share a timeout
+type QueryClass int
+
+const (
+	ReadQuery QueryClass = iota
+	WriteQuery
+	AnalyticsQuery
+	ExportQuery
+)
+
+func (c QueryClass) String() string {
+	switch c {
+	case WriteQuery:
+		return "write"
+	case AnalyticsQuery:
+		return "analytics"
+	case ExportQuery:
+		return "export"
+	}
+	return "read"
+}
+
+// Timeouts bound how long one model call may run, by query class
+type Timeouts struct {
+	Read      time.Duration
+	Write     time.Duration
+	Analytics time.Duration
+	Export    time.Duration
+}
+
+// DefaultTimeouts apply to any class left at zero
+var DefaultTimeouts = Timeouts{
+	Read:      5 * time.Second,
+	Write:     5 * time.Second,
+	Analytics: 10 * time.Second,
+	Export:    30 * time.Second,
+}
+
+// DB is the connection pool the Postgres models query through. Every model
+// call runs under the caller's context, so a request that is cancelled or
+// whose client disconnects stops its query, bounded further by the timeout
+// of the call's class.
+type DB struct {
+	*sql.DB
+	Timeouts Timeouts
+	// SlowQuery is the duration above which a model call is logged; 0
+	// disables the log
+	SlowQuery time.Duration
+	// Logger receives slow query warnings with the caller's context, so the
+	// request ID is attached
+	Logger *slog.Logger
+}
+
+// withTimeout bounds ctx by the timeout of class. The returned func must be
+// deferred: it releases the context and logs the call if it was slow.
+func (db *DB) withTimeout(ctx context.Context, class QueryClass) (context.Context, func()) {
+	queryCtx, cancel := context.WithTimeout(ctx, db.timeout(class))
+	if db.SlowQuery <= 0 || db.Logger == nil {
+		return queryCtx, cancel
+	}
+
+	start := time.Now()
+	pc, _, _, _ := runtime.Caller(1)
+	return queryCtx, func() {
+		cancel()
+		if elapsed := time.Since(start); elapsed >= db.SlowQuery {
+			db.Logger.WarnContext(ctx, "slow query",
+				"call", callerName(pc), "class", class.String(), "duration_ms", elapsed.Milliseconds())
+		}
+	}
+}
+
+func (db *DB) timeout(class QueryClass) time.Duration {
+	var d, fallback time.Duration
+	switch class {
+	case WriteQuery:
+		d, fallback = db.Timeouts.Write, DefaultTimeouts.Write
+	case AnalyticsQuery:
+		d, fallback = db.Timeouts.Analytics, DefaultTimeouts.Analytics
+	case ExportQuery:
+		d, fallback = db.Timeouts.Export, DefaultTimeouts.Export
+	default:
+		d, fallback = db.Timeouts.Read, DefaultTimeouts.Read
+	}
+	if d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// callerName turns a program counter into e.g. StudentModel.List
+func callerName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	return strings.TrimPrefix(name, "models.")
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	db := &DB{Timeouts: Timeouts{Analytics: time.Minute}}
+
+	tests := map[QueryClass]time.Duration{
+		AnalyticsQuery: time.Minute,
+		ReadQuery:      DefaultTimeouts.Read, // left at zero
+		ExportQuery:    DefaultTimeouts.Export,
+	}
+	for class, want := range tests {
+		ctx, done := db.withTimeout(context.Background(), class)
+		deadline, _ := ctx.Deadline()
+		done()
+		if got := time.Until(deadline); got > want || got < want-time.Second {
+			t.Errorf("%s: deadline in %v, want %v", class, got, want)
+		}
+	}
+
+	// Cancelling the request cancels the query
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, done := db.withTimeout(parent, ReadQuery)
+	defer done()
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("query context outlived the request")
+	}
+}
+
+type slowModel struct{ DB *DB }
+
+func (m slowModel) Search(ctx context.Context) {
+	_, done := m.DB.withTimeout(ctx, ReadQuery)
+	defer done()
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	db := &DB{SlowQuery: time.Millisecond, Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+
+	slowModel{db}.Search(context.Background())
+
+	var entry struct {
+		Msg, Call, Class string
+		DurationMS       int64 `json:"duration_ms"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("no slow query log: %v (%q)", err, buf.String())
+	}
+	if entry.Msg != "slow query" || entry.Call != "slowModel.Search" || entry.Class != "read" || entry.DurationMS < 5 {
+		t.Errorf("log entry = %+v", entry)
+	}
+
+	buf.Reset()
+	db.SlowQuery = time.Hour
+	slowModel{db}.Search(context.Background())
+	if buf.Len() > 0 {
+		t.Errorf("fast call logged: %s", buf.String())
+	}
+}
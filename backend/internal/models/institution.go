@@ -0,0 +1,215 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Institution is one college in the group. All students, admins, batches,
+// skills, companies and API keys belong to exactly one institution.
+type Institution struct {
+	ID           int64     `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	Domains      []string  `json:"domains"`
+	LogoURL      *string   `json:"logo_url"`
+	PrimaryColor *string   `json:"primary_color"`
+	IsActive     bool      `json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type InstitutionModel struct {
+	DB *DB
+}
+
+const institutionColumns = `
+	i.id, i.code, i.name,
+	COALESCE((SELECT string_agg(d.domain, ',' ORDER BY d.domain) FROM institution_domains d WHERE d.institution_id = i.id), ''),
+	i.logo_url, i.primary_color, i.is_active, i.created_at, i.updated_at`
+
+// GetByID retrieves an institution, active or not
+func (m InstitutionModel) GetByID(ctx context.Context, id int64) (*Institution, error) {
+	query := `SELECT ` + institutionColumns + ` FROM institutions i WHERE i.id = $1`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	return scanInstitution(m.DB.QueryRowContext(ctx, query, id))
+}
+
+// GetByDomain resolves the active institution that owns an email domain
+func (m InstitutionModel) GetByDomain(ctx context.Context, domain string) (*Institution, error) {
+	query := `
+		SELECT ` + institutionColumns + `
+		FROM institutions i
+		JOIN institution_domains dom ON dom.institution_id = i.id
+		WHERE dom.domain = $1 AND i.is_active = true`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	return scanInstitution(m.DB.QueryRowContext(ctx, query, strings.ToLower(domain)))
+}
+
+// GetAll lists every institution
+func (m InstitutionModel) GetAll(ctx context.Context) ([]*Institution, error) {
+	query := `SELECT ` + institutionColumns + ` FROM institutions i ORDER BY i.name ASC`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	institutions := []*Institution{}
+	for rows.Next() {
+		inst, err := scanInstitution(rows)
+		if err != nil {
+			return nil, err
+		}
+		institutions = append(institutions, inst)
+	}
+
+	return institutions, rows.Err()
+}
+
+// Insert creates an institution with its domains. The skill catalogue and
+// batch years of the oldest institution are copied so the new one is usable
+// straight away.
+func (m InstitutionModel) Insert(ctx context.Context, inst *Institution) error {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO institutions (code, name, logo_url, primary_color)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, is_active, created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query, inst.Code, inst.Name, inst.LogoURL, inst.PrimaryColor).
+		Scan(&inst.ID, &inst.IsActive, &inst.CreatedAt, &inst.UpdatedAt)
+	if err != nil {
+		return institutionError(err)
+	}
+
+	if err := replaceDomains(ctx, tx, inst); err != nil {
+		return err
+	}
+
+	seed := `
+		INSERT INTO skills (institution_id, name, category, description, display_order)
+		SELECT $1, name, category, description, display_order
+		FROM skills
+		WHERE is_active = true AND institution_id = (SELECT MIN(id) FROM institutions)`
+	if _, err := tx.ExecContext(ctx, seed, inst.ID); err != nil {
+		return err
+	}
+
+	seed = `
+		INSERT INTO batches (institution_id, year)
+		SELECT $1, year
+		FROM batches
+		WHERE is_active = true AND institution_id = (SELECT MIN(id) FROM institutions)`
+	if _, err := tx.ExecContext(ctx, seed, inst.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Update changes an institution's details and replaces its domains
+func (m InstitutionModel) Update(ctx context.Context, inst *Institution) error {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE institutions
+		SET name = $1, logo_url = $2, primary_color = $3, is_active = $4, updated_at = NOW()
+		WHERE id = $5
+		RETURNING updated_at`
+
+	err = tx.QueryRowContext(ctx, query, inst.Name, inst.LogoURL, inst.PrimaryColor, inst.IsActive, inst.ID).
+		Scan(&inst.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	if err := replaceDomains(ctx, tx, inst); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceDomains(ctx context.Context, tx *sql.Tx, inst *Institution) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM institution_domains WHERE institution_id = $1`, inst.ID); err != nil {
+		return err
+	}
+
+	for _, domain := range inst.Domains {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO institution_domains (domain, institution_id) VALUES ($1, $2)`,
+			strings.ToLower(domain), inst.ID,
+		)
+		if err != nil {
+			return institutionError(err)
+		}
+	}
+
+	return nil
+}
+
+func institutionError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "institutions_code_key"):
+		return ErrDuplicateCode
+	case strings.Contains(err.Error(), "institution_domains_pkey"):
+		return ErrDuplicateDomain
+	default:
+		return err
+	}
+}
+
+func scanInstitution(row rowScanner) (*Institution, error) {
+	var inst Institution
+	var domains string
+
+	err := row.Scan(
+		&inst.ID, &inst.Code, &inst.Name, &domains,
+		&inst.LogoURL, &inst.PrimaryColor, &inst.IsActive, &inst.CreatedAt, &inst.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	inst.Domains = []string{}
+	if domains != "" {
+		inst.Domains = strings.Split(domains, ",")
+	}
+
+	return &inst, nil
+}
@@ -0,0 +1,378 @@
+package memstore
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+// ============================================
+// ADMINS
+// ============================================
+
+type adminStore struct{ s *Store }
+
+var _ models.AdminStore = adminStore{}
+
+func (m adminStore) GetByEmail(_ context.Context, email string) (*models.Admin, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, a := range m.s.admins {
+		if a.Email == email && a.IsActive {
+			return clone(a), nil
+		}
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m adminStore) GetByID(_ context.Context, id int64) (*models.Admin, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	a, ok := m.s.admins[id]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	return clone(a), nil
+}
+
+func (m adminStore) GetAll(_ context.Context, institutionID *int64) ([]models.Admin, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	byName := func(a, b *models.Admin) int { return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID)) }
+
+	var admins []models.Admin
+	for _, a := range sorted(m.s.admins, byName) {
+		if inInstitution(institutionID, a.InstitutionID) {
+			admins = append(admins, *a)
+		}
+	}
+	return admins, nil
+}
+
+func (m adminStore) Insert(_ context.Context, admin *models.Admin) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, a := range m.s.admins {
+		if a.Email == admin.Email {
+			return models.ErrDuplicateEmail
+		}
+	}
+
+	now := time.Now()
+	admin.ID = m.s.nextID()
+	admin.IsActive = true
+	admin.CreatedAt, admin.UpdatedAt = now, now
+	m.s.admins[admin.ID] = clone(admin)
+	return nil
+}
+
+// Update refuses to demote or deactivate the last active super-admin of an
+// institution, like AdminModel.Update
+func (m adminStore) Update(_ context.Context, admin *models.Admin) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if admin.Role != "super_admin" || !admin.IsActive {
+		var superAdmins []int64
+		for _, a := range m.s.admins {
+			if a.Role == "super_admin" && a.IsActive && a.InstitutionID == admin.InstitutionID {
+				superAdmins = append(superAdmins, a.ID)
+			}
+		}
+		if len(superAdmins) == 1 && superAdmins[0] == admin.ID {
+			return models.ErrLastSuperAdmin
+		}
+	}
+
+	stored, ok := m.s.admins[admin.ID]
+	if !ok {
+		return models.ErrRecordNotFound
+	}
+
+	stored.Name = admin.Name
+	stored.Phone = clone(admin.Phone)
+	stored.Designation = admin.Designation
+	stored.Role = admin.Role
+	stored.Department = clone(admin.Department)
+	stored.IsActive = admin.IsActive
+	stored.UpdatedAt = time.Now()
+	admin.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// ============================================
+// SESSIONS
+// ============================================
+
+type sessionStore struct{ s *Store }
+
+var _ models.SessionStore = sessionStore{}
+
+func (m sessionStore) Insert(_ context.Context, sess *models.Session, tokenHash []byte) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	sess.ID = m.s.nextID()
+	sess.CreatedAt, sess.LastUsedAt = now, now
+	m.s.sessions[sess.ID] = &session{Session: *sess, hash: bytes.Clone(tokenHash)}
+	return nil
+}
+
+// Rotate revokes the session when a rotated-out token is presented again,
+// like SessionModel.Rotate
+func (m sessionStore) Rotate(_ context.Context, oldHash, newHash []byte, expiresAt time.Time) (*models.Session, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	for _, sess := range m.s.sessions {
+		if bytes.Equal(sess.hash, oldHash) && sess.RevokedAt == nil && sess.ExpiresAt.After(now) {
+			sess.previousHash, sess.hash = sess.hash, bytes.Clone(newHash)
+			sess.ExpiresAt, sess.LastUsedAt = expiresAt, now
+			return clone(&sess.Session), nil
+		}
+	}
+
+	reused := false
+	for _, sess := range m.s.sessions {
+		if bytes.Equal(sess.previousHash, oldHash) && sess.RevokedAt == nil {
+			sess.RevokedAt = &now
+			reused = true
+		}
+	}
+	if reused {
+		return nil, models.ErrRefreshTokenReuse
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m sessionStore) IsActive(_ context.Context, id int64) (bool, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	sess, ok := m.s.sessions[id]
+	return ok && sess.RevokedAt == nil && sess.ExpiresAt.After(time.Now()), nil
+}
+
+func (m sessionStore) Revoke(_ context.Context, id int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if sess, ok := m.s.sessions[id]; ok && sess.RevokedAt == nil {
+		now := time.Now()
+		sess.RevokedAt = &now
+	}
+	return nil
+}
+
+func (m sessionStore) RevokeAllForUser(_ context.Context, userType string, userID int64) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	var revoked int64
+	for _, sess := range m.s.sessions {
+		if sess.UserType == userType && sess.UserID == userID && sess.RevokedAt == nil {
+			sess.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (m sessionStore) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var deleted int64
+	for id, sess := range m.s.sessions {
+		if sess.ExpiresAt.Before(before) || (sess.RevokedAt != nil && sess.RevokedAt.Before(before)) {
+			delete(m.s.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ============================================
+// AUTH CODES
+// ============================================
+
+type authCodeStore struct{ s *Store }
+
+var _ models.AuthCodeStore = authCodeStore{}
+
+func (m authCodeStore) Insert(_ context.Context, codeHash []byte, code *models.AuthCode) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	m.s.authCodes[string(codeHash)] = *code
+	return nil
+}
+
+func (m authCodeStore) Consume(_ context.Context, codeHash []byte) (*models.AuthCode, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	code, ok := m.s.authCodes[string(codeHash)]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	delete(m.s.authCodes, string(codeHash))
+
+	if time.Now().After(code.ExpiresAt) {
+		return nil, models.ErrRecordNotFound
+	}
+	return &code, nil
+}
+
+func (m authCodeStore) DeleteExpired(_ context.Context, before time.Time) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var deleted int64
+	for hash, code := range m.s.authCodes {
+		if code.ExpiresAt.Before(before) {
+			delete(m.s.authCodes, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ============================================
+// API KEYS
+// ============================================
+
+type apiKeyStore struct{ s *Store }
+
+var _ models.APIKeyStore = apiKeyStore{}
+
+func (m apiKeyStore) Insert(_ context.Context, key *models.APIKey, keyHash []byte) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key.ID = m.s.nextID()
+	key.CreatedAt = time.Now()
+	stored := &apiKey{APIKey: *key, hash: bytes.Clone(keyHash)}
+	stored.Scopes = append([]string{}, key.Scopes...)
+	m.s.apiKeys[key.ID] = stored
+	return nil
+}
+
+func (m apiKeyStore) GetActiveByHash(_ context.Context, keyHash []byte) (*models.APIKey, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range m.s.apiKeys {
+		if bytes.Equal(key.hash, keyHash) && key.RevokedAt == nil && (key.ExpiresAt == nil || key.ExpiresAt.After(now)) {
+			return cloneAPIKey(key), nil
+		}
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m apiKeyStore) GetAll(_ context.Context, institutionID *int64) ([]*models.APIKey, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	newestFirst := func(a, b *apiKey) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	}
+
+	keys := []*models.APIKey{}
+	for _, key := range sorted(m.s.apiKeys, newestFirst) {
+		if inInstitution(institutionID, key.InstitutionID) {
+			keys = append(keys, cloneAPIKey(key))
+		}
+	}
+	return keys, nil
+}
+
+func (m apiKeyStore) Revoke(_ context.Context, institutionID *int64, id int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key, ok := m.s.apiKeys[id]
+	if !ok || key.RevokedAt != nil || !inInstitution(institutionID, key.InstitutionID) {
+		return models.ErrRecordNotFound
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return nil
+}
+
+// TouchLastUsed records usage at most once a minute, like APIKeyModel.TouchLastUsed
+func (m apiKeyStore) TouchLastUsed(_ context.Context, id int64, ip string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key, ok := m.s.apiKeys[id]
+	now := time.Now()
+	if !ok || (key.LastUsedAt != nil && key.LastUsedAt.After(now.Add(-time.Minute))) {
+		return nil
+	}
+
+	key.LastUsedAt = &now
+	key.LastUsedIP = nil
+	if ip != "" {
+		key.LastUsedIP = &ip
+	}
+	return nil
+}
+
+func cloneAPIKey(key *apiKey) *models.APIKey {
+	c := key.APIKey
+	c.Scopes = append([]string{}, key.Scopes...)
+	return &c
+}
+
+// ============================================
+// ACTIVITY LOG
+// ============================================
+
+type activityStore struct{ s *Store }
+
+var _ models.ActivityStore = activityStore{}
+
+func (m activityStore) Insert(_ context.Context, log *models.ActivityLog) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	log.ID = m.s.nextID()
+	log.CreatedAt = time.Now()
+	m.s.activity = append(m.s.activity, *log)
+	return nil
+}
+
+func (m activityStore) GetByEntityType(_ context.Context, institutionID *int64, entityType string, limit int) ([]models.ActivityLog, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// Entries are appended in order, so walking backwards is newest first
+	var logs []models.ActivityLog
+	for i := len(m.s.activity) - 1; i >= 0 && len(logs) < limit; i-- {
+		l := m.s.activity[i]
+		if l.EntityType == nil || *l.EntityType != entityType {
+			continue
+		}
+		if institutionID != nil && (l.InstitutionID == nil || *l.InstitutionID != *institutionID) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
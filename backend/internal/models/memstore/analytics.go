@@ -0,0 +1,417 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+type analyticsStore struct{ s *Store }
+
+var _ models.AnalyticsStore = analyticsStore{}
+
+// packageStats aggregates package_lpa the way AVG, MAX and MIN do: NULLs are
+// ignored and an empty set reports zero
+type packageStats struct {
+	sum, max, min float64
+	n             int
+}
+
+func (p *packageStats) add(lpa *float64) {
+	if lpa == nil {
+		return
+	}
+	if p.n == 0 || *lpa > p.max {
+		p.max = *lpa
+	}
+	if *lpa > 0 && (p.min == 0 || *lpa < p.min) {
+		p.min = *lpa
+	}
+	p.sum += *lpa
+	p.n++
+}
+
+func (p *packageStats) avg() float64 {
+	if p.n == 0 {
+		return 0
+	}
+	return p.sum / float64(p.n)
+}
+
+// studentsIn lists the students of an institution and, when they are set,
+// of that department and batch year
+func (s *Store) studentsIn(institutionID *int64, department *string, batchYear *int) []*models.Student {
+	var students []*models.Student
+	for _, st := range sorted(s.students, byStudentID) {
+		if !inInstitution(institutionID, st.InstitutionID) || !inDepartment(department, st) {
+			continue
+		}
+		if batchYear != nil {
+			if year := s.batchYear(st); year == nil || *year != *batchYear {
+				continue
+			}
+		}
+		students = append(students, st)
+	}
+	return students
+}
+
+// inDepartment reports whether the student belongs to department; nil matches every student
+func inDepartment(department *string, st *models.Student) bool {
+	return department == nil || (st.Department != nil && *st.Department == *department)
+}
+
+// acceptedPlacements lists every accepted offer of the given students
+func (s *Store) acceptedPlacements(students []*models.Student) []*models.PlacementRecord {
+	ids := make(map[int64]bool, len(students))
+	for _, st := range students {
+		ids[st.ID] = true
+	}
+
+	var placements []*models.PlacementRecord
+	for _, p := range sorted(s.placements, func(a, b *models.PlacementRecord) int { return cmp.Compare(a.ID, b.ID) }) {
+		if p.IsAccepted && ids[p.StudentID] {
+			placements = append(placements, p)
+		}
+	}
+	return placements
+}
+
+func (m analyticsStore) GetDashboardStats(_ context.Context, institutionID *int64, department *string, batchYear *int) (*models.DashboardStats, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var stats models.DashboardStats
+	students := m.s.studentsIn(institutionID, department, batchYear)
+	for _, st := range students {
+		stats.TotalStudents++
+		if st.IsProfileCompleted {
+			stats.ProfilesCompleted++
+		}
+		switch st.PlacementStatus {
+		case models.PlacementStatusPlaced:
+			stats.StudentsPlaced++
+		case models.PlacementStatusNotPlaced:
+			stats.StudentsNotPlaced++
+		case models.PlacementStatusInProcess:
+			stats.StudentsInProcess++
+		case models.PlacementStatusHigherStudies:
+			stats.HigherStudies++
+		}
+	}
+
+	if stats.TotalStudents > 0 {
+		stats.ProfileCompletionPct = float64(stats.ProfilesCompleted) / float64(stats.TotalStudents) * 100
+		eligibleForPlacement := stats.TotalStudents - stats.HigherStudies
+		if eligibleForPlacement > 0 {
+			stats.PlacementPct = float64(stats.StudentsPlaced) / float64(eligibleForPlacement) * 100
+		}
+	}
+
+	var packages packageStats
+	for _, p := range m.s.acceptedPlacements(students) {
+		packages.add(p.PackageLPA)
+	}
+	stats.AvgPackage, stats.MaxPackage, stats.MinPackage = packages.avg(), packages.max, packages.min
+
+	for _, c := range m.s.companies {
+		if c.IsActive && inInstitution(institutionID, c.InstitutionID) {
+			stats.TotalCompanies++
+		}
+	}
+
+	return &stats, nil
+}
+
+func (m analyticsStore) GetBatchWiseStats(_ context.Context, institutionID *int64, department *string) ([]models.BatchStats, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// Batches of the same year are merged across institutions
+	years := make(map[int]map[int]bool)
+	for _, b := range m.s.batches {
+		if b.IsActive && inInstitution(institutionID, b.InstitutionID) {
+			if years[b.Year] == nil {
+				years[b.Year] = make(map[int]bool)
+			}
+			years[b.Year][b.ID] = true
+		}
+	}
+
+	var stats []models.BatchStats
+	for year, batchIDs := range years {
+		s := models.BatchStats{BatchYear: year}
+
+		var students []*models.Student
+		for _, st := range sorted(m.s.students, byStudentID) {
+			if st.BatchID == nil || !batchIDs[*st.BatchID] || !inDepartment(department, st) {
+				continue
+			}
+			students = append(students, st)
+			s.TotalStudents++
+			if st.IsProfileCompleted {
+				s.ProfilesCompleted++
+			}
+			if st.PlacementStatus == models.PlacementStatusPlaced {
+				s.StudentsPlaced++
+			}
+		}
+		if s.TotalStudents > 0 {
+			s.PlacementPct = math.Round(float64(s.StudentsPlaced)/float64(s.TotalStudents)*100*100) / 100
+		}
+
+		var packages packageStats
+		for _, p := range m.s.acceptedPlacements(students) {
+			packages.add(p.PackageLPA)
+		}
+		s.AvgPackage, s.MaxPackage = packages.avg(), packages.max
+
+		stats = append(stats, s)
+	}
+
+	slices.SortFunc(stats, func(a, b models.BatchStats) int { return cmp.Compare(b.BatchYear, a.BatchYear) })
+	return stats, nil
+}
+
+func (m analyticsStore) GetSkillStats(_ context.Context, institutionID *int64, department *string) ([]models.SkillStats, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	type key struct {
+		name     string
+		category models.SkillCategory
+	}
+	counts := make(map[key]*models.SkillStats)
+	for studentID, rows := range m.s.studentSkills {
+		if !inDepartment(department, m.s.students[studentID]) {
+			continue
+		}
+		for _, row := range rows {
+			sk := m.s.skills[row.SkillID]
+			if !sk.IsActive || !inInstitution(institutionID, sk.InstitutionID) {
+				continue
+			}
+
+			k := key{sk.Name, sk.Category}
+			s, ok := counts[k]
+			if !ok {
+				s = &models.SkillStats{SkillName: sk.Name, Category: string(sk.Category)}
+				counts[k] = s
+			}
+			switch row.Proficiency {
+			case models.ProficiencyBeginner:
+				s.Beginners++
+			case models.ProficiencyIntermediate:
+				s.Intermediate++
+			case models.ProficiencyAdvanced:
+				s.Advanced++
+			case models.ProficiencyExpert:
+				s.Experts++
+			}
+			s.Total++
+		}
+	}
+
+	var stats []models.SkillStats
+	for _, s := range counts {
+		stats = append(stats, *s)
+	}
+	slices.SortFunc(stats, func(a, b models.SkillStats) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.SkillName, b.SkillName))
+	})
+	if len(stats) > 30 {
+		stats = stats[:30]
+	}
+	return stats, nil
+}
+
+type cgpaRange struct {
+	label string
+	min   float64
+}
+
+// cgpaRanges are the buckets of GetCGPADistribution, best first
+var cgpaRanges = []cgpaRange{
+	{"9.0 - 10.0", 9},
+	{"8.0 - 8.99", 8},
+	{"7.0 - 7.99", 7},
+	{"6.0 - 6.99", 6},
+	{"Below 6.0", math.Inf(-1)},
+}
+
+func (m analyticsStore) GetCGPADistribution(_ context.Context, institutionID *int64, department *string, batchYear *int) ([]models.CGPADistribution, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	buckets := make([]models.CGPADistribution, len(cgpaRanges))
+	for _, st := range m.s.studentsIn(institutionID, department, batchYear) {
+		a := m.s.academics[st.ID]
+		if a == nil || a.CGPAOverall == nil {
+			continue
+		}
+
+		i := slices.IndexFunc(cgpaRanges, func(r cgpaRange) bool { return *a.CGPAOverall >= r.min })
+		buckets[i].Count++
+		switch st.PlacementStatus {
+		case models.PlacementStatusPlaced:
+			buckets[i].Placed++
+		case models.PlacementStatusNotPlaced:
+			buckets[i].NotPlaced++
+		}
+	}
+
+	var stats []models.CGPADistribution
+	for i, b := range buckets {
+		if b.Count > 0 {
+			b.Range = cgpaRanges[i].label
+			stats = append(stats, b)
+		}
+	}
+	return stats, nil
+}
+
+func (m analyticsStore) GetCompanyStats(_ context.Context, institutionID *int64, department *string, batchYear *int) ([]models.CompanyStats, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	byName := make(map[string]*packageStats)
+	hired := make(map[string]int)
+	for _, p := range m.s.acceptedPlacements(m.s.studentsIn(institutionID, department, batchYear)) {
+		name := p.CompanyName
+		if p.CompanyID != nil {
+			if c, ok := m.s.companies[*p.CompanyID]; ok {
+				name = c.Name
+			}
+		}
+		if byName[name] == nil {
+			byName[name] = &packageStats{}
+		}
+		byName[name].add(p.PackageLPA)
+		hired[name]++
+	}
+
+	var stats []models.CompanyStats
+	for name, packages := range byName {
+		stats = append(stats, models.CompanyStats{
+			CompanyName: name, HiredCount: hired[name], AvgPackage: packages.avg(), MaxPackage: packages.max,
+		})
+	}
+	slices.SortFunc(stats, func(a, b models.CompanyStats) int {
+		return cmp.Or(cmp.Compare(b.HiredCount, a.HiredCount), cmp.Compare(a.CompanyName, b.CompanyName))
+	})
+	return stats, nil
+}
+
+func (m analyticsStore) GetRecentActivity(_ context.Context, institutionID *int64, department *string, limit int) ([]models.RecentActivity, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var activities []models.RecentActivity
+	students := m.s.studentsIn(institutionID, department, nil)
+	for _, st := range students {
+		activities = append(activities, models.RecentActivity{
+			Type: "registration", StudentName: st.Name, Details: "New student registered", Timestamp: st.CreatedAt,
+		})
+	}
+	for _, p := range m.s.acceptedPlacements(students) {
+		name := p.CompanyName
+		if p.CompanyID != nil {
+			if c, ok := m.s.companies[*p.CompanyID]; ok {
+				name = c.Name
+			}
+		}
+		activities = append(activities, models.RecentActivity{
+			Type: "placed", StudentName: m.s.students[p.StudentID].Name, Details: "Placed at " + name, Timestamp: p.CreatedAt,
+		})
+	}
+
+	slices.SortStableFunc(activities, func(a, b models.RecentActivity) int { return b.Timestamp.Compare(a.Timestamp) })
+	if len(activities) > limit {
+		activities = activities[:limit]
+	}
+	return activities, nil
+}
+
+func (m analyticsStore) GetBatches(_ context.Context, institutionID *int64) ([]models.Batch, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// Across institutions, batches of the same year are merged
+	byYear := make(map[int]*models.Batch)
+	for _, b := range m.s.batches {
+		if !inInstitution(institutionID, b.InstitutionID) {
+			continue
+		}
+		merged, ok := byYear[b.Year]
+		if !ok {
+			byYear[b.Year] = &models.Batch{ID: b.ID, Year: b.Year, IsActive: b.IsActive}
+			continue
+		}
+		merged.ID = min(merged.ID, b.ID)
+		merged.IsActive = merged.IsActive || b.IsActive
+	}
+
+	var batches []models.Batch
+	for _, b := range sorted(byYear, func(a, b *models.Batch) int { return cmp.Compare(b.Year, a.Year) }) {
+		batches = append(batches, *b)
+	}
+	return batches, nil
+}
+
+func (m analyticsStore) GetInstitutionTotals(_ context.Context) ([]models.InstitutionTotals, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var totals []models.InstitutionTotals
+	for _, inst := range sorted(m.s.institutions, func(a, b *models.Institution) int { return cmp.Compare(a.Code, b.Code) }) {
+		students := m.s.studentsIn(&inst.ID, nil, nil)
+		t := models.InstitutionTotals{
+			InstitutionCode:    inst.Code,
+			StudentsRegistered: len(students),
+			PlacementsRecorded: len(m.s.acceptedPlacements(students)),
+		}
+		for _, st := range students {
+			if st.IsProfileCompleted {
+				t.ProfilesCompleted++
+			}
+		}
+		totals = append(totals, t)
+	}
+	return totals, nil
+}
+
+func (m analyticsStore) SaveDashboardSnapshot(_ context.Context, institutionID int64, day time.Time, stats *models.DashboardStats) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if m.s.snapshots[institutionID] == nil {
+		m.s.snapshots[institutionID] = make(map[string]models.DashboardStats)
+	}
+	m.s.snapshots[institutionID][day.Format(time.DateOnly)] = *stats
+	return nil
+}
+
+func (m analyticsStore) GetDashboardSnapshots(_ context.Context, institutionID int64, since time.Time) ([]models.DashboardSnapshot, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	from := since.Format(time.DateOnly)
+	snapshots := []models.DashboardSnapshot{}
+	for day, stats := range m.s.snapshots[institutionID] {
+		if day < from {
+			continue
+		}
+		takenOn, _ := time.Parse(time.DateOnly, day)
+		snapshots = append(snapshots, models.DashboardSnapshot{TakenOn: takenOn, Stats: stats})
+	}
+	slices.SortFunc(snapshots, func(a, b models.DashboardSnapshot) int { return a.TakenOn.Compare(b.TakenOn) })
+	return snapshots, nil
+}
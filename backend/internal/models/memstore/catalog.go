@@ -0,0 +1,443 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+// ============================================
+// INSTITUTIONS
+// ============================================
+
+type institutionStore struct{ s *Store }
+
+var _ models.InstitutionStore = institutionStore{}
+
+func (m institutionStore) GetByID(_ context.Context, id int64) (*models.Institution, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	inst, ok := m.s.institutions[id]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	return cloneInstitution(inst), nil
+}
+
+func (m institutionStore) GetByDomain(_ context.Context, domain string) (*models.Institution, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	domain = strings.ToLower(domain)
+	for _, inst := range m.s.institutions {
+		if inst.IsActive && slices.Contains(inst.Domains, domain) {
+			return cloneInstitution(inst), nil
+		}
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m institutionStore) GetAll(_ context.Context) ([]*models.Institution, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	institutions := []*models.Institution{}
+	for _, inst := range sorted(m.s.institutions, func(a, b *models.Institution) int { return cmp.Compare(a.Name, b.Name) }) {
+		institutions = append(institutions, cloneInstitution(inst))
+	}
+	return institutions, nil
+}
+
+// Insert copies the skills and batch years of the oldest institution, like
+// InstitutionModel.Insert
+func (m institutionStore) Insert(_ context.Context, inst *models.Institution) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, other := range m.s.institutions {
+		if other.Code == inst.Code {
+			return models.ErrDuplicateCode
+		}
+	}
+	domains, err := m.s.claimDomains(0, inst.Domains)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	inst.ID = m.s.nextID()
+	inst.IsActive = true
+	inst.CreatedAt, inst.UpdatedAt = now, now
+	stored := cloneInstitution(inst)
+	stored.Domains = domains
+	m.s.institutions[inst.ID] = stored
+
+	oldest := slices.Min(slices.Collect(maps.Keys(m.s.institutions)))
+	for _, sk := range sorted(m.s.skills, compareSkills) {
+		if sk.InstitutionID == oldest && sk.IsActive {
+			id := int(m.s.nextID())
+			m.s.skills[id] = &models.Skill{
+				ID: id, InstitutionID: inst.ID, Name: sk.Name, Category: sk.Category,
+				Description: clone(sk.Description), IsActive: true, DisplayOrder: sk.DisplayOrder,
+			}
+		}
+	}
+	for _, b := range sorted(m.s.batches, func(a, b *batch) int { return cmp.Compare(a.ID, b.ID) }) {
+		if b.InstitutionID == oldest && b.IsActive {
+			id := int(m.s.nextID())
+			m.s.batches[id] = &batch{ID: id, InstitutionID: inst.ID, Year: b.Year, IsActive: true}
+		}
+	}
+
+	return nil
+}
+
+func (m institutionStore) Update(_ context.Context, inst *models.Institution) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	stored, ok := m.s.institutions[inst.ID]
+	if !ok {
+		return models.ErrRecordNotFound
+	}
+	domains, err := m.s.claimDomains(inst.ID, inst.Domains)
+	if err != nil {
+		return err
+	}
+
+	stored.Name = inst.Name
+	stored.LogoURL = clone(inst.LogoURL)
+	stored.PrimaryColor = clone(inst.PrimaryColor)
+	stored.IsActive = inst.IsActive
+	stored.Domains = domains
+	stored.UpdatedAt = time.Now()
+	inst.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+// claimDomains lowercases and sorts domains, failing when another
+// institution already owns one of them
+func (s *Store) claimDomains(institutionID int64, domains []string) ([]string, error) {
+	claimed := []string{}
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if slices.Contains(claimed, domain) {
+			return nil, models.ErrDuplicateDomain
+		}
+		for _, other := range s.institutions {
+			if other.ID != institutionID && slices.Contains(other.Domains, domain) {
+				return nil, models.ErrDuplicateDomain
+			}
+		}
+		claimed = append(claimed, domain)
+	}
+	slices.Sort(claimed)
+	return claimed, nil
+}
+
+func cloneInstitution(inst *models.Institution) *models.Institution {
+	c := clone(inst)
+	c.Domains = append([]string{}, inst.Domains...)
+	return c
+}
+
+// ============================================
+// SKILLS
+// ============================================
+
+type skillStore struct{ s *Store }
+
+var _ models.SkillStore = skillStore{}
+
+func (m skillStore) GetAll(_ context.Context, institutionID int64) ([]models.Skill, error) {
+	return m.list(func(sk *models.Skill) bool { return sk.InstitutionID == institutionID })
+}
+
+func (m skillStore) GetByCategory(_ context.Context, institutionID int64, category models.SkillCategory) ([]models.Skill, error) {
+	return m.list(func(sk *models.Skill) bool { return sk.InstitutionID == institutionID && sk.Category == category })
+}
+
+func (m skillStore) list(match func(*models.Skill) bool) ([]models.Skill, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var skills []models.Skill
+	for _, sk := range sorted(m.s.skills, compareSkills) {
+		if sk.IsActive && match(sk) {
+			skills = append(skills, *sk)
+		}
+	}
+	return skills, nil
+}
+
+func (m skillStore) GetGroupedByCategory(ctx context.Context, institutionID int64) (map[models.SkillCategory][]models.Skill, error) {
+	skills, err := m.GetAll(ctx, institutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[models.SkillCategory][]models.Skill)
+	for _, skill := range skills {
+		grouped[skill.Category] = append(grouped[skill.Category], skill)
+	}
+	return grouped, nil
+}
+
+func (m skillStore) GetByID(_ context.Context, id int) (*models.Skill, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	sk, ok := m.s.skills[id]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	return clone(sk), nil
+}
+
+func (m skillStore) Insert(_ context.Context, skill *models.Skill) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	skill.ID = int(m.s.nextID())
+	stored := clone(skill)
+	stored.IsActive = true
+	m.s.skills[skill.ID] = stored
+	return nil
+}
+
+func (m skillStore) GetAsMap(ctx context.Context, institutionID int64) (map[string]int, error) {
+	skills, err := m.GetAll(ctx, institutionID)
+	if err != nil {
+		return nil, err
+	}
+
+	skillMap := make(map[string]int)
+	for _, s := range skills {
+		skillMap[s.Name] = s.ID
+	}
+	return skillMap, nil
+}
+
+// ============================================
+// COMPANIES
+// ============================================
+
+type companyStore struct{ s *Store }
+
+var _ models.CompanyStore = companyStore{}
+
+func byCompanyName(a, b *models.Company) int {
+	return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+}
+
+func (m companyStore) GetAll(_ context.Context, institutionID *int64) ([]models.Company, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var companies []models.Company
+	for _, c := range sorted(m.s.companies, byCompanyName) {
+		if c.IsActive && inInstitution(institutionID, c.InstitutionID) {
+			companies = append(companies, *c)
+		}
+	}
+	return companies, nil
+}
+
+func (m companyStore) GetByID(_ context.Context, id int64) (*models.Company, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.s.companies[id]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	return clone(c), nil
+}
+
+func (m companyStore) Search(_ context.Context, institutionID *int64, search string) ([]models.Company, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var companies []models.Company
+	for _, c := range sorted(m.s.companies, byCompanyName) {
+		if c.IsActive && inInstitution(institutionID, c.InstitutionID) && containsFold(&c.Name, search) {
+			companies = append(companies, *c)
+		}
+		if len(companies) == 20 {
+			break
+		}
+	}
+	return companies, nil
+}
+
+func (m companyStore) Insert(_ context.Context, c *models.Company) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	c.ID = m.s.nextID()
+	c.CreatedAt, c.UpdatedAt = now, now
+	stored := clone(c)
+	stored.IsActive = true
+	m.s.companies[c.ID] = stored
+	return nil
+}
+
+func (m companyStore) Update(_ context.Context, c *models.Company) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	stored, ok := m.s.companies[c.ID]
+	if !ok {
+		return models.ErrRecordNotFound
+	}
+
+	c.UpdatedAt = time.Now()
+	updated := clone(c)
+	updated.InstitutionID, updated.CreatedAt = stored.InstitutionID, stored.CreatedAt
+	m.s.companies[c.ID] = updated
+	return nil
+}
+
+func (m companyStore) Delete(_ context.Context, institutionID *int64, id int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.s.companies[id]
+	if !ok || !inInstitution(institutionID, c.InstitutionID) {
+		return models.ErrRecordNotFound
+	}
+	delete(m.s.companies, id)
+	return nil
+}
+
+// ============================================
+// PLACEMENTS
+// ============================================
+
+type placementStore struct{ s *Store }
+
+var _ models.PlacementStore = placementStore{}
+
+func (m placementStore) GetByStudentID(_ context.Context, studentID int64) (*models.PlacementRecord, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	return clone(m.s.acceptedPlacement(studentID)), nil
+}
+
+func (m placementStore) GetStudentID(_ context.Context, id int64) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.placements[id]
+	if !ok {
+		return 0, models.ErrRecordNotFound
+	}
+	return p.StudentID, nil
+}
+
+func (m placementStore) GetAll(_ context.Context, institutionID *int64, department *string) ([]models.PlacementWithStudent, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	newestFirst := func(a, b *models.PlacementRecord) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	}
+
+	var placements []models.PlacementWithStudent
+	for _, p := range sorted(m.s.placements, newestFirst) {
+		st := m.s.students[p.StudentID]
+		if !p.IsAccepted || !inInstitution(institutionID, st.InstitutionID) {
+			continue
+		}
+		if department != nil && (st.Department == nil || *st.Department != *department) {
+			continue
+		}
+		placements = append(placements, models.PlacementWithStudent{
+			PlacementRecord: *p,
+			StudentName:     st.Name,
+			StudentRoll:     clone(st.RollNo),
+			StudentEmail:    st.OfficialEmail,
+			StudentPhotoURL: clone(st.PhotoURL),
+			BatchYear:       m.s.batchYear(st),
+		})
+	}
+	return placements, nil
+}
+
+func (m placementStore) Insert(_ context.Context, p *models.PlacementRecord) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.students[p.StudentID]; !ok {
+		return models.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	p.ID = m.s.nextID()
+	p.CreatedAt, p.UpdatedAt = now, now
+	stored := clone(p)
+	stored.JoiningDate, stored.OfferDate = dateOrNil(p.JoiningDate), dateOrNil(p.OfferDate)
+	stored.VerifiedAt = nil
+	m.s.placements[p.ID] = stored
+	return nil
+}
+
+func (m placementStore) Update(_ context.Context, p *models.PlacementRecord) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	stored, ok := m.s.placements[p.ID]
+	if !ok {
+		return models.ErrRecordNotFound
+	}
+
+	stored.CompanyID = clone(p.CompanyID)
+	stored.CompanyName = p.CompanyName
+	stored.JobRole = clone(p.JobRole)
+	stored.PackageLPA = clone(p.PackageLPA)
+	stored.PackageCTC = clone(p.PackageCTC)
+	stored.JoiningDate, stored.OfferDate = dateOrNil(p.JoiningDate), dateOrNil(p.OfferDate)
+	stored.OfferType = clone(p.OfferType)
+	stored.JobLocation = clone(p.JobLocation)
+	stored.IsAccepted = p.IsAccepted
+	stored.OfferLetterURL = clone(p.OfferLetterURL)
+	stored.Remarks = clone(p.Remarks)
+	stored.UpdatedAt = time.Now()
+	p.UpdatedAt = stored.UpdatedAt
+	return nil
+}
+
+func (m placementStore) Delete(_ context.Context, id int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	delete(m.s.placements, id)
+	return nil
+}
+
+func (m placementStore) Verify(_ context.Context, id int64, adminID int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if p, ok := m.s.placements[id]; ok {
+		now := time.Now()
+		p.VerifiedBy, p.VerifiedAt = &adminID, &now
+	}
+	return nil
+}
+
+// dateOrNil stores a blank date as NULL, like PlacementModel does
+func dateOrNil(date *string) *string {
+	if date == nil || *date == "" {
+		return nil
+	}
+	return clone(date)
+}
@@ -0,0 +1,218 @@
+// Package memstore implements the model stores in memory, so handlers can be
+// tested without Postgres. It mirrors the queries of the models package
+// closely enough for the shared store tests to pass against both.
+package memstore
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+// DefaultInstitutionID is the institution New seeds, like migration 007 does
+const DefaultInstitutionID int64 = 1
+
+type batch struct {
+	ID            int
+	InstitutionID int64
+	Year          int
+	IsActive      bool
+}
+
+type session struct {
+	models.Session
+	hash, previousHash []byte
+}
+
+type apiKey struct {
+	models.APIKey
+	hash []byte
+}
+
+// Store holds every table behind one mutex. Records are copied on the way in
+// and out so callers never share memory with the store.
+type Store struct {
+	mu  sync.Mutex
+	seq int64
+
+	institutions map[int64]*models.Institution
+	batches      map[int]*batch
+	skills       map[int]*models.Skill
+
+	students      map[int64]*models.Student
+	personal      map[int64]*models.StudentPersonalDetails
+	family        map[int64]*models.StudentFamilyDetails
+	academics     map[int64]*models.StudentAcademics
+	achievements  map[int64]*models.StudentAchievements
+	aspirations   map[int64]*models.StudentAspirations
+	studentSkills map[int64][]models.StudentSkill
+
+	companies  map[int64]*models.Company
+	placements map[int64]*models.PlacementRecord
+
+	admins    map[int64]*models.Admin
+	sessions  map[int64]*session
+	authCodes map[string]models.AuthCode
+	apiKeys   map[int64]*apiKey
+	activity  []models.ActivityLog
+
+	// snapshots maps an institution to its dashboard by day (YYYY-MM-DD)
+	snapshots map[int64]map[string]models.DashboardStats
+}
+
+// New returns a store seeded like a freshly migrated database: one
+// institution with the kct.ac.in domain, batches 2024 to 2027 and a small
+// skill catalogue
+func New() *Store {
+	s := &Store{
+		institutions:  make(map[int64]*models.Institution),
+		batches:       make(map[int]*batch),
+		skills:        make(map[int]*models.Skill),
+		students:      make(map[int64]*models.Student),
+		personal:      make(map[int64]*models.StudentPersonalDetails),
+		family:        make(map[int64]*models.StudentFamilyDetails),
+		academics:     make(map[int64]*models.StudentAcademics),
+		achievements:  make(map[int64]*models.StudentAchievements),
+		aspirations:   make(map[int64]*models.StudentAspirations),
+		studentSkills: make(map[int64][]models.StudentSkill),
+		companies:     make(map[int64]*models.Company),
+		placements:    make(map[int64]*models.PlacementRecord),
+		admins:        make(map[int64]*models.Admin),
+		sessions:      make(map[int64]*session),
+		authCodes:     make(map[string]models.AuthCode),
+		apiKeys:       make(map[int64]*apiKey),
+		snapshots:     make(map[int64]map[string]models.DashboardStats),
+	}
+
+	now := time.Now()
+	s.institutions[DefaultInstitutionID] = &models.Institution{
+		ID: DefaultInstitutionID, Code: "kct", Name: "Kumaraguru College of Technology",
+		Domains: []string{"kct.ac.in"}, IsActive: true, CreatedAt: now, UpdatedAt: now,
+	}
+	s.seq = DefaultInstitutionID
+
+	for _, year := range []int{2024, 2025, 2026, 2027} {
+		s.AddBatch(DefaultInstitutionID, year)
+	}
+
+	for _, group := range []struct {
+		category models.SkillCategory
+		names    []string
+	}{
+		{models.SkillCategoryProgramming, []string{"C", "C++", "Java", "Python", "JavaScript", "Go"}},
+		{models.SkillCategoryDatabase, []string{"MySQL", "PostgreSQL", "MongoDB"}},
+		{models.SkillCategoryFramework, []string{"React", "Node.js", "Django", "Spring Boot"}},
+		{models.SkillCategoryTool, []string{"Git", "Docker"}},
+	} {
+		for i, name := range group.names {
+			id := int(s.nextID())
+			s.skills[id] = &models.Skill{
+				ID: id, InstitutionID: DefaultInstitutionID, Name: name, Category: group.category,
+				IsActive: true, DisplayOrder: i + 1,
+			}
+		}
+	}
+
+	return s
+}
+
+// Models wires every store into a models.Models. Its DB field is nil.
+func (s *Store) Models() models.Models {
+	return models.Models{
+		Institutions: institutionStore{s},
+		Students:     studentStore{s},
+		Admins:       adminStore{s},
+		Skills:       skillStore{s},
+		Companies:    companyStore{s},
+		Placements:   placementStore{s},
+		Analytics:    analyticsStore{s},
+		Sessions:     sessionStore{s},
+		Activity:     activityStore{s},
+		AuthCodes:    authCodeStore{s},
+		APIKeys:      apiKeyStore{s},
+	}
+}
+
+// AddBatch creates an active batch year for an institution and returns its
+// ID. The schema has no model for batches, so tests seed them directly.
+func (s *Store) AddBatch(institutionID int64, year int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := int(s.nextID())
+	s.batches[id] = &batch{ID: id, InstitutionID: institutionID, Year: year, IsActive: true}
+	return id
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+// nextID is a single sequence shared by every table; callers hold the lock
+func (s *Store) nextID() int64 {
+	s.seq++
+	return s.seq
+}
+
+func clone[T any](v *T) *T {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+// sorted returns the values of a map ordered by compare
+func sorted[K comparable, V any](m map[K]*V, compare func(a, b *V) int) []*V {
+	values := make([]*V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	slices.SortFunc(values, compare)
+	return values
+}
+
+func inInstitution(filter *int64, institutionID int64) bool {
+	return filter == nil || *filter == institutionID
+}
+
+// containsFold is ILIKE '%substr%'
+func containsFold(s *string, substr string) bool {
+	return s != nil && strings.Contains(strings.ToLower(*s), strings.ToLower(substr))
+}
+
+// categoryOrder follows the declaration order of the skill_category enum,
+// which is how Postgres sorts it
+var categoryOrder = map[models.SkillCategory]int{
+	models.SkillCategoryProgramming: 1,
+	models.SkillCategoryDatabase:    2,
+	models.SkillCategoryFramework:   3,
+	models.SkillCategoryTool:        4,
+	models.SkillCategoryConcept:     5,
+	models.SkillCategorySoftSkill:   6,
+}
+
+func compareSkills(a, b *models.Skill) int {
+	return cmp.Or(
+		cmp.Compare(categoryOrder[a.Category], categoryOrder[b.Category]),
+		cmp.Compare(a.DisplayOrder, b.DisplayOrder),
+		cmp.Compare(a.ID, b.ID),
+	)
+}
+
+// batchYear resolves a student's batch year the way the LEFT JOIN on batches does
+func (s *Store) batchYear(st *models.Student) *int {
+	if st.BatchID == nil {
+		return nil
+	}
+	b, ok := s.batches[*st.BatchID]
+	if !ok {
+		return nil
+	}
+	year := b.Year
+	return &year
+}
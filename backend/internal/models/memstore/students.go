@@ -0,0 +1,487 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+)
+
+type studentStore struct{ s *Store }
+
+var _ models.StudentStore = studentStore{}
+
+func (m studentStore) Insert(_ context.Context, student *models.Student) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, st := range m.s.students {
+		if st.OfficialEmail == student.OfficialEmail {
+			return models.ErrDuplicateEmail
+		}
+	}
+
+	now := time.Now()
+	student.ID = m.s.nextID()
+	student.CreatedAt, student.UpdatedAt = now, now
+	student.Version = 1
+
+	st := &models.Student{
+		ID: student.ID, InstitutionID: student.InstitutionID, OfficialEmail: student.OfficialEmail,
+		Name: student.Name, IsEligibleForPlacement: true, PlacementStatus: models.PlacementStatusNotPlaced,
+		CreatedAt: now, UpdatedAt: now, Version: 1,
+	}
+	m.s.students[st.ID] = st
+	return nil
+}
+
+func (m studentStore) GetByEmail(_ context.Context, email string) (*models.Student, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, st := range m.s.students {
+		if st.OfficialEmail == email {
+			return m.s.studentView(st), nil
+		}
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m studentStore) GetByID(_ context.Context, id int64) (*models.Student, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	st, ok := m.s.students[id]
+	if !ok {
+		return nil, models.ErrRecordNotFound
+	}
+	return m.s.studentView(st), nil
+}
+
+func (m studentStore) GetByRollNo(_ context.Context, institutionID *int64, rollNo string) (*models.Student, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, st := range sorted(m.s.students, byStudentID) {
+		if st.RollNo != nil && *st.RollNo == rollNo && inInstitution(institutionID, st.InstitutionID) {
+			return m.s.studentView(st), nil
+		}
+	}
+	return nil, models.ErrRecordNotFound
+}
+
+func (m studentStore) UpdateBasicInfo(_ context.Context, student *models.Student) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	st, ok := m.s.students[student.ID]
+	if !ok || st.Version != student.Version {
+		return models.ErrEditConflict
+	}
+
+	st.Name = student.Name
+	st.RollNo = clone(student.RollNo)
+	st.RegisterNo = clone(student.RegisterNo)
+	st.BatchID = clone(student.BatchID)
+	st.Department = clone(student.Department)
+	st.PhotoURL = clone(student.PhotoURL)
+	st.Version++
+	st.UpdatedAt = time.Now()
+
+	student.Version, student.UpdatedAt = st.Version, st.UpdatedAt
+	return nil
+}
+
+func (m studentStore) SetProfileCompleted(_ context.Context, studentID int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if st, ok := m.s.students[studentID]; ok {
+		st.IsProfileCompleted = true
+	}
+	return nil
+}
+
+func (m studentStore) UpdateLastLogin(_ context.Context, id int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if st, ok := m.s.students[id]; ok {
+		now := time.Now()
+		st.LastLoginAt = &now
+	}
+	return nil
+}
+
+func (m studentStore) UpdatePlacementStatus(_ context.Context, studentID int64, status models.PlacementStatus) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if st, ok := m.s.students[studentID]; ok {
+		st.PlacementStatus = status
+	}
+	return nil
+}
+
+func (m studentStore) GetBatchIDByYear(_ context.Context, institutionID int64, year int) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, b := range m.s.batches {
+		if b.InstitutionID == institutionID && b.Year == year {
+			return b.ID, nil
+		}
+	}
+	return 0, models.ErrRecordNotFound
+}
+
+// ============================================
+// PROFILE SECTIONS
+// ============================================
+
+func (m studentStore) UpsertPersonalDetails(_ context.Context, details *models.StudentPersonalDetails) error {
+	return upsertSection(m.s, m.s.personal, details, details.StudentID, func(d *models.StudentPersonalDetails) *int64 { return &d.ID })
+}
+
+func (m studentStore) GetPersonalDetails(_ context.Context, studentID int64) (*models.StudentPersonalDetails, error) {
+	return getSection(m.s, m.s.personal, studentID)
+}
+
+func (m studentStore) UpsertFamilyDetails(_ context.Context, details *models.StudentFamilyDetails) error {
+	return upsertSection(m.s, m.s.family, details, details.StudentID, func(d *models.StudentFamilyDetails) *int64 { return &d.ID })
+}
+
+func (m studentStore) GetFamilyDetails(_ context.Context, studentID int64) (*models.StudentFamilyDetails, error) {
+	return getSection(m.s, m.s.family, studentID)
+}
+
+func (m studentStore) UpsertAcademics(_ context.Context, a *models.StudentAcademics) error {
+	return upsertSection(m.s, m.s.academics, a, a.StudentID, func(a *models.StudentAcademics) *int64 { return &a.ID })
+}
+
+func (m studentStore) GetAcademics(_ context.Context, studentID int64) (*models.StudentAcademics, error) {
+	return getSection(m.s, m.s.academics, studentID)
+}
+
+func (m studentStore) UpsertAchievements(_ context.Context, a *models.StudentAchievements) error {
+	return upsertSection(m.s, m.s.achievements, a, a.StudentID, func(a *models.StudentAchievements) *int64 { return &a.ID })
+}
+
+func (m studentStore) GetAchievements(_ context.Context, studentID int64) (*models.StudentAchievements, error) {
+	return getSection(m.s, m.s.achievements, studentID)
+}
+
+func (m studentStore) UpsertAspirations(_ context.Context, a *models.StudentAspirations) error {
+	return upsertSection(m.s, m.s.aspirations, a, a.StudentID, func(a *models.StudentAspirations) *int64 { return &a.ID })
+}
+
+func (m studentStore) GetAspirations(_ context.Context, studentID int64) (*models.StudentAspirations, error) {
+	return getSection(m.s, m.s.aspirations, studentID)
+}
+
+// upsertSection stores a copy of a one-row-per-student section, keeping the
+// row ID of an earlier save like ON CONFLICT (student_id) DO UPDATE does
+func upsertSection[T any](s *Store, table map[int64]*T, row *T, studentID int64, id func(*T) *int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.students[studentID]; !ok {
+		return fmt.Errorf("memstore: student %d does not exist", studentID)
+	}
+
+	stored := clone(row)
+	if existing, ok := table[studentID]; ok {
+		*id(stored) = *id(existing)
+	} else {
+		*id(stored) = s.nextID()
+	}
+	table[studentID] = stored
+	return nil
+}
+
+// getSection returns nil, nil for a section the student has not filled in
+func getSection[T any](s *Store, table map[int64]*T, studentID int64) (*T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return clone(table[studentID]), nil
+}
+
+func (m studentStore) SyncAcademics(_ context.Context, rec *models.AcademicSync) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var st *models.Student
+	for _, candidate := range m.s.students {
+		if candidate.OfficialEmail == rec.Email && inInstitution(rec.InstitutionID, candidate.InstitutionID) {
+			st = candidate
+		}
+	}
+	if st == nil {
+		return 0, models.ErrRecordNotFound
+	}
+
+	if rec.RollNo != nil {
+		for _, other := range m.s.students {
+			if other.ID != st.ID && other.InstitutionID == st.InstitutionID && other.RollNo != nil && *other.RollNo == *rec.RollNo {
+				return 0, models.ErrDuplicateRollNo
+			}
+		}
+		st.RollNo = clone(rec.RollNo)
+	}
+	if rec.Department != nil {
+		st.Department = clone(rec.Department)
+	}
+	st.Version++
+
+	a, ok := m.s.academics[st.ID]
+	if !ok {
+		a = &models.StudentAcademics{ID: m.s.nextID(), StudentID: st.ID}
+		m.s.academics[st.ID] = a
+	}
+	sems := []**float64{&a.CGPASem1, &a.CGPASem2, &a.CGPASem3, &a.CGPASem4, &a.CGPASem5, &a.CGPASem6, &a.CGPASem7, &a.CGPASem8}
+	for i, sem := range rec.CGPASems {
+		if sem != nil {
+			*sems[i] = clone(sem)
+		}
+	}
+	if rec.CGPAOverall != nil {
+		a.CGPAOverall = clone(rec.CGPAOverall)
+	}
+	if rec.CurrentBacklogs != nil {
+		a.CurrentBacklogs = *rec.CurrentBacklogs
+	}
+
+	return st.ID, nil
+}
+
+func (m studentStore) UpsertSkills(_ context.Context, studentID int64, skills []models.StudentSkill) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	st, ok := m.s.students[studentID]
+	if !ok {
+		delete(m.s.studentSkills, studentID)
+		return nil
+	}
+
+	var rows []models.StudentSkill
+	seen := make(map[int]bool)
+	for _, skill := range skills {
+		// Skills of another institution are silently skipped
+		sk, ok := m.s.skills[skill.SkillID]
+		if !ok || sk.InstitutionID != st.InstitutionID {
+			continue
+		}
+		if seen[sk.ID] {
+			return fmt.Errorf("memstore: skill %d is listed twice for student %d", sk.ID, studentID)
+		}
+		seen[sk.ID] = true
+		rows = append(rows, models.StudentSkill{
+			ID: m.s.nextID(), StudentID: studentID, SkillID: sk.ID,
+			Proficiency: skill.Proficiency, YearsOfExperience: skill.YearsOfExperience,
+		})
+	}
+
+	m.s.studentSkills[studentID] = rows
+	return nil
+}
+
+func (m studentStore) GetSkills(_ context.Context, studentID int64) ([]models.StudentSkill, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var skills []models.StudentSkill
+	for _, row := range m.s.studentSkills[studentID] {
+		sk := m.s.skills[row.SkillID]
+		row.SkillName, row.SkillCategory = sk.Name, sk.Category
+		skills = append(skills, row)
+	}
+	sortByCatalogue(skills, m.s.skills)
+	return skills, nil
+}
+
+func sortByCatalogue(rows []models.StudentSkill, catalogue map[int]*models.Skill) {
+	slices.SortFunc(rows, func(a, b models.StudentSkill) int {
+		return compareSkills(catalogue[a.SkillID], catalogue[b.SkillID])
+	})
+}
+
+func (m studentStore) GetFullProfile(ctx context.Context, studentID int64) (*models.StudentFullProfile, error) {
+	student, err := m.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &models.StudentFullProfile{Student: *student}
+	profile.Personal, _ = m.GetPersonalDetails(ctx, studentID)
+	profile.Family, _ = m.GetFamilyDetails(ctx, studentID)
+	profile.Academics, _ = m.GetAcademics(ctx, studentID)
+	profile.Achievements, _ = m.GetAchievements(ctx, studentID)
+	profile.Aspirations, _ = m.GetAspirations(ctx, studentID)
+	profile.Skills, _ = m.GetSkills(ctx, studentID)
+
+	return profile, nil
+}
+
+// ============================================
+// SEARCH & FILTER
+// ============================================
+
+// List applies the same filters as StudentModel.List. SkillIDs is ignored
+// there too.
+func (m studentStore) List(_ context.Context, filter models.StudentFilter) (*models.StudentListResult, error) {
+	if filter.PageSize < 1 || filter.PageSize > 100 {
+		filter.PageSize = 20
+	}
+	return m.list(filter)
+}
+
+func (m studentStore) Export(_ context.Context, filter models.StudentFilter) (*models.StudentListResult, error) {
+	filter.Page, filter.PageSize = 1, models.MaxExportRows
+	return m.list(filter)
+}
+
+func (m studentStore) list(filter models.StudentFilter) (*models.StudentListResult, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	byName := func(a, b *models.Student) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	}
+
+	var matches []models.StudentListItem
+	for _, st := range sorted(m.s.students, byName) {
+		year := m.s.batchYear(st)
+		academics := m.s.academics[st.ID]
+		var cgpa *float64
+		if academics != nil {
+			cgpa = academics.CGPAOverall
+		}
+
+		if !inInstitution(filter.InstitutionID, st.InstitutionID) {
+			continue
+		}
+		if filter.Search != "" && !containsFold(&st.Name, filter.Search) &&
+			!containsFold(st.RollNo, filter.Search) && !containsFold(&st.OfficialEmail, filter.Search) {
+			continue
+		}
+		if filter.BatchYear != nil && (year == nil || *year != *filter.BatchYear) {
+			continue
+		}
+		if filter.Department != nil && (st.Department == nil || *st.Department != *filter.Department) {
+			continue
+		}
+		if filter.PlacementStatus != nil && st.PlacementStatus != *filter.PlacementStatus {
+			continue
+		}
+		if filter.MinCGPA != nil && (cgpa == nil || *cgpa < *filter.MinCGPA) {
+			continue
+		}
+		if filter.MaxCGPA != nil && (cgpa == nil || *cgpa > *filter.MaxCGPA) {
+			continue
+		}
+		if filter.HasBacklogs != nil {
+			hasBacklogs := academics != nil && academics.CurrentBacklogs > 0
+			if hasBacklogs != *filter.HasBacklogs {
+				continue
+			}
+		}
+		if filter.ProfileCompleted != nil && st.IsProfileCompleted != *filter.ProfileCompleted {
+			continue
+		}
+
+		item := models.StudentListItem{
+			ID: st.ID, Name: st.Name, OfficialEmail: st.OfficialEmail, RollNo: clone(st.RollNo),
+			BatchYear: year, Department: clone(st.Department), PhotoURL: clone(st.PhotoURL),
+			IsProfileCompleted: st.IsProfileCompleted, PlacementStatus: st.PlacementStatus,
+			CGPAOverall: clone(cgpa),
+		}
+		if personal := m.s.personal[st.ID]; personal != nil {
+			item.MobileNumber = clone(personal.MobileNumber)
+		}
+		if p := m.s.acceptedPlacement(st.ID); p != nil {
+			name := p.CompanyName
+			item.PlacedCompany = &name
+			item.PackageLPA = clone(p.PackageLPA)
+		}
+		matches = append(matches, item)
+	}
+
+	total := len(matches)
+	start := min((filter.Page-1)*filter.PageSize, total)
+	end := min(start+filter.PageSize, total)
+
+	var students []models.StudentListItem
+	if start < end {
+		students = matches[start:end]
+	}
+
+	return &models.StudentListResult{
+		Students:   students,
+		Total:      total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+		TotalPages: (total + filter.PageSize - 1) / filter.PageSize,
+	}, nil
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+func byStudentID(a, b *models.Student) int {
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// studentView copies a student with its batch year filled in
+func (s *Store) studentView(st *models.Student) *models.Student {
+	c := clone(st)
+	c.BatchYear = s.batchYear(st)
+	return c
+}
+
+// acceptedPlacement is the student's latest accepted offer, if any
+func (s *Store) acceptedPlacement(studentID int64) *models.PlacementRecord {
+	var latest *models.PlacementRecord
+	for _, p := range s.placements {
+		if p.StudentID != studentID || !p.IsAccepted {
+			continue
+		}
+		if latest == nil || p.CreatedAt.After(latest.CreatedAt) || (p.CreatedAt.Equal(latest.CreatedAt) && p.ID > latest.ID) {
+			latest = p
+		}
+	}
+	return latest
+}
+
+func (m studentStore) RecomputeEligibility(_ context.Context, rule models.EligibilityRule) (int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	var changed int64
+	for _, st := range m.s.students {
+		eligible := st.IsProfileCompleted
+		if a := m.s.academics[st.ID]; a != nil {
+			eligible = eligible && a.CurrentBacklogs <= rule.MaxBacklogs
+			if rule.MinCGPA > 0 {
+				eligible = eligible && a.CGPAOverall != nil && *a.CGPAOverall >= rule.MinCGPA
+			}
+		} else if rule.MinCGPA > 0 {
+			eligible = false
+		}
+
+		if st.IsEligibleForPlacement != eligible {
+			st.IsEligibleForPlacement = eligible
+			changed++
+		}
+	}
+	return changed, nil
+}
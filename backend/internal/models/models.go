@@ -10,26 +10,43 @@ var (
 	ErrEditConflict    = errors.New("edit conflict")
 	ErrDuplicateEmail  = errors.New("duplicate email")
 	ErrDuplicateRollNo = errors.New("duplicate roll number")
+	ErrDuplicateCode   = errors.New("duplicate code")
+	ErrDuplicateDomain = errors.New("duplicate domain")
+
+	ErrRefreshTokenReuse = errors.New("refresh token reuse detected")
+	ErrLastSuperAdmin    = errors.New("cannot remove the last active super-admin")
 )
 
 type Models struct {
-	Students   StudentModel
-	Admins     AdminModel
-	Skills     SkillModel
-	Companies  CompanyModel
-	Placements PlacementModel
-	Analytics  AnalyticsModel
-	DB         *sql.DB
+	Institutions InstitutionStore
+	Students     StudentStore
+	Admins       AdminStore
+	Skills       SkillStore
+	Companies    CompanyStore
+	Placements   PlacementStore
+	Analytics    AnalyticsStore
+	Sessions     SessionStore
+	Activity     ActivityStore
+	AuthCodes    AuthCodeStore
+	APIKeys      APIKeyStore
+	// DB is nil when the stores are not backed by Postgres
+	DB *sql.DB
 }
 
-func NewModels(db *sql.DB) Models {
+// NewModels wires the Postgres models to db
+func NewModels(db *DB) Models {
 	return Models{
-		Students:   StudentModel{DB: db},
-		Admins:     AdminModel{DB: db},
-		Skills:     SkillModel{DB: db},
-		Companies:  CompanyModel{DB: db},
-		Placements: PlacementModel{DB: db},
-		Analytics:  AnalyticsModel{DB: db},
-		DB:         db,
+		Institutions: InstitutionModel{DB: db},
+		Students:     StudentModel{DB: db},
+		Admins:       AdminModel{DB: db},
+		Skills:       SkillModel{DB: db},
+		Companies:    CompanyModel{DB: db},
+		Placements:   PlacementModel{DB: db},
+		Analytics:    AnalyticsModel{DB: db},
+		Sessions:     SessionModel{DB: db},
+		Activity:     ActivityLogModel{DB: db},
+		AuthCodes:    AuthCodeModel{DB: db},
+		APIKeys:      APIKeyModel{DB: db},
+		DB:           db.DB,
 	}
 }
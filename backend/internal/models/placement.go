@@ -30,11 +30,11 @@ type PlacementRecord struct {
 }
 
 type PlacementModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
 // GetByStudentID retrieves placement record for a student
-func (m PlacementModel) GetByStudentID(studentID int64) (*PlacementRecord, error) {
+func (m PlacementModel) GetByStudentID(ctx context.Context, studentID int64) (*PlacementRecord, error) {
 	query := `
 		SELECT id, student_id, company_id, 
 		       COALESCE(company_name, (SELECT name FROM companies WHERE id = company_id)),
@@ -47,7 +47,7 @@ func (m PlacementModel) GetByStudentID(studentID int64) (*PlacementRecord, error
 		LIMIT 1`
 
 	var p PlacementRecord
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -67,8 +67,27 @@ func (m PlacementModel) GetByStudentID(studentID int64) (*PlacementRecord, error
 	return &p, nil
 }
 
+// GetStudentID returns the student a placement record belongs to
+func (m PlacementModel) GetStudentID(ctx context.Context, id int64) (int64, error) {
+	query := `SELECT student_id FROM placements WHERE id = $1`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	var studentID int64
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&studentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return studentID, nil
+}
+
 // Insert creates a new placement record
-func (m PlacementModel) Insert(p *PlacementRecord) error {
+func (m PlacementModel) Insert(ctx context.Context, p *PlacementRecord) error {
 	query := `
 		INSERT INTO placements (
 			student_id, company_id, company_name, job_role, package_lpa, package_ctc,
@@ -77,7 +96,7 @@ func (m PlacementModel) Insert(p *PlacementRecord) error {
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	var joiningDate, offerDate interface{}
@@ -96,7 +115,7 @@ func (m PlacementModel) Insert(p *PlacementRecord) error {
 }
 
 // Update updates a placement record
-func (m PlacementModel) Update(p *PlacementRecord) error {
+func (m PlacementModel) Update(ctx context.Context, p *PlacementRecord) error {
 	query := `
 		UPDATE placements
 		SET company_id = $1, company_name = $2, job_role = $3, package_lpa = $4,
@@ -105,7 +124,7 @@ func (m PlacementModel) Update(p *PlacementRecord) error {
 		WHERE id = $13
 		RETURNING updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	var joiningDate, offerDate interface{}
@@ -124,22 +143,22 @@ func (m PlacementModel) Update(p *PlacementRecord) error {
 }
 
 // Delete deletes a placement record
-func (m PlacementModel) Delete(id int64) error {
+func (m PlacementModel) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM placements WHERE id = $1`
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 	_, err := m.DB.ExecContext(ctx, query, id)
 	return err
 }
 
 // Verify marks a placement as verified by admin
-func (m PlacementModel) Verify(id int64, adminID int64) error {
+func (m PlacementModel) Verify(ctx context.Context, id int64, adminID int64) error {
 	query := `
 		UPDATE placements
 		SET verified_by = $1, verified_at = NOW()
 		WHERE id = $2`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 	_, err := m.DB.ExecContext(ctx, query, adminID, id)
 	return err
@@ -155,7 +174,8 @@ type PlacementWithStudent struct {
 	BatchYear       *int    `json:"batch_year"`
 }
 
-func (m PlacementModel) GetAll() ([]PlacementWithStudent, error) {
+// GetAll lists accepted placements, optionally limited to one institution and department
+func (m PlacementModel) GetAll(ctx context.Context, institutionID *int64, department *string) ([]PlacementWithStudent, error) {
 	query := `
 		SELECT p.id, p.student_id, p.company_id,
 		       COALESCE(p.company_name, c.name), p.job_role, p.package_lpa,
@@ -167,13 +187,14 @@ func (m PlacementModel) GetAll() ([]PlacementWithStudent, error) {
 		JOIN students s ON p.student_id = s.id
 		LEFT JOIN companies c ON p.company_id = c.id
 		LEFT JOIN batches b ON s.batch_id = b.id
-		WHERE p.is_accepted = true
+		WHERE p.is_accepted = true AND ($1::text IS NULL OR s.department = $1)
+		  AND ($2::int IS NULL OR s.institution_id = $2)
 		ORDER BY p.created_at DESC`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, department, institutionID)
 	if err != nil {
 		return nil, err
 	}
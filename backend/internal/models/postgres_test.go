@@ -0,0 +1,47 @@
+//go:build integration
+
+package models_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/data"
+	"github.com/VJ-2303/placement-profiling-system/internal/migrate"
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/migrations"
+)
+
+// TestPostgres runs the store tests against a real database. Point
+// TEST_DATABASE_URL at a database used only for tests: it is migrated to the
+// latest version, and the rows the tests create are left behind.
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags integration ./internal/models/
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := data.OpenDB(dsn, data.PoolConfig{MaxOpenConns: 5, MaxIdleConns: 5, MaxIdleTime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := migrate.New(db, list).Up(ctx); err != nil {
+		t.Fatalf("migrating test database: %v", err)
+	}
+
+	runStoreTests(t, func(t *testing.T) models.Models {
+		return models.NewModels(&models.DB{DB: db})
+	})
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Session represents a login session backed by a rotating refresh token
+type Session struct {
+	ID         int64      `json:"id"`
+	UserType   string     `json:"user_type"` // student or admin
+	UserID     int64      `json:"user_id"`
+	UserAgent  *string    `json:"user_agent"`
+	IPAddress  *string    `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+}
+
+type SessionModel struct {
+	DB *DB
+}
+
+// Insert creates a new session for the given refresh token hash
+func (m SessionModel) Insert(ctx context.Context, session *Session, tokenHash []byte) error {
+	query := `
+		INSERT INTO sessions (user_type, user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, last_used_at`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query,
+		session.UserType, session.UserID, tokenHash, session.UserAgent,
+		session.IPAddress, session.ExpiresAt,
+	).Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt)
+}
+
+// Rotate swaps the session's refresh token for a new one. Presenting a token
+// that was already rotated out revokes the whole session, since it means the
+// token was copied by someone else.
+func (m SessionModel) Rotate(ctx context.Context, oldHash, newHash []byte, expiresAt time.Time) (*Session, error) {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	query := `
+		UPDATE sessions
+		SET refresh_token_hash = $1, previous_token_hash = refresh_token_hash,
+		    expires_at = $2, last_used_at = NOW()
+		WHERE refresh_token_hash = $3 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_type, user_id, user_agent, ip_address, expires_at,
+		          revoked_at, created_at, last_used_at`
+
+	var s Session
+	err := m.DB.QueryRowContext(ctx, query, newHash, expiresAt, oldHash).Scan(
+		&s.ID, &s.UserType, &s.UserID, &s.UserAgent, &s.IPAddress, &s.ExpiresAt,
+		&s.RevokedAt, &s.CreatedAt, &s.LastUsedAt,
+	)
+	if err == nil {
+		return &s, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	// Not a current token - check whether it is a rotated-out one
+	reuseQuery := `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE previous_token_hash = $1 AND revoked_at IS NULL`
+
+	result, err := m.DB.ExecContext(ctx, reuseQuery, oldHash)
+	if err != nil {
+		return nil, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if rowsAffected > 0 {
+		return nil, ErrRefreshTokenReuse
+	}
+
+	return nil, ErrRecordNotFound
+}
+
+// IsActive reports whether a session exists, is not revoked and has not expired
+func (m SessionModel) IsActive(ctx context.Context, id int64) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM sessions
+			WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		)`
+
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
+	defer cancel()
+
+	var active bool
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(&active)
+	return active, err
+}
+
+// Revoke revokes a single session
+func (m SessionModel) Revoke(ctx context.Context, id int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+	_, err := m.DB.ExecContext(ctx, query, id)
+	return err
+}
+
+// RevokeAllForUser revokes every active session of a user and returns how many were revoked
+func (m SessionModel) RevokeAllForUser(ctx context.Context, userType string, userID int64) (int64, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_type = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, userType, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// DeleteExpired removes sessions that expired or were revoked before the cutoff
+func (m SessionModel) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE expires_at < $1 OR (revoked_at IS NOT NULL AND revoked_at < $1)`
+
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -4,35 +4,35 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"time"
 )
 
 // Skill represents a master skill entry
 type Skill struct {
-	ID           int           `json:"id"`
-	Name         string        `json:"name"`
-	Category     SkillCategory `json:"category"`
-	Description  *string       `json:"description"`
-	IsActive     bool          `json:"is_active"`
-	DisplayOrder int           `json:"display_order"`
+	ID            int           `json:"id"`
+	InstitutionID int64         `json:"institution_id"`
+	Name          string        `json:"name"`
+	Category      SkillCategory `json:"category"`
+	Description   *string       `json:"description"`
+	IsActive      bool          `json:"is_active"`
+	DisplayOrder  int           `json:"display_order"`
 }
 
 type SkillModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
-// GetAll retrieves all active skills
-func (m SkillModel) GetAll() ([]Skill, error) {
+// GetAll retrieves all active skills of an institution
+func (m SkillModel) GetAll(ctx context.Context, institutionID int64) ([]Skill, error) {
 	query := `
-		SELECT id, name, category, description, is_active, display_order
+		SELECT id, institution_id, name, category, description, is_active, display_order
 		FROM skills
-		WHERE is_active = true
+		WHERE is_active = true AND institution_id = $1
 		ORDER BY category, display_order`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +41,7 @@ func (m SkillModel) GetAll() ([]Skill, error) {
 	var skills []Skill
 	for rows.Next() {
 		var s Skill
-		if err := rows.Scan(&s.ID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder); err != nil {
+		if err := rows.Scan(&s.ID, &s.InstitutionID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder); err != nil {
 			return nil, err
 		}
 		skills = append(skills, s)
@@ -50,18 +50,18 @@ func (m SkillModel) GetAll() ([]Skill, error) {
 	return skills, rows.Err()
 }
 
-// GetByCategory retrieves skills by category
-func (m SkillModel) GetByCategory(category SkillCategory) ([]Skill, error) {
+// GetByCategory retrieves an institution's skills by category
+func (m SkillModel) GetByCategory(ctx context.Context, institutionID int64, category SkillCategory) ([]Skill, error) {
 	query := `
-		SELECT id, name, category, description, is_active, display_order
+		SELECT id, institution_id, name, category, description, is_active, display_order
 		FROM skills
-		WHERE category = $1 AND is_active = true
+		WHERE category = $1 AND is_active = true AND institution_id = $2
 		ORDER BY display_order`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query, category)
+	rows, err := m.DB.QueryContext(ctx, query, category, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +70,7 @@ func (m SkillModel) GetByCategory(category SkillCategory) ([]Skill, error) {
 	var skills []Skill
 	for rows.Next() {
 		var s Skill
-		if err := rows.Scan(&s.ID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder); err != nil {
+		if err := rows.Scan(&s.ID, &s.InstitutionID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder); err != nil {
 			return nil, err
 		}
 		skills = append(skills, s)
@@ -79,9 +79,9 @@ func (m SkillModel) GetByCategory(category SkillCategory) ([]Skill, error) {
 	return skills, rows.Err()
 }
 
-// GetGroupedByCategory retrieves skills grouped by category
-func (m SkillModel) GetGroupedByCategory() (map[SkillCategory][]Skill, error) {
-	skills, err := m.GetAll()
+// GetGroupedByCategory retrieves an institution's skills grouped by category
+func (m SkillModel) GetGroupedByCategory(ctx context.Context, institutionID int64) (map[SkillCategory][]Skill, error) {
+	skills, err := m.GetAll(ctx, institutionID)
 	if err != nil {
 		return nil, err
 	}
@@ -95,18 +95,18 @@ func (m SkillModel) GetGroupedByCategory() (map[SkillCategory][]Skill, error) {
 }
 
 // GetByID retrieves a skill by ID
-func (m SkillModel) GetByID(id int) (*Skill, error) {
+func (m SkillModel) GetByID(ctx context.Context, id int) (*Skill, error) {
 	query := `
-		SELECT id, name, category, description, is_active, display_order
+		SELECT id, institution_id, name, category, description, is_active, display_order
 		FROM skills
 		WHERE id = $1`
 
 	var s Skill
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&s.ID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder,
+		&s.ID, &s.InstitutionID, &s.Name, &s.Category, &s.Description, &s.IsActive, &s.DisplayOrder,
 	)
 
 	if err != nil {
@@ -120,23 +120,23 @@ func (m SkillModel) GetByID(id int) (*Skill, error) {
 }
 
 // Insert adds a new skill
-func (m SkillModel) Insert(skill *Skill) error {
+func (m SkillModel) Insert(ctx context.Context, skill *Skill) error {
 	query := `
-		INSERT INTO skills (name, category, description, display_order)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO skills (institution_id, name, category, description, display_order)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	return m.DB.QueryRowContext(ctx, query,
-		skill.Name, skill.Category, skill.Description, skill.DisplayOrder,
+		skill.InstitutionID, skill.Name, skill.Category, skill.Description, skill.DisplayOrder,
 	).Scan(&skill.ID)
 }
 
-// GetAsMap returns a map of skill name to ID
-func (m SkillModel) GetAsMap() (map[string]int, error) {
-	skills, err := m.GetAll()
+// GetAsMap returns a map of an institution's skill names to IDs
+func (m SkillModel) GetAsMap(ctx context.Context, institutionID int64) (map[string]int, error) {
+	skills, err := m.GetAll(ctx, institutionID)
 	if err != nil {
 		return nil, err
 	}
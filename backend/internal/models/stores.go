@@ -0,0 +1,155 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// The stores below are what handlers depend on. The Postgres models in this
+// package implement them for production; the memstore package implements
+// them in memory so handlers can be tested without a database. Handlers pass
+// the request context, so a cancelled request stops its queries.
+
+// StudentStore reads and writes students and every section of their profile
+type StudentStore interface {
+	Insert(ctx context.Context, student *Student) error
+	GetByEmail(ctx context.Context, email string) (*Student, error)
+	GetByID(ctx context.Context, id int64) (*Student, error)
+	GetByRollNo(ctx context.Context, institutionID *int64, rollNo string) (*Student, error)
+	UpdateBasicInfo(ctx context.Context, student *Student) error
+	SetProfileCompleted(ctx context.Context, studentID int64) error
+	UpdateLastLogin(ctx context.Context, id int64) error
+	UpdatePlacementStatus(ctx context.Context, studentID int64, status PlacementStatus) error
+	GetBatchIDByYear(ctx context.Context, institutionID int64, year int) (int, error)
+
+	UpsertPersonalDetails(ctx context.Context, details *StudentPersonalDetails) error
+	GetPersonalDetails(ctx context.Context, studentID int64) (*StudentPersonalDetails, error)
+	UpsertFamilyDetails(ctx context.Context, details *StudentFamilyDetails) error
+	GetFamilyDetails(ctx context.Context, studentID int64) (*StudentFamilyDetails, error)
+	UpsertAcademics(ctx context.Context, a *StudentAcademics) error
+	GetAcademics(ctx context.Context, studentID int64) (*StudentAcademics, error)
+	SyncAcademics(ctx context.Context, rec *AcademicSync) (int64, error)
+	UpsertAchievements(ctx context.Context, a *StudentAchievements) error
+	GetAchievements(ctx context.Context, studentID int64) (*StudentAchievements, error)
+	UpsertAspirations(ctx context.Context, a *StudentAspirations) error
+	GetAspirations(ctx context.Context, studentID int64) (*StudentAspirations, error)
+	UpsertSkills(ctx context.Context, studentID int64, skills []StudentSkill) error
+	GetSkills(ctx context.Context, studentID int64) ([]StudentSkill, error)
+	GetFullProfile(ctx context.Context, studentID int64) (*StudentFullProfile, error)
+
+	List(ctx context.Context, filter StudentFilter) (*StudentListResult, error)
+	// Export is List under the export timeout, for downloads of a whole cohort
+	Export(ctx context.Context, filter StudentFilter) (*StudentListResult, error)
+	RecomputeEligibility(ctx context.Context, rule EligibilityRule) (int64, error)
+}
+
+// PlacementStore manages placement offers
+type PlacementStore interface {
+	GetByStudentID(ctx context.Context, studentID int64) (*PlacementRecord, error)
+	GetStudentID(ctx context.Context, id int64) (int64, error)
+	GetAll(ctx context.Context, institutionID *int64, department *string) ([]PlacementWithStudent, error)
+	Insert(ctx context.Context, p *PlacementRecord) error
+	Update(ctx context.Context, p *PlacementRecord) error
+	Delete(ctx context.Context, id int64) error
+	Verify(ctx context.Context, id int64, adminID int64) error
+}
+
+// CompanyStore manages the recruiting company master list
+type CompanyStore interface {
+	GetAll(ctx context.Context, institutionID *int64) ([]Company, error)
+	GetByID(ctx context.Context, id int64) (*Company, error)
+	Search(ctx context.Context, institutionID *int64, search string) ([]Company, error)
+	Insert(ctx context.Context, c *Company) error
+	Update(ctx context.Context, c *Company) error
+	Delete(ctx context.Context, institutionID *int64, id int64) error
+}
+
+// SkillStore reads an institution's skill catalogue
+type SkillStore interface {
+	GetAll(ctx context.Context, institutionID int64) ([]Skill, error)
+	GetByCategory(ctx context.Context, institutionID int64, category SkillCategory) ([]Skill, error)
+	GetGroupedByCategory(ctx context.Context, institutionID int64) (map[SkillCategory][]Skill, error)
+	GetByID(ctx context.Context, id int) (*Skill, error)
+	GetAsMap(ctx context.Context, institutionID int64) (map[string]int, error)
+	Insert(ctx context.Context, skill *Skill) error
+}
+
+// AnalyticsStore computes the dashboard and report aggregates. A non-nil
+// department limits them to that department's students.
+type AnalyticsStore interface {
+	GetDashboardStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) (*DashboardStats, error)
+	GetBatchWiseStats(ctx context.Context, institutionID *int64, department *string) ([]BatchStats, error)
+	GetSkillStats(ctx context.Context, institutionID *int64, department *string) ([]SkillStats, error)
+	GetCGPADistribution(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CGPADistribution, error)
+	GetCompanyStats(ctx context.Context, institutionID *int64, department *string, batchYear *int) ([]CompanyStats, error)
+	GetRecentActivity(ctx context.Context, institutionID *int64, department *string, limit int) ([]RecentActivity, error)
+	GetBatches(ctx context.Context, institutionID *int64) ([]Batch, error)
+	GetInstitutionTotals(ctx context.Context) ([]InstitutionTotals, error)
+	SaveDashboardSnapshot(ctx context.Context, institutionID int64, day time.Time, stats *DashboardStats) error
+	GetDashboardSnapshots(ctx context.Context, institutionID int64, since time.Time) ([]DashboardSnapshot, error)
+}
+
+// InstitutionStore manages the colleges of the group and their domains
+type InstitutionStore interface {
+	GetByID(ctx context.Context, id int64) (*Institution, error)
+	GetByDomain(ctx context.Context, domain string) (*Institution, error)
+	GetAll(ctx context.Context) ([]*Institution, error)
+	Insert(ctx context.Context, inst *Institution) error
+	Update(ctx context.Context, inst *Institution) error
+}
+
+// AdminStore manages pre-registered admins
+type AdminStore interface {
+	GetByEmail(ctx context.Context, email string) (*Admin, error)
+	GetByID(ctx context.Context, id int64) (*Admin, error)
+	GetAll(ctx context.Context, institutionID *int64) ([]Admin, error)
+	Insert(ctx context.Context, admin *Admin) error
+	Update(ctx context.Context, admin *Admin) error
+}
+
+// SessionStore manages refresh-token sessions
+type SessionStore interface {
+	Insert(ctx context.Context, session *Session, tokenHash []byte) error
+	Rotate(ctx context.Context, oldHash, newHash []byte, expiresAt time.Time) (*Session, error)
+	IsActive(ctx context.Context, id int64) (bool, error)
+	Revoke(ctx context.Context, id int64) error
+	RevokeAllForUser(ctx context.Context, userType string, userID int64) (int64, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ActivityStore records and reads the audit log
+type ActivityStore interface {
+	Insert(ctx context.Context, log *ActivityLog) error
+	GetByEntityType(ctx context.Context, institutionID *int64, entityType string, limit int) ([]ActivityLog, error)
+}
+
+// AuthCodeStore holds one-time login codes
+type AuthCodeStore interface {
+	Insert(ctx context.Context, codeHash []byte, code *AuthCode) error
+	Consume(ctx context.Context, codeHash []byte) (*AuthCode, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// APIKeyStore manages service account keys
+type APIKeyStore interface {
+	Insert(ctx context.Context, key *APIKey, keyHash []byte) error
+	GetActiveByHash(ctx context.Context, keyHash []byte) (*APIKey, error)
+	GetAll(ctx context.Context, institutionID *int64) ([]*APIKey, error)
+	Revoke(ctx context.Context, institutionID *int64, id int64) error
+	TouchLastUsed(ctx context.Context, id int64, ip string) error
+}
+
+// Compile-time checks that the Postgres models satisfy their stores
+var (
+	_ StudentStore     = StudentModel{}
+	_ PlacementStore   = PlacementModel{}
+	_ CompanyStore     = CompanyModel{}
+	_ SkillStore       = SkillModel{}
+	_ AnalyticsStore   = AnalyticsModel{}
+	_ InstitutionStore = InstitutionModel{}
+	_ AdminStore       = AdminModel{}
+	_ SessionStore     = SessionModel{}
+	_ ActivityStore    = ActivityLogModel{}
+	_ AuthCodeStore    = AuthCodeModel{}
+	_ APIKeyStore      = APIKeyModel{}
+)
@@ -0,0 +1,650 @@
+package models_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/models"
+	"github.com/VJ-2303/placement-profiling-system/internal/models/memstore"
+)
+
+// The store tests run against memstore here and against Postgres in
+// postgres_test.go, so the fake cannot drift from the real queries. Every
+// test works inside an institution of its own, which keeps them independent
+// of each other and of whatever else is in the database.
+
+func TestMemStore(t *testing.T) {
+	runStoreTests(t, func(t *testing.T) models.Models {
+		return memstore.New().Models()
+	})
+}
+
+func runStoreTests(t *testing.T, newModels func(t *testing.T) models.Models) {
+	tests := map[string]func(t *testing.T, m models.Models){
+		"ProfileUpserts": testProfileUpserts,
+		"StudentSkills":  testStudentSkills,
+		"List":           testList,
+		"Eligibility":    testEligibility,
+		"Analytics":      testAnalytics,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test(t, newModels(t))
+		})
+	}
+}
+
+// ============================================
+// PROFILE
+// ============================================
+
+func testProfileUpserts(t *testing.T, m models.Models) {
+	ctx := t.Context()
+	inst := newInstitution(t, m)
+	student := newStudent(t, m, inst, "Meera Nair")
+
+	personal, err := m.Students.GetPersonalDetails(ctx, student.ID)
+	if err != nil || personal != nil {
+		t.Fatalf("GetPersonalDetails before any save = %+v, %v; want nil, nil", personal, err)
+	}
+
+	err = m.Students.UpsertPersonalDetails(ctx, &models.StudentPersonalDetails{
+		StudentID: student.ID, DateOfBirth: ptr("2004-03-09"), Gender: ptr("female"),
+		MobileNumber: ptr("9876543210"), City: ptr("Coimbatore"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	personal, err = m.Students.GetPersonalDetails(ctx, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := deref(personal.DateOfBirth) + "|" + deref(personal.Gender) + "|" + deref(personal.City); got != "2004-03-09|female|Coimbatore" {
+		t.Errorf("personal details = %q", got)
+	}
+
+	first := &models.StudentAcademics{
+		StudentID: student.ID, TenthPercentage: ptr(94.5), TenthYear: ptr(2019),
+		CGPAOverall: ptr(8.75), CurrentBacklogs: 1, HistoryOfBacklogs: true, BacklogDetails: ptr("Maths II"),
+	}
+	if err := m.Students.UpsertAcademics(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := m.Students.GetAcademics(ctx, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.ID == 0 || deref(saved.CGPAOverall) != 8.75 || saved.CurrentBacklogs != 1 || deref(saved.BacklogDetails) != "Maths II" {
+		t.Fatalf("first save = %+v", saved)
+	}
+
+	// A second save replaces every column, including clearing omitted ones
+	second := &models.StudentAcademics{StudentID: student.ID, CGPAOverall: ptr(9.1)}
+	if err := m.Students.UpsertAcademics(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := m.Students.GetAcademics(ctx, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID != saved.ID {
+		t.Errorf("row ID changed from %d to %d; an upsert must update in place", saved.ID, updated.ID)
+	}
+	if deref(updated.CGPAOverall) != 9.1 || updated.TenthPercentage != nil || updated.CurrentBacklogs != 0 || updated.BacklogDetails != nil {
+		t.Errorf("second save = %+v", updated)
+	}
+
+	if err := m.Students.SetProfileCompleted(ctx, student.ID); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := m.Students.GetFullProfile(ctx, student.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !profile.Student.IsProfileCompleted || profile.Personal == nil || profile.Academics == nil || profile.Family != nil {
+		t.Errorf("full profile = %+v", profile)
+	}
+
+	stale := profile.Student
+	stale.Version--
+	if err := m.Students.UpdateBasicInfo(ctx, &stale); !errors.Is(err, models.ErrEditConflict) {
+		t.Errorf("UpdateBasicInfo with a stale version: got %v, want ErrEditConflict", err)
+	}
+}
+
+func testStudentSkills(t *testing.T, m models.Models) {
+	ctx := t.Context()
+	inst := newInstitution(t, m)
+	other := newInstitution(t, m)
+	student := newStudent(t, m, inst, "Ravi Shankar")
+	skills := skillIDs(t, m, inst)
+	foreign := skillIDs(t, m, other)
+
+	err := m.Students.UpsertSkills(ctx, student.ID, []models.StudentSkill{
+		{SkillID: skills["Go"], Proficiency: models.ProficiencyExpert},
+		{SkillID: skills["Python"], Proficiency: models.ProficiencyBeginner},
+		// Skills of another institution are dropped
+		{SkillID: foreign["Java"], Proficiency: models.ProficiencyAdvanced},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := skillNames(t, m, student.ID); !slices.Equal(got, []string{"Go:expert", "Python:beginner"}) {
+		t.Errorf("skills after first save = %v", got)
+	}
+
+	// Saving again replaces the whole list
+	err = m.Students.UpsertSkills(ctx, student.ID, []models.StudentSkill{
+		{SkillID: skills["Java"], Proficiency: models.ProficiencyIntermediate},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := skillNames(t, m, student.ID); !slices.Equal(got, []string{"Java:intermediate"}) {
+		t.Errorf("skills after second save = %v", got)
+	}
+}
+
+// ============================================
+// LIST
+// ============================================
+
+func testList(t *testing.T, m models.Models) {
+	ctx := t.Context()
+	c := newCohort(t, m)
+	// A student elsewhere must never show up
+	newStudent(t, m, newInstitution(t, m), "Asha Elsewhere")
+
+	tests := []struct {
+		name   string
+		filter models.StudentFilter
+		want   []string
+	}{
+		{"institution only", models.StudentFilter{}, []string{"Asha", "Bala", "Chitra", "Dinesh"}},
+		{"search name", models.StudentFilter{Search: "chit"}, []string{"Chitra"}},
+		{"search roll number", models.StudentFilter{Search: "cs00"}, []string{"Asha", "Chitra", "Dinesh"}},
+		{"batch year", models.StudentFilter{BatchYear: ptr(2025)}, []string{"Chitra"}},
+		{"department", models.StudentFilter{Department: ptr("ECE")}, []string{"Bala"}},
+		{"placement status", models.StudentFilter{PlacementStatus: ptr(models.PlacementStatusPlaced)}, []string{"Asha", "Chitra"}},
+		{"min cgpa", models.StudentFilter{MinCGPA: ptr(8.0)}, []string{"Asha", "Chitra"}},
+		{"max cgpa", models.StudentFilter{MaxCGPA: ptr(8.0)}, []string{"Bala"}},
+		{"with backlogs", models.StudentFilter{HasBacklogs: ptr(true)}, []string{"Bala"}},
+		{"without backlogs", models.StudentFilter{HasBacklogs: ptr(false)}, []string{"Asha", "Chitra", "Dinesh"}},
+		{"profile completed", models.StudentFilter{ProfileCompleted: ptr(true)}, []string{"Asha", "Chitra"}},
+		{"profile incomplete", models.StudentFilter{ProfileCompleted: ptr(false)}, []string{"Bala", "Dinesh"}},
+		{"combined", models.StudentFilter{Department: ptr("CSE"), BatchYear: ptr(2026), MinCGPA: ptr(9.0)}, []string{"Asha"}},
+		{"every filter", models.StudentFilter{Search: "cs00", BatchYear: ptr(2026), Department: ptr("CSE"), PlacementStatus: ptr(models.PlacementStatusPlaced),
+			MinCGPA: ptr(9.0), MaxCGPA: ptr(10.0), HasBacklogs: ptr(false), ProfileCompleted: ptr(true)}, []string{"Asha"}},
+		{"second page", models.StudentFilter{Page: 2, PageSize: 3}, []string{"Dinesh"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.InstitutionID = &c.inst
+			result, err := m.Students.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, s := range result.Students {
+				got = append(got, c.short[s.ID])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("students = %v, want %v", got, tt.want)
+			}
+			if tt.filter.Page == 0 && result.Total != len(tt.want) {
+				t.Errorf("total = %d, want %d", result.Total, len(tt.want))
+			}
+		})
+	}
+
+	result, err := m.Students.List(ctx, models.StudentFilter{InstitutionID: &c.inst, Page: 2, PageSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 4 || result.TotalPages != 2 || result.Page != 2 || result.PageSize != 3 {
+		t.Errorf("pagination = total %d, pages %d, page %d, size %d", result.Total, result.TotalPages, result.Page, result.PageSize)
+	}
+
+	result, err = m.Students.List(ctx, models.StudentFilter{InstitutionID: &c.inst, Search: "Asha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Students) != 1 {
+		t.Fatalf("search Asha returned %d students", len(result.Students))
+	}
+	asha := result.Students[0]
+	if deref(asha.PlacedCompany) != "Acme" || deref(asha.PackageLPA) != 12 || deref(asha.MobileNumber) != "9000000001" ||
+		deref(asha.BatchYear) != 2026 || deref(asha.CGPAOverall) != 9.1 {
+		t.Errorf("list item = %+v", asha)
+	}
+}
+
+func testEligibility(t *testing.T, m models.Models) {
+	ctx := t.Context()
+	c := newCohort(t, m)
+
+	eligible := func() []string {
+		t.Helper()
+		var names []string
+		for id, name := range c.short {
+			student, err := m.Students.GetByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if student.IsEligibleForPlacement {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	// The recompute covers every institution, so only this cohort's flags are checked
+	if _, err := m.Students.RecomputeEligibility(ctx, models.EligibilityRule{MinCGPA: 8.5}); err != nil {
+		t.Fatal(err)
+	}
+	if got := eligible(); !slices.Equal(got, []string{"Asha"}) {
+		t.Errorf("eligible with CGPA 8.5 = %v, want [Asha]", got)
+	}
+
+	// Bala has 2 backlogs and an incomplete profile, so allowing backlogs is not enough
+	if _, err := m.Students.RecomputeEligibility(ctx, models.EligibilityRule{MaxBacklogs: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if got := eligible(); !slices.Equal(got, []string{"Asha", "Chitra"}) {
+		t.Errorf("eligible without a CGPA cutoff = %v, want [Asha Chitra]", got)
+	}
+
+	changed, err := m.Students.RecomputeEligibility(ctx, models.EligibilityRule{MaxBacklogs: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 0 {
+		t.Errorf("running the same rule again changed %d students, want 0", changed)
+	}
+}
+
+// ============================================
+// ANALYTICS
+// ============================================
+
+func testAnalytics(t *testing.T, m models.Models) {
+	ctx := t.Context()
+	c := newCohort(t, m)
+	inst := &c.inst
+
+	stats, err := m.Analytics.GetDashboardStats(ctx, inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.DashboardStats{
+		TotalStudents: 4, ProfilesCompleted: 2, ProfileCompletionPct: 50,
+		StudentsPlaced: 2, StudentsNotPlaced: 1, StudentsInProcess: 1, PlacementPct: 50,
+		AvgPackage: 10, MaxPackage: 12, MinPackage: 8, TotalCompanies: 1,
+	}
+	if !dashboardEqual(*stats, want) {
+		t.Errorf("dashboard = %+v\nwant        %+v", *stats, want)
+	}
+
+	stats, err = m.Analytics.GetDashboardStats(ctx, inst, nil, ptr(2025))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalStudents != 1 || stats.StudentsPlaced != 1 || !near(stats.AvgPackage, 8) {
+		t.Errorf("dashboard for 2025 = %+v", *stats)
+	}
+
+	batches, err := m.Analytics.GetBatchWiseStats(ctx, inst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var years []int
+	byYear := make(map[int]models.BatchStats)
+	for _, b := range batches {
+		years = append(years, b.BatchYear)
+		byYear[b.BatchYear] = b
+	}
+	if !slices.Equal(years, []int{2027, 2026, 2025, 2024}) {
+		t.Errorf("batch years = %v", years)
+	}
+	if b := byYear[2026]; b.TotalStudents != 3 || b.ProfilesCompleted != 1 || b.StudentsPlaced != 1 ||
+		!near(b.PlacementPct, 33.33) || !near(b.AvgPackage, 12) || !near(b.MaxPackage, 12) {
+		t.Errorf("2026 batch = %+v", b)
+	}
+	if b := byYear[2024]; b.TotalStudents != 0 || b.PlacementPct != 0 {
+		t.Errorf("empty 2024 batch = %+v", b)
+	}
+
+	cgpa, err := m.Analytics.GetCGPADistribution(ctx, inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCGPA := []models.CGPADistribution{
+		{Range: "9.0 - 10.0", Count: 1, Placed: 1},
+		{Range: "8.0 - 8.99", Count: 1, Placed: 1},
+		{Range: "7.0 - 7.99", Count: 1},
+	}
+	if !slices.Equal(cgpa, wantCGPA) {
+		t.Errorf("cgpa distribution = %+v", cgpa)
+	}
+
+	companies, err := m.Analytics.GetCompanyStats(ctx, inst, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Globex was recorded by company ID, Acme by name only; the declined offer is left out
+	slices.SortFunc(companies, func(a, b models.CompanyStats) int { return cmpString(a.CompanyName, b.CompanyName) })
+	if len(companies) != 2 || companies[0].CompanyName != "Acme" || !near(companies[0].AvgPackage, 12) ||
+		companies[1].CompanyName != "Globex" || companies[1].HiredCount != 1 || !near(companies[1].MaxPackage, 8) {
+		t.Errorf("company stats = %+v", companies)
+	}
+
+	skills, err := m.Analytics.GetSkillStats(ctx, inst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSkills := []models.SkillStats{
+		{SkillName: "Go", Category: "programming_language", Beginners: 1, Intermediate: 1, Experts: 1, Total: 3},
+		{SkillName: "Python", Category: "programming_language", Advanced: 1, Total: 1},
+	}
+	if !slices.Equal(skills, wantSkills) {
+		t.Errorf("skill stats = %+v", skills)
+	}
+
+	// Limited to a department, every aggregate counts only Bala
+	ece := ptr("ECE")
+	stats, err = m.Analytics.GetDashboardStats(ctx, inst, ece, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalStudents != 1 || stats.StudentsInProcess != 1 || stats.StudentsPlaced != 0 || stats.MaxPackage != 0 {
+		t.Errorf("ECE dashboard = %+v", *stats)
+	}
+	batches, err = m.Analytics.GetBatchWiseStats(ctx, inst, ece)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range batches {
+		if want := map[int]int{2026: 1}[b.BatchYear]; b.TotalStudents != want {
+			t.Errorf("ECE batch %d has %d students, want %d", b.BatchYear, b.TotalStudents, want)
+		}
+	}
+	skills, err = m.Analytics.GetSkillStats(ctx, inst, ece)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 1 || skills[0].SkillName != "Go" || skills[0].Beginners != 1 || skills[0].Total != 1 {
+		t.Errorf("ECE skill stats = %+v", skills)
+	}
+	cgpa, err = m.Analytics.GetCGPADistribution(ctx, inst, ece, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cgpa, []models.CGPADistribution{{Range: "7.0 - 7.99", Count: 1}}) {
+		t.Errorf("ECE cgpa distribution = %+v", cgpa)
+	}
+	companies, err = m.Analytics.GetCompanyStats(ctx, inst, ece, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(companies) != 0 {
+		t.Errorf("ECE company stats = %+v", companies)
+	}
+	activity, err := m.Analytics.GetRecentActivity(ctx, inst, ece, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activity) != 1 || activity[0].StudentName != "Bala" {
+		t.Errorf("ECE recent activity = %+v", activity)
+	}
+
+	batchList, err := m.Analytics.GetBatches(ctx, inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	years = years[:0]
+	for _, b := range batchList {
+		years = append(years, b.Year)
+	}
+	if !slices.Equal(years, []int{2027, 2026, 2025, 2024}) {
+		t.Errorf("GetBatches years = %v", years)
+	}
+
+	// A second snapshot on the same day replaces the first
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	for _, snap := range []struct {
+		day   time.Time
+		total int
+	}{{day.AddDate(0, 0, -1), 2}, {day, 3}, {day, 4}} {
+		err := m.Analytics.SaveDashboardSnapshot(ctx, c.inst, snap.day, &models.DashboardStats{TotalStudents: snap.total})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := m.Analytics.GetDashboardSnapshots(ctx, c.inst, day.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var totals []int
+	for _, snap := range snapshots {
+		totals = append(totals, snap.Stats.TotalStudents)
+	}
+	if !slices.Equal(totals, []int{2, 4}) || !snapshots[1].TakenOn.Equal(day) {
+		t.Errorf("snapshots = %+v", snapshots)
+	}
+}
+
+// ============================================
+// FIXTURES
+// ============================================
+
+var fixtureSeq atomic.Int64
+
+// newInstitution creates an institution with a unique code. It starts with
+// the skills and batch years of the oldest institution.
+func newInstitution(t *testing.T, m models.Models) int64 {
+	t.Helper()
+	ctx := t.Context()
+	code := fmt.Sprintf("test%d-%d", time.Now().UnixNano(), fixtureSeq.Add(1))
+	inst := &models.Institution{Code: code, Name: "Test " + code, Domains: []string{code + ".test"}}
+	if err := m.Institutions.Insert(ctx, inst); err != nil {
+		t.Fatal(err)
+	}
+	return inst.ID
+}
+
+func newStudent(t *testing.T, m models.Models, inst int64, name string) *models.Student {
+	t.Helper()
+	ctx := t.Context()
+	email := fmt.Sprintf("student%d-%d@inst%d.test", time.Now().UnixNano(), fixtureSeq.Add(1), inst)
+	student := &models.Student{InstitutionID: inst, OfficialEmail: email, Name: name}
+	if err := m.Students.Insert(ctx, student); err != nil {
+		t.Fatal(err)
+	}
+	return student
+}
+
+func skillIDs(t *testing.T, m models.Models, inst int64) map[string]int {
+	t.Helper()
+	ctx := t.Context()
+	skills, err := m.Skills.GetAsMap(ctx, inst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Go", "Python", "Java"} {
+		if skills[name] == 0 {
+			t.Fatalf("institution %d has no %s skill to test with", inst, name)
+		}
+	}
+	return skills
+}
+
+func skillNames(t *testing.T, m models.Models, studentID int64) []string {
+	t.Helper()
+	ctx := t.Context()
+	skills, err := m.Students.GetSkills(ctx, studentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range skills {
+		names = append(names, s.SkillName+":"+string(s.Proficiency))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// cohort is four students of one institution, set up to exercise every List
+// filter and analytics query:
+//
+//	Asha    2026 CSE  CGPA 9.1  placed at Acme, 12 LPA   profile complete
+//	Bala    2026 ECE  CGPA 7.4  2 backlogs, in process
+//	Chitra  2025 CSE  CGPA 8.2  placed at Globex, 8 LPA  profile complete
+//	Dinesh  2026 CSE  no academics, declined an Initech offer
+type cohort struct {
+	inst  int64
+	short map[int64]string
+}
+
+func newCohort(t *testing.T, m models.Models) *cohort {
+	t.Helper()
+	ctx := t.Context()
+	c := &cohort{inst: newInstitution(t, m), short: make(map[int64]string)}
+	skills := skillIDs(t, m, c.inst)
+
+	globex := &models.Company{InstitutionID: c.inst, Name: "Globex"}
+	if err := m.Companies.Insert(ctx, globex); err != nil {
+		t.Fatal(err)
+	}
+
+	type seed struct {
+		name, roll, dept, mobile string
+		year                     int
+		cgpa                     *float64
+		backlogs                 int
+		status                   models.PlacementStatus
+		complete                 bool
+		placement                *models.PlacementRecord
+		skills                   []models.StudentSkill
+	}
+	seeds := []seed{
+		{
+			name: "Asha", roll: "22CS001", dept: "CSE", mobile: "9000000001", year: 2026, cgpa: ptr(9.1),
+			status: models.PlacementStatusPlaced, complete: true,
+			placement: &models.PlacementRecord{CompanyName: "Acme", PackageLPA: ptr(12.0), IsAccepted: true},
+			skills: []models.StudentSkill{
+				{SkillID: skills["Go"], Proficiency: models.ProficiencyExpert},
+				{SkillID: skills["Python"], Proficiency: models.ProficiencyAdvanced},
+			},
+		},
+		{
+			name: "Bala", roll: "22EC001", dept: "ECE", mobile: "9000000002", year: 2026, cgpa: ptr(7.4), backlogs: 2,
+			status: models.PlacementStatusInProcess,
+			skills: []models.StudentSkill{{SkillID: skills["Go"], Proficiency: models.ProficiencyBeginner}},
+		},
+		{
+			name: "Chitra", roll: "21CS003", dept: "CSE", mobile: "9000000003", year: 2025, cgpa: ptr(8.2),
+			status: models.PlacementStatusPlaced, complete: true,
+			placement: &models.PlacementRecord{CompanyID: &globex.ID, PackageLPA: ptr(8.0), IsAccepted: true},
+			skills:    []models.StudentSkill{{SkillID: skills["Go"], Proficiency: models.ProficiencyIntermediate}},
+		},
+		{
+			name: "Dinesh", roll: "22CS004", dept: "CSE", mobile: "9000000004", year: 2026,
+			status:    models.PlacementStatusNotPlaced,
+			placement: &models.PlacementRecord{CompanyName: "Initech", PackageLPA: ptr(20.0), IsAccepted: false},
+		},
+	}
+
+	for _, s := range seeds {
+		student := newStudent(t, m, c.inst, s.name)
+		c.short[student.ID] = s.name
+
+		batchID, err := m.Students.GetBatchIDByYear(ctx, c.inst, s.year)
+		if err != nil {
+			t.Fatalf("batch %d: %v", s.year, err)
+		}
+		student.RollNo, student.Department, student.BatchID = &s.roll, &s.dept, &batchID
+		if err := m.Students.UpdateBasicInfo(ctx, student); err != nil {
+			t.Fatal(err)
+		}
+
+		err = m.Students.UpsertPersonalDetails(ctx, &models.StudentPersonalDetails{StudentID: student.ID, MobileNumber: &s.mobile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.cgpa != nil {
+			err := m.Students.UpsertAcademics(ctx, &models.StudentAcademics{
+				StudentID: student.ID, CGPAOverall: s.cgpa, CurrentBacklogs: s.backlogs, HistoryOfBacklogs: s.backlogs > 0,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := m.Students.UpsertSkills(ctx, student.ID, s.skills); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.Students.UpdatePlacementStatus(ctx, student.ID, s.status); err != nil {
+			t.Fatal(err)
+		}
+		if s.complete {
+			if err := m.Students.SetProfileCompleted(ctx, student.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if s.placement != nil {
+			s.placement.StudentID = student.ID
+			if err := m.Placements.Insert(ctx, s.placement); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	return c
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+// near compares aggregates that Postgres computes in NUMERIC
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func cmpString(a, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func dashboardEqual(a, b models.DashboardStats) bool {
+	return a.TotalStudents == b.TotalStudents && a.ProfilesCompleted == b.ProfilesCompleted &&
+		a.StudentsPlaced == b.StudentsPlaced && a.StudentsNotPlaced == b.StudentsNotPlaced &&
+		a.StudentsInProcess == b.StudentsInProcess && a.HigherStudies == b.HigherStudies &&
+		a.TotalCompanies == b.TotalCompanies && near(a.ProfileCompletionPct, b.ProfileCompletionPct) &&
+		near(a.PlacementPct, b.PlacementPct) && near(a.AvgPackage, b.AvgPackage) &&
+		near(a.MaxPackage, b.MaxPackage) && near(a.MinPackage, b.MinPackage)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -48,12 +49,14 @@ const (
 
 type Student struct {
 	ID                     int64           `json:"id"`
+	InstitutionID          int64           `json:"institution_id"`
 	OfficialEmail          string          `json:"official_email"`
 	Name                   string          `json:"name"`
 	RollNo                 *string         `json:"roll_no"`
 	RegisterNo             *string         `json:"register_no"`
 	BatchID                *int            `json:"batch_id"`
 	BatchYear              *int            `json:"batch_year,omitempty"`
+	Department             *string         `json:"department"`
 	PhotoURL               *string         `json:"photo_url"`
 	IsProfileCompleted     bool            `json:"is_profile_completed"`
 	IsEligibleForPlacement bool            `json:"is_eligible_for_placement"`
@@ -201,20 +204,20 @@ type StudentFullProfile struct {
 // ============================================
 
 type StudentModel struct {
-	DB *sql.DB
+	DB *DB
 }
 
-// Insert creates a new student (from OAuth)
-func (m StudentModel) Insert(student *Student) error {
+// Insert creates a new student (from OAuth) in student.InstitutionID
+func (m StudentModel) Insert(ctx context.Context, student *Student) error {
 	query := `
-		INSERT INTO students (official_email, name)
-		VALUES ($1, $2)
+		INSERT INTO students (institution_id, official_email, name)
+		VALUES ($1, $2, $3)
 		RETURNING id, created_at, updated_at, version`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	return m.DB.QueryRowContext(ctx, query, student.OfficialEmail, student.Name).Scan(
+	return m.DB.QueryRowContext(ctx, query, student.InstitutionID, student.OfficialEmail, student.Name).Scan(
 		&student.ID,
 		&student.CreatedAt,
 		&student.UpdatedAt,
@@ -223,10 +226,10 @@ func (m StudentModel) Insert(student *Student) error {
 }
 
 // GetByEmail retrieves a student by email
-func (m StudentModel) GetByEmail(email string) (*Student, error) {
+func (m StudentModel) GetByEmail(ctx context.Context, email string) (*Student, error) {
 	query := `
-		SELECT s.id, s.official_email, s.name, s.roll_no, s.register_no, 
-		       s.batch_id, b.year, s.photo_url, s.is_profile_completed, 
+		SELECT s.id, s.institution_id, s.official_email, s.name, s.roll_no, s.register_no, 
+		       s.batch_id, b.year, s.department, s.photo_url, s.is_profile_completed, 
 		       s.is_eligible_for_placement, s.placement_status,
 		       s.created_at, s.updated_at, s.last_login_at, s.version
 		FROM students s
@@ -234,12 +237,12 @@ func (m StudentModel) GetByEmail(email string) (*Student, error) {
 		WHERE s.official_email = $1`
 
 	var student Student
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, email).Scan(
-		&student.ID, &student.OfficialEmail, &student.Name, &student.RollNo,
-		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.PhotoURL,
+		&student.ID, &student.InstitutionID, &student.OfficialEmail, &student.Name, &student.RollNo,
+		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.Department, &student.PhotoURL,
 		&student.IsProfileCompleted, &student.IsEligibleForPlacement,
 		&student.PlacementStatus, &student.CreatedAt, &student.UpdatedAt,
 		&student.LastLoginAt, &student.Version,
@@ -256,10 +259,10 @@ func (m StudentModel) GetByEmail(email string) (*Student, error) {
 }
 
 // GetByID retrieves a student by ID
-func (m StudentModel) GetByID(id int64) (*Student, error) {
+func (m StudentModel) GetByID(ctx context.Context, id int64) (*Student, error) {
 	query := `
-		SELECT s.id, s.official_email, s.name, s.roll_no, s.register_no, 
-		       s.batch_id, b.year, s.photo_url, s.is_profile_completed, 
+		SELECT s.id, s.institution_id, s.official_email, s.name, s.roll_no, s.register_no, 
+		       s.batch_id, b.year, s.department, s.photo_url, s.is_profile_completed, 
 		       s.is_eligible_for_placement, s.placement_status,
 		       s.created_at, s.updated_at, s.last_login_at, s.version
 		FROM students s
@@ -267,12 +270,12 @@ func (m StudentModel) GetByID(id int64) (*Student, error) {
 		WHERE s.id = $1`
 
 	var student Student
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&student.ID, &student.OfficialEmail, &student.Name, &student.RollNo,
-		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.PhotoURL,
+		&student.ID, &student.InstitutionID, &student.OfficialEmail, &student.Name, &student.RollNo,
+		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.Department, &student.PhotoURL,
 		&student.IsProfileCompleted, &student.IsEligibleForPlacement,
 		&student.PlacementStatus, &student.CreatedAt, &student.UpdatedAt,
 		&student.LastLoginAt, &student.Version,
@@ -289,20 +292,20 @@ func (m StudentModel) GetByID(id int64) (*Student, error) {
 }
 
 // UpdateBasicInfo updates student's basic info
-func (m StudentModel) UpdateBasicInfo(student *Student) error {
+func (m StudentModel) UpdateBasicInfo(ctx context.Context, student *Student) error {
 	query := `
 		UPDATE students 
 		SET name = $1, roll_no = $2, register_no = $3, batch_id = $4, 
-		    photo_url = $5, version = version + 1
-		WHERE id = $6 AND version = $7
+		    department = $5, photo_url = $6, version = version + 1
+		WHERE id = $7 AND version = $8
 		RETURNING version, updated_at`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query,
 		student.Name, student.RollNo, student.RegisterNo, student.BatchID,
-		student.PhotoURL, student.ID, student.Version,
+		student.Department, student.PhotoURL, student.ID, student.Version,
 	).Scan(&student.Version, &student.UpdatedAt)
 
 	if err != nil {
@@ -316,29 +319,31 @@ func (m StudentModel) UpdateBasicInfo(student *Student) error {
 }
 
 // SetProfileCompleted marks profile as completed
-func (m StudentModel) SetProfileCompleted(tx *sql.Tx, studentID int64) error {
+func (m StudentModel) SetProfileCompleted(ctx context.Context, studentID int64) error {
 	query := `UPDATE students SET is_profile_completed = true WHERE id = $1`
-	_, err := tx.Exec(query, studentID)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+	_, err := m.DB.ExecContext(ctx, query, studentID)
 	return err
 }
 
 // UpdateLastLogin updates last login timestamp
-func (m StudentModel) UpdateLastLogin(id int64) error {
+func (m StudentModel) UpdateLastLogin(ctx context.Context, id int64) error {
 	query := `UPDATE students SET last_login_at = NOW() WHERE id = $1`
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 	_, err := m.DB.ExecContext(ctx, query, id)
 	return err
 }
 
-// GetBatchIDByYear retrieves batch ID by year
-func (m StudentModel) GetBatchIDByYear(year int) (int, error) {
-	query := `SELECT id FROM batches WHERE year = $1`
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// GetBatchIDByYear retrieves an institution's batch ID by year
+func (m StudentModel) GetBatchIDByYear(ctx context.Context, institutionID int64, year int) (int, error) {
+	query := `SELECT id FROM batches WHERE institution_id = $1 AND year = $2`
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	var id int
-	err := m.DB.QueryRowContext(ctx, query, year).Scan(&id)
+	err := m.DB.QueryRowContext(ctx, query, institutionID, year).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return 0, ErrRecordNotFound
@@ -352,7 +357,7 @@ func (m StudentModel) GetBatchIDByYear(year int) (int, error) {
 // PERSONAL DETAILS
 // ============================================
 
-func (m StudentModel) UpsertPersonalDetails(tx *sql.Tx, details *StudentPersonalDetails) error {
+func (m StudentModel) UpsertPersonalDetails(ctx context.Context, details *StudentPersonalDetails) error {
 	query := `
 		INSERT INTO student_personal_details (
 			student_id, date_of_birth, gender, blood_group, mobile_number,
@@ -376,10 +381,10 @@ func (m StudentModel) UpsertPersonalDetails(tx *sql.Tx, details *StudentPersonal
 			pincode = EXCLUDED.pincode,
 			residence_type = EXCLUDED.residence_type`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := m.DB.ExecContext(ctx, query,
 		details.StudentID, details.DateOfBirth, details.Gender, details.BloodGroup,
 		details.MobileNumber, details.AlternateMobile, details.PersonalEmail,
 		details.LinkedinURL, details.GithubURL, details.PortfolioURL,
@@ -389,7 +394,7 @@ func (m StudentModel) UpsertPersonalDetails(tx *sql.Tx, details *StudentPersonal
 	return err
 }
 
-func (m StudentModel) GetPersonalDetails(studentID int64) (*StudentPersonalDetails, error) {
+func (m StudentModel) GetPersonalDetails(ctx context.Context, studentID int64) (*StudentPersonalDetails, error) {
 	query := `
 		SELECT id, student_id, date_of_birth::text, gender, blood_group, mobile_number,
 		       alternate_mobile, personal_email, linkedin_url, github_url, portfolio_url,
@@ -398,7 +403,7 @@ func (m StudentModel) GetPersonalDetails(studentID int64) (*StudentPersonalDetai
 		WHERE student_id = $1`
 
 	var d StudentPersonalDetails
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -422,7 +427,7 @@ func (m StudentModel) GetPersonalDetails(studentID int64) (*StudentPersonalDetai
 // FAMILY DETAILS
 // ============================================
 
-func (m StudentModel) UpsertFamilyDetails(tx *sql.Tx, details *StudentFamilyDetails) error {
+func (m StudentModel) UpsertFamilyDetails(ctx context.Context, details *StudentFamilyDetails) error {
 	query := `
 		INSERT INTO student_family_details (
 			student_id, father_name, father_mobile, father_email, father_occupation,
@@ -445,10 +450,10 @@ func (m StudentModel) UpsertFamilyDetails(tx *sql.Tx, details *StudentFamilyDeta
 			guardian_mobile = EXCLUDED.guardian_mobile,
 			guardian_relation = EXCLUDED.guardian_relation`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := m.DB.ExecContext(ctx, query,
 		details.StudentID, details.FatherName, details.FatherMobile, details.FatherEmail,
 		details.FatherOccupation, details.FatherCompany, details.FatherAnnualIncome,
 		details.MotherName, details.MotherMobile, details.MotherEmail,
@@ -458,7 +463,7 @@ func (m StudentModel) UpsertFamilyDetails(tx *sql.Tx, details *StudentFamilyDeta
 	return err
 }
 
-func (m StudentModel) GetFamilyDetails(studentID int64) (*StudentFamilyDetails, error) {
+func (m StudentModel) GetFamilyDetails(ctx context.Context, studentID int64) (*StudentFamilyDetails, error) {
 	query := `
 		SELECT id, student_id, father_name, father_mobile, father_email, father_occupation,
 		       father_company, father_annual_income, mother_name, mother_mobile, mother_email,
@@ -467,7 +472,7 @@ func (m StudentModel) GetFamilyDetails(studentID int64) (*StudentFamilyDetails,
 		WHERE student_id = $1`
 
 	var d StudentFamilyDetails
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -491,7 +496,7 @@ func (m StudentModel) GetFamilyDetails(studentID int64) (*StudentFamilyDetails,
 // ACADEMICS
 // ============================================
 
-func (m StudentModel) UpsertAcademics(tx *sql.Tx, a *StudentAcademics) error {
+func (m StudentModel) UpsertAcademics(ctx context.Context, a *StudentAcademics) error {
 	query := `
 		INSERT INTO student_academics (
 			student_id, tenth_percentage, tenth_board, tenth_year, tenth_school,
@@ -529,10 +534,10 @@ func (m StudentModel) UpsertAcademics(tx *sql.Tx, a *StudentAcademics) error {
 			has_gap_year = EXCLUDED.has_gap_year,
 			gap_year_reason = EXCLUDED.gap_year_reason`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := m.DB.ExecContext(ctx, query,
 		a.StudentID, a.TenthPercentage, a.TenthBoard, a.TenthYear, a.TenthSchool,
 		a.TwelfthPercentage, a.TwelfthBoard, a.TwelfthYear, a.TwelfthSchool,
 		a.HasDiploma, a.DiplomaPercentage, a.DiplomaBranch, a.DiplomaCollege,
@@ -543,7 +548,7 @@ func (m StudentModel) UpsertAcademics(tx *sql.Tx, a *StudentAcademics) error {
 	return err
 }
 
-func (m StudentModel) GetAcademics(studentID int64) (*StudentAcademics, error) {
+func (m StudentModel) GetAcademics(ctx context.Context, studentID int64) (*StudentAcademics, error) {
 	query := `
 		SELECT id, student_id, tenth_percentage, tenth_board, tenth_year, tenth_school,
 		       twelfth_percentage, twelfth_board, twelfth_year, twelfth_school,
@@ -555,7 +560,7 @@ func (m StudentModel) GetAcademics(studentID int64) (*StudentAcademics, error) {
 		WHERE student_id = $1`
 
 	var a StudentAcademics
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -577,11 +582,83 @@ func (m StudentModel) GetAcademics(studentID int64) (*StudentAcademics, error) {
 	return &a, nil
 }
 
+// AcademicSync is one student's record pushed by the college ERP.
+// Nil fields leave the stored value unchanged.
+type AcademicSync struct {
+	// InstitutionID limits the match to one institution; nil matches any
+	InstitutionID *int64
+	Email         string
+	RollNo        *string
+	// Department is set only here and by admins; students cannot change it
+	// because it decides which department coordinators see them
+	Department      *string
+	CGPASems        [8]*float64
+	CGPAOverall     *float64
+	CurrentBacklogs *int
+}
+
+// SyncAcademics applies an ERP record to the student with the matching
+// official email and returns the student's ID
+func (m StudentModel) SyncAcademics(ctx context.Context, rec *AcademicSync) (int64, error) {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var studentID int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE students
+		SET roll_no = COALESCE($2, roll_no), department = COALESCE($4, department), version = version + 1
+		WHERE official_email = $1 AND ($3::int IS NULL OR institution_id = $3)
+		RETURNING id`, rec.Email, rec.RollNo, rec.InstitutionID, rec.Department).Scan(&studentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrRecordNotFound
+		case strings.Contains(err.Error(), "students_institution_roll_no_key"):
+			return 0, ErrDuplicateRollNo
+		}
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO student_academics (
+			student_id, cgpa_sem1, cgpa_sem2, cgpa_sem3, cgpa_sem4,
+			cgpa_sem5, cgpa_sem6, cgpa_sem7, cgpa_sem8, cgpa_overall, current_backlogs
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, COALESCE($11, 0))
+		ON CONFLICT (student_id) DO UPDATE SET
+			cgpa_sem1 = COALESCE($2, student_academics.cgpa_sem1),
+			cgpa_sem2 = COALESCE($3, student_academics.cgpa_sem2),
+			cgpa_sem3 = COALESCE($4, student_academics.cgpa_sem3),
+			cgpa_sem4 = COALESCE($5, student_academics.cgpa_sem4),
+			cgpa_sem5 = COALESCE($6, student_academics.cgpa_sem5),
+			cgpa_sem6 = COALESCE($7, student_academics.cgpa_sem6),
+			cgpa_sem7 = COALESCE($8, student_academics.cgpa_sem7),
+			cgpa_sem8 = COALESCE($9, student_academics.cgpa_sem8),
+			cgpa_overall = COALESCE($10, student_academics.cgpa_overall),
+			current_backlogs = COALESCE($11, student_academics.current_backlogs)`
+
+	sems := rec.CGPASems
+	_, err = tx.ExecContext(ctx, query,
+		studentID, sems[0], sems[1], sems[2], sems[3], sems[4], sems[5], sems[6], sems[7],
+		rec.CGPAOverall, rec.CurrentBacklogs,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return studentID, tx.Commit()
+}
+
 // ============================================
 // ACHIEVEMENTS
 // ============================================
 
-func (m StudentModel) UpsertAchievements(tx *sql.Tx, a *StudentAchievements) error {
+func (m StudentModel) UpsertAchievements(ctx context.Context, a *StudentAchievements) error {
 	query := `
 		INSERT INTO student_achievements (
 			student_id, certifications, awards, workshops, internships, projects,
@@ -609,10 +686,10 @@ func (m StudentModel) UpsertAchievements(tx *sql.Tx, a *StudentAchievements) err
 			sports = EXCLUDED.sports,
 			volunteer_work = EXCLUDED.volunteer_work`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := m.DB.ExecContext(ctx, query,
 		a.StudentID, a.Certifications, a.Awards, a.Workshops, a.Internships, a.Projects,
 		a.LeetcodeProfile, a.HackerrankProfile, a.CodeforcesProfile, a.CodechefProfile,
 		a.LeetcodeRating, a.ProblemsSolved, a.HackathonsParticipated, a.HackathonsWon,
@@ -621,7 +698,7 @@ func (m StudentModel) UpsertAchievements(tx *sql.Tx, a *StudentAchievements) err
 	return err
 }
 
-func (m StudentModel) GetAchievements(studentID int64) (*StudentAchievements, error) {
+func (m StudentModel) GetAchievements(ctx context.Context, studentID int64) (*StudentAchievements, error) {
 	query := `
 		SELECT id, student_id, certifications, awards, workshops, internships, projects,
 		       leetcode_profile, hackerrank_profile, codeforces_profile, codechef_profile,
@@ -631,7 +708,7 @@ func (m StudentModel) GetAchievements(studentID int64) (*StudentAchievements, er
 		WHERE student_id = $1`
 
 	var a StudentAchievements
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -655,7 +732,7 @@ func (m StudentModel) GetAchievements(studentID int64) (*StudentAchievements, er
 // ASPIRATIONS
 // ============================================
 
-func (m StudentModel) UpsertAspirations(tx *sql.Tx, a *StudentAspirations) error {
+func (m StudentModel) UpsertAspirations(ctx context.Context, a *StudentAspirations) error {
 	query := `
 		INSERT INTO student_aspirations (
 			student_id, dream_companies, preferred_roles, preferred_locations, expected_salary,
@@ -676,10 +753,10 @@ func (m StudentModel) UpsertAspirations(tx *sql.Tx, a *StudentAspirations) error
 			hobbies = EXCLUDED.hobbies,
 			languages_known = EXCLUDED.languages_known`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 
-	_, err := tx.ExecContext(ctx, query,
+	_, err := m.DB.ExecContext(ctx, query,
 		a.StudentID, a.DreamCompanies, a.PreferredRoles, a.PreferredLocations, a.ExpectedSalary,
 		a.WillingToRelocate, a.CareerObjective, a.ShortTermGoals, a.LongTermGoals,
 		a.Strengths, a.Weaknesses, a.Hobbies, a.LanguagesKnown,
@@ -687,7 +764,7 @@ func (m StudentModel) UpsertAspirations(tx *sql.Tx, a *StudentAspirations) error
 	return err
 }
 
-func (m StudentModel) GetAspirations(studentID int64) (*StudentAspirations, error) {
+func (m StudentModel) GetAspirations(ctx context.Context, studentID int64) (*StudentAspirations, error) {
 	query := `
 		SELECT id, student_id, dream_companies, preferred_roles, preferred_locations, expected_salary,
 		       willing_to_relocate, career_objective, short_term_goals, long_term_goals,
@@ -696,7 +773,7 @@ func (m StudentModel) GetAspirations(studentID int64) (*StudentAspirations, erro
 		WHERE student_id = $1`
 
 	var a StudentAspirations
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, query, studentID).Scan(
@@ -719,29 +796,41 @@ func (m StudentModel) GetAspirations(studentID int64) (*StudentAspirations, erro
 // STUDENT SKILLS
 // ============================================
 
-func (m StudentModel) UpsertSkills(tx *sql.Tx, studentID int64, skills []StudentSkill) error {
-	// First delete existing skills
-	_, err := tx.Exec("DELETE FROM student_skills WHERE student_id = $1", studentID)
+// UpsertSkills replaces a student's skills in one transaction. Skills of
+// another institution are silently skipped.
+func (m StudentModel) UpsertSkills(ctx context.Context, studentID int64, skills []StudentSkill) error {
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	// Insert new skills
-	for _, skill := range skills {
-		query := `
-			INSERT INTO student_skills (student_id, skill_id, proficiency, years_of_experience)
-			VALUES ($1, $2, $3, $4)`
+	_, err = tx.ExecContext(ctx, "DELETE FROM student_skills WHERE student_id = $1", studentID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO student_skills (student_id, skill_id, proficiency, years_of_experience)
+		SELECT $1, sk.id, $3, $4
+		FROM skills sk
+		JOIN students s ON s.institution_id = sk.institution_id
+		WHERE s.id = $1 AND sk.id = $2`
 
-		_, err := tx.Exec(query, studentID, skill.SkillID, skill.Proficiency, skill.YearsOfExperience)
+	for _, skill := range skills {
+		_, err := tx.ExecContext(ctx, query, studentID, skill.SkillID, skill.Proficiency, skill.YearsOfExperience)
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
-func (m StudentModel) GetSkills(studentID int64) ([]StudentSkill, error) {
+func (m StudentModel) GetSkills(ctx context.Context, studentID int64) ([]StudentSkill, error) {
 	query := `
 		SELECT ss.id, ss.student_id, ss.skill_id, s.name, s.category, ss.proficiency, ss.years_of_experience
 		FROM student_skills ss
@@ -749,7 +838,7 @@ func (m StudentModel) GetSkills(studentID int64) ([]StudentSkill, error) {
 		WHERE ss.student_id = $1
 		ORDER BY s.category, s.display_order`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
 	rows, err := m.DB.QueryContext(ctx, query, studentID)
@@ -774,20 +863,20 @@ func (m StudentModel) GetSkills(studentID int64) ([]StudentSkill, error) {
 // FULL PROFILE
 // ============================================
 
-func (m StudentModel) GetFullProfile(studentID int64) (*StudentFullProfile, error) {
-	student, err := m.GetByID(studentID)
+func (m StudentModel) GetFullProfile(ctx context.Context, studentID int64) (*StudentFullProfile, error) {
+	student, err := m.GetByID(ctx, studentID)
 	if err != nil {
 		return nil, err
 	}
 
 	profile := &StudentFullProfile{Student: *student}
 
-	profile.Personal, _ = m.GetPersonalDetails(studentID)
-	profile.Family, _ = m.GetFamilyDetails(studentID)
-	profile.Academics, _ = m.GetAcademics(studentID)
-	profile.Achievements, _ = m.GetAchievements(studentID)
-	profile.Aspirations, _ = m.GetAspirations(studentID)
-	profile.Skills, _ = m.GetSkills(studentID)
+	profile.Personal, _ = m.GetPersonalDetails(ctx, studentID)
+	profile.Family, _ = m.GetFamilyDetails(ctx, studentID)
+	profile.Academics, _ = m.GetAcademics(ctx, studentID)
+	profile.Achievements, _ = m.GetAchievements(ctx, studentID)
+	profile.Aspirations, _ = m.GetAspirations(ctx, studentID)
+	profile.Skills, _ = m.GetSkills(ctx, studentID)
 
 	return profile, nil
 }
@@ -797,15 +886,20 @@ func (m StudentModel) GetFullProfile(studentID int64) (*StudentFullProfile, erro
 // ============================================
 
 type StudentFilter struct {
+	// InstitutionID limits results to one institution; nil lists every institution
+	InstitutionID   *int64
 	Search          string
 	BatchYear       *int
+	Department      *string
 	PlacementStatus *PlacementStatus
 	MinCGPA         *float64
 	MaxCGPA         *float64
 	HasBacklogs     *bool
-	SkillIDs        []int
-	Page            int
-	PageSize        int
+	// ProfileCompleted matches students who have, or have not, submitted their profile
+	ProfileCompleted *bool
+	SkillIDs         []int
+	Page             int
+	PageSize         int
 }
 
 type StudentListItem struct {
@@ -814,6 +908,7 @@ type StudentListItem struct {
 	OfficialEmail      string          `json:"official_email"`
 	RollNo             *string         `json:"roll_no"`
 	BatchYear          *int            `json:"batch_year"`
+	Department         *string         `json:"department"`
 	PhotoURL           *string         `json:"photo_url"`
 	IsProfileCompleted bool            `json:"is_profile_completed"`
 	PlacementStatus    PlacementStatus `json:"placement_status"`
@@ -831,45 +926,72 @@ type StudentListResult struct {
 	TotalPages int               `json:"total_pages"`
 }
 
-func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
-	if filter.Page < 1 {
-		filter.Page = 1
-	}
+// MaxExportRows caps how many students one export returns
+const MaxExportRows = 10000
+
+// List returns one page of the students matching the filter, at most 100 per page
+func (m StudentModel) List(ctx context.Context, filter StudentFilter) (*StudentListResult, error) {
 	if filter.PageSize < 1 || filter.PageSize > 100 {
 		filter.PageSize = 20
 	}
+	return m.list(ctx, ReadQuery, filter)
+}
+
+// Export returns up to MaxExportRows students matching the filter as one page
+func (m StudentModel) Export(ctx context.Context, filter StudentFilter) (*StudentListResult, error) {
+	filter.Page, filter.PageSize = 1, MaxExportRows
+	return m.list(ctx, ExportQuery, filter)
+}
+
+func (m StudentModel) list(ctx context.Context, class QueryClass, filter StudentFilter) (*StudentListResult, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
 
 	var conditions []string
 	var args []interface{}
 	argNum := 1
 
 	// Build WHERE clause
+	if filter.InstitutionID != nil {
+		conditions = append(conditions, "s.institution_id = $"+strconv.Itoa(argNum))
+		args = append(args, *filter.InstitutionID)
+		argNum++
+	}
+
 	if filter.Search != "" {
-		conditions = append(conditions, "(s.name ILIKE $"+string(rune('0'+argNum))+" OR s.roll_no ILIKE $"+string(rune('0'+argNum))+" OR s.official_email ILIKE $"+string(rune('0'+argNum))+")")
+		placeholder := "$" + strconv.Itoa(argNum)
+		conditions = append(conditions, "(s.name ILIKE "+placeholder+" OR s.roll_no ILIKE "+placeholder+" OR s.official_email ILIKE "+placeholder+")")
 		args = append(args, "%"+filter.Search+"%")
 		argNum++
 	}
 
 	if filter.BatchYear != nil {
-		conditions = append(conditions, "b.year = $"+string(rune('0'+argNum)))
+		conditions = append(conditions, "b.year = $"+strconv.Itoa(argNum))
 		args = append(args, *filter.BatchYear)
 		argNum++
 	}
 
+	if filter.Department != nil {
+		conditions = append(conditions, "s.department = $"+strconv.Itoa(argNum))
+		args = append(args, *filter.Department)
+		argNum++
+	}
+
 	if filter.PlacementStatus != nil {
-		conditions = append(conditions, "s.placement_status = $"+string(rune('0'+argNum)))
+		conditions = append(conditions, "s.placement_status = $"+strconv.Itoa(argNum))
 		args = append(args, *filter.PlacementStatus)
 		argNum++
 	}
 
 	if filter.MinCGPA != nil {
-		conditions = append(conditions, "sa.cgpa_overall >= $"+string(rune('0'+argNum)))
+		conditions = append(conditions, "sa.cgpa_overall >= $"+strconv.Itoa(argNum))
 		args = append(args, *filter.MinCGPA)
 		argNum++
 	}
 
 	if filter.MaxCGPA != nil {
-		conditions = append(conditions, "sa.cgpa_overall <= $"+string(rune('0'+argNum)))
+		conditions = append(conditions, "sa.cgpa_overall <= $"+strconv.Itoa(argNum))
 		args = append(args, *filter.MaxCGPA)
 		argNum++
 	}
@@ -882,6 +1004,14 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 		}
 	}
 
+	if filter.ProfileCompleted != nil {
+		if *filter.ProfileCompleted {
+			conditions = append(conditions, "s.is_profile_completed = true")
+		} else {
+			conditions = append(conditions, "(s.is_profile_completed = false OR s.is_profile_completed IS NULL)")
+		}
+	}
+
 	whereClause := ""
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
@@ -896,7 +1026,7 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 		` + whereClause
 
 	var total int
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, class)
 	defer cancel()
 
 	err := m.DB.QueryRowContext(ctx, countQuery, args...).Scan(&total)
@@ -909,7 +1039,7 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 	args = append(args, filter.PageSize, offset)
 
 	query := `
-		SELECT DISTINCT s.id, s.name, s.official_email, s.roll_no, b.year, s.photo_url,
+		SELECT DISTINCT s.id, s.name, s.official_email, s.roll_no, b.year, s.department, s.photo_url,
 		       s.is_profile_completed, s.placement_status, sa.cgpa_overall, spd.mobile_number,
 		       p.company_name, p.package_lpa
 		FROM students s
@@ -919,7 +1049,7 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 		LEFT JOIN placements p ON s.id = p.student_id AND p.is_accepted = true
 		` + whereClause + `
 		ORDER BY s.name ASC
-		LIMIT $` + string(rune('0'+argNum)) + ` OFFSET $` + string(rune('0'+argNum+1))
+		LIMIT $` + strconv.Itoa(argNum) + ` OFFSET $` + strconv.Itoa(argNum+1)
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -931,7 +1061,7 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 	for rows.Next() {
 		var s StudentListItem
 		if err := rows.Scan(
-			&s.ID, &s.Name, &s.OfficialEmail, &s.RollNo, &s.BatchYear, &s.PhotoURL,
+			&s.ID, &s.Name, &s.OfficialEmail, &s.RollNo, &s.BatchYear, &s.Department, &s.PhotoURL,
 			&s.IsProfileCompleted, &s.PlacementStatus, &s.CGPAOverall, &s.MobileNumber,
 			&s.PlacedCompany, &s.PackageLPA,
 		); err != nil {
@@ -951,24 +1081,27 @@ func (m StudentModel) List(filter StudentFilter) (*StudentListResult, error) {
 	}, nil
 }
 
-// GetByRollNo retrieves a student by roll number
-func (m StudentModel) GetByRollNo(rollNo string) (*Student, error) {
+// GetByRollNo retrieves a student by roll number. Roll numbers are only unique
+// within an institution, so across institutions the oldest match is returned.
+func (m StudentModel) GetByRollNo(ctx context.Context, institutionID *int64, rollNo string) (*Student, error) {
 	query := `
-		SELECT s.id, s.official_email, s.name, s.roll_no, s.register_no, 
-		       s.batch_id, b.year, s.photo_url, s.is_profile_completed, 
+		SELECT s.id, s.institution_id, s.official_email, s.name, s.roll_no, s.register_no, 
+		       s.batch_id, b.year, s.department, s.photo_url, s.is_profile_completed, 
 		       s.is_eligible_for_placement, s.placement_status,
 		       s.created_at, s.updated_at, s.last_login_at, s.version
 		FROM students s
 		LEFT JOIN batches b ON s.batch_id = b.id
-		WHERE s.roll_no = $1`
+		WHERE s.roll_no = $1 AND ($2::int IS NULL OR s.institution_id = $2)
+		ORDER BY s.id
+		LIMIT 1`
 
 	var student Student
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, ReadQuery)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, rollNo).Scan(
-		&student.ID, &student.OfficialEmail, &student.Name, &student.RollNo,
-		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.PhotoURL,
+	err := m.DB.QueryRowContext(ctx, query, rollNo, institutionID).Scan(
+		&student.ID, &student.InstitutionID, &student.OfficialEmail, &student.Name, &student.RollNo,
+		&student.RegisterNo, &student.BatchID, &student.BatchYear, &student.Department, &student.PhotoURL,
 		&student.IsProfileCompleted, &student.IsEligibleForPlacement,
 		&student.PlacementStatus, &student.CreatedAt, &student.UpdatedAt,
 		&student.LastLoginAt, &student.Version,
@@ -985,10 +1118,46 @@ func (m StudentModel) GetByRollNo(rollNo string) (*Student, error) {
 }
 
 // UpdatePlacementStatus updates a student's placement status
-func (m StudentModel) UpdatePlacementStatus(studentID int64, status PlacementStatus) error {
+func (m StudentModel) UpdatePlacementStatus(ctx context.Context, studentID int64, status PlacementStatus) error {
 	query := `UPDATE students SET placement_status = $1 WHERE id = $2`
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := m.DB.withTimeout(ctx, WriteQuery)
 	defer cancel()
 	_, err := m.DB.ExecContext(ctx, query, status, studentID)
 	return err
 }
+
+// EligibilityRule decides who may sit for placement drives: a submitted
+// profile, at most MaxBacklogs current backlogs and, when MinCGPA is above
+// zero, an overall CGPA of at least MinCGPA
+type EligibilityRule struct {
+	MinCGPA     float64
+	MaxBacklogs int
+}
+
+// RecomputeEligibility applies the rule to every student and returns how many
+// changed. Only the flag is written, so the version used for edit conflicts
+// is left alone.
+func (m StudentModel) RecomputeEligibility(ctx context.Context, rule EligibilityRule) (int64, error) {
+	query := `
+		UPDATE students s
+		SET is_eligible_for_placement = e.eligible
+		FROM (
+			SELECT s.id,
+			       COALESCE(s.is_profile_completed, false)
+			       AND COALESCE(sa.current_backlogs, 0) <= $1
+			       AND ($2::numeric = 0 OR COALESCE(sa.cgpa_overall, 0) >= $2::numeric) AS eligible
+			FROM students s
+			LEFT JOIN student_academics sa ON sa.student_id = s.id
+		) e
+		WHERE s.id = e.id AND s.is_eligible_for_placement IS DISTINCT FROM e.eligible`
+
+	// One statement over every student, so it gets the export budget
+	ctx, cancel := m.DB.withTimeout(ctx, ExportQuery)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, rule.MaxBacklogs, rule.MinCGPA)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
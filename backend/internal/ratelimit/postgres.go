@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ============================================
+// POSTGRES LIMITER
+// ============================================
+
+// PostgresLimiter keeps buckets in the rate_limit_buckets table so every
+// replica of the API shares the same counters. Each call locks its bucket row
+// for the duration of a short transaction; the database clock is used so
+// replicas with drifting clocks still agree.
+type PostgresLimiter struct {
+	DB *sql.DB
+}
+
+func NewPostgresLimiter(db *sql.DB) *PostgresLimiter {
+	return &PostgresLimiter{DB: db}
+}
+
+func (l *PostgresLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	tx, err := l.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	// A new bucket starts full
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO NOTHING`, key, float64(limit.Burst))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var b bucket
+	var now time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, updated_at, NOW()
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE`, key).Scan(&b.tokens, &b.last, &now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := take(&b, now, limit)
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE key = $3`,
+		b.tokens, b.last, key)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, tx.Commit()
+}
+
+// Cleanup deletes buckets that have been idle long enough to be full again
+func (l *PostgresLimiter) Cleanup(ctx context.Context, idle time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	result, err := l.DB.ExecContext(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - $1 * INTERVAL '1 second'`,
+		idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,104 @@
+// Package ratelimit implements token-bucket rate limiting keyed by an
+// arbitrary string such as an API key ID, user or client IP. Buckets live in
+// process memory or, to share them between replicas, in Postgres.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens, refilled at Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute allows n requests a minute with bursts of up to n
+func PerMinute(n int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: n}
+}
+
+// Result describes the outcome of a single Allow call
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether the caller identified by key may proceed
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// ============================================
+// IN-MEMORY LIMITER
+// ============================================
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryLimiter keeps buckets in process memory. Limits are per instance.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+
+	return take(b, now, limit), nil
+}
+
+// Cleanup drops buckets that have been idle long enough to be full again
+func (l *MemoryLimiter) Cleanup(idle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := l.now().Add(-idle)
+	for key, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// take refills the bucket for the time elapsed since its last use and tries to spend one token
+func take(b *bucket, now time.Time, limit Limit) Result {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+
+	result := Result{Allowed: false}
+	if limit.Rate > 0 {
+		result.RetryAfter = time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	limit := PerMinute(2)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if res, _ := l.Allow(ctx, "a", limit); !res.Allowed {
+			t.Fatalf("request %d was rejected within the burst", i+1)
+		}
+	}
+
+	res, _ := l.Allow(ctx, "a", limit)
+	if res.Allowed {
+		t.Fatal("request over the burst was allowed")
+	}
+	if res.RetryAfter != 30*time.Second {
+		t.Errorf("got RetryAfter %v, want 30s", res.RetryAfter)
+	}
+
+	// Other keys have their own bucket
+	if res, _ := l.Allow(ctx, "b", limit); !res.Allowed {
+		t.Error("separate key was limited")
+	}
+
+	now = now.Add(30 * time.Second)
+	if res, _ := l.Allow(ctx, "a", limit); !res.Allowed {
+		t.Error("request was rejected after the bucket refilled")
+	}
+}
+
+func TestMemoryLimiterCleanup(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewMemoryLimiter()
+	l.now = func() time.Time { return now }
+
+	l.Allow(context.Background(), "idle", PerMinute(1))
+	now = now.Add(time.Hour)
+	l.Allow(context.Background(), "busy", PerMinute(1))
+
+	l.Cleanup(10 * time.Minute)
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("idle bucket was not removed")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("recent bucket was removed")
+	}
+}
@@ -0,0 +1,176 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================
+// CRON EXPRESSIONS
+// ============================================
+
+// Cron is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Fields accept *, numbers, ranges (1-5), lists
+// (1,15) and steps (*/15 or 8-18/2). Day of week runs from 0 (Sunday) to 6;
+// 7 is also Sunday.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// As in standard cron, when both day fields are restricted a day matching
+	// either of them matches
+	domAny, dowAny bool
+}
+
+// descriptors are the @ shorthands accepted in place of five fields
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+// ParseCron parses a cron expression such as "*/15 * * * *" or "@daily"
+func ParseCron(spec string) (*Cron, error) {
+	expr := strings.TrimSpace(spec)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields (minute hour day month weekday), got %d", spec, len(fields))
+	}
+
+	var c Cron
+	var err error
+	parse := func(dst *uint64, field string, name string, min, max int) {
+		if err != nil {
+			return
+		}
+		if *dst, err = parseField(field, min, max); err != nil {
+			err = fmt.Errorf("cron %q: %s: %w", spec, name, err)
+		}
+	}
+	parse(&c.minute, fields[0], "minute", 0, 59)
+	parse(&c.hour, fields[1], "hour", 0, 23)
+	parse(&c.dom, fields[2], "day of month", 1, 31)
+	parse(&c.month, fields[3], "month", 1, 12)
+	parse(&c.dow, fields[4], "day of week", 0, 7)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sunday may be written as 0 or 7
+	if c.dow&(1<<7) != 0 {
+		c.dow = c.dow&^(1<<7) | 1
+	}
+	c.domAny = strings.HasPrefix(fields[2], "*")
+	c.dowAny = strings.HasPrefix(fields[4], "*")
+	return &c, nil
+}
+
+// parseField returns a bit set of the values a field matches
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+
+		lo, hi := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			from, to, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(from, min, max); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(to, min, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("range %q runs backwards", rng)
+			}
+		default:
+			n, err := parseValue(rng, min, max)
+			if err != nil {
+				return 0, err
+			}
+			// A single value with a step, e.g. 5/15, runs from it to the end
+			lo, hi = n, n
+			if hasStep {
+				hi = max
+			}
+		}
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("step %q is not a positive number", stepText)
+			}
+			step = n
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < min || n > max {
+		return 0, fmt.Errorf("%d is outside %d-%d", n, min, max)
+	}
+	return n, nil
+}
+
+// Next returns the first matching minute after t, in t's location. It
+// returns the zero time if nothing matches within five years, e.g. for
+// "0 0 30 2 *".
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for limit := t.AddDate(5, 0, 0); t.Before(limit); {
+		if !has(c.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !has(c.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if !has(c.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := has(c.dom, t.Day())
+	dow := has(c.dow, int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<v) != 0
+}
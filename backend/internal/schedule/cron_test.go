@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"1,,2 * * * *",
+		"@fortnightly",
+	} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Errorf("ParseCron(%q) should fail", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	ist, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A Wednesday
+	from := time.Date(2026, 3, 4, 10, 17, 30, 0, ist)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 18, 0, 0, ist)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, ist)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, ist)},
+		{"5 0 * * *", time.Date(2026, 3, 5, 0, 5, 0, 0, ist)},
+		{"0 9 * * 1", time.Date(2026, 3, 9, 9, 0, 0, 0, ist)},
+		{"0 9 * * 1-5", time.Date(2026, 3, 5, 9, 0, 0, 0, ist)},
+		{"30 8-18/4 * * *", time.Date(2026, 3, 4, 12, 30, 0, 0, ist)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, ist)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, ist)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, ist)},
+		// Both day fields restricted: the 1st of the month or any Monday
+		{"0 0 1 * 1", time.Date(2026, 3, 9, 0, 0, 0, 0, ist)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: next after %v is %v, want %v", tt.spec, from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+	"time"
+)
+
+type RunStatus string
+
+const (
+	RunRunning   RunStatus = "running"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+)
+
+// Run is one execution of a task
+type Run struct {
+	ID         int64      `json:"id"`
+	Task       string     `json:"task"`
+	Instance   string     `json:"instance"`
+	Status     RunStatus  `json:"status"`
+	Summary    *string    `json:"summary"`
+	Error      *string    `json:"error"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// History records task runs
+type History interface {
+	// Start records a run of task by instance and returns its ID
+	Start(ctx context.Context, task, instance string) (int64, error)
+	// Finish records how a run ended; a nil runErr means it succeeded
+	Finish(ctx context.Context, id int64, summary string, runErr error) error
+	// Abandon marks runs still recorded as running as failed. A new leader
+	// calls it, since the replica that started them is gone.
+	Abandon(ctx context.Context) (int64, error)
+	// List returns recent runs, newest first; an empty task lists every task
+	List(ctx context.Context, task string, limit int) ([]*Run, error)
+	// Latest returns the most recent run of each task that has run
+	Latest(ctx context.Context) (map[string]*Run, error)
+}
+
+const abandonedError = "interrupted: the replica running it stopped"
+
+// ============================================
+// POSTGRES HISTORY
+// ============================================
+
+// PostgresHistory keeps runs in the schedule_runs table
+type PostgresHistory struct {
+	DB *sql.DB
+}
+
+func NewPostgresHistory(db *sql.DB) *PostgresHistory {
+	return &PostgresHistory{DB: db}
+}
+
+const runColumns = `id, task, instance, status, summary, error, started_at, finished_at`
+
+func scanRun(row interface{ Scan(...any) error }) (*Run, error) {
+	var run Run
+	err := row.Scan(&run.ID, &run.Task, &run.Instance, &run.Status, &run.Summary, &run.Error, &run.StartedAt, &run.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (h *PostgresHistory) Start(ctx context.Context, task, instance string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var id int64
+	err := h.DB.QueryRowContext(ctx, `
+		INSERT INTO schedule_runs (task, instance) VALUES ($1, $2)
+		RETURNING id`, task, instance).Scan(&id)
+	return id, err
+}
+
+func (h *PostgresHistory) Finish(ctx context.Context, id int64, summary string, runErr error) error {
+	status, message := RunSucceeded, (*string)(nil)
+	if runErr != nil {
+		status = RunFailed
+		text := runErr.Error()
+		message = &text
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := h.DB.ExecContext(ctx, `
+		UPDATE schedule_runs
+		SET status = $2, summary = NULLIF($3, ''), error = $4, finished_at = NOW()
+		WHERE id = $1`, id, status, summary, message)
+	return err
+}
+
+func (h *PostgresHistory) Abandon(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := h.DB.ExecContext(ctx, `
+		UPDATE schedule_runs
+		SET status = 'failed', error = $1, finished_at = NOW()
+		WHERE status = 'running'`, abandonedError)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (h *PostgresHistory) List(ctx context.Context, task string, limit int) ([]*Run, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.QueryContext(ctx, `
+		SELECT `+runColumns+` FROM schedule_runs
+		WHERE ($1 = '' OR task = $1)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2`, task, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*Run{}
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (h *PostgresHistory) Latest(ctx context.Context) (map[string]*Run, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := h.DB.QueryContext(ctx, `
+		SELECT DISTINCT ON (task) `+runColumns+` FROM schedule_runs
+		ORDER BY task, started_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := make(map[string]*Run)
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		latest[run.Task] = run
+	}
+	return latest, rows.Err()
+}
+
+// ============================================
+// IN-MEMORY HISTORY
+// ============================================
+
+// MemoryHistory keeps runs in process memory, for tests
+type MemoryHistory struct {
+	mu   sync.Mutex
+	runs []*Run
+}
+
+func NewMemoryHistory() *MemoryHistory {
+	return &MemoryHistory{}
+}
+
+func (h *MemoryHistory) Start(_ context.Context, task, instance string) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	run := &Run{ID: int64(len(h.runs) + 1), Task: task, Instance: instance, Status: RunRunning, StartedAt: time.Now()}
+	h.runs = append(h.runs, run)
+	return run.ID, nil
+}
+
+func (h *MemoryHistory) Finish(_ context.Context, id int64, summary string, runErr error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if id < 1 || id > int64(len(h.runs)) {
+		return nil
+	}
+	run := h.runs[id-1]
+	now := time.Now()
+	run.Status = RunSucceeded
+	if summary != "" {
+		run.Summary = &summary
+	}
+	if runErr != nil {
+		run.Status = RunFailed
+		message := runErr.Error()
+		run.Error = &message
+	}
+	run.FinishedAt = &now
+	return nil
+}
+
+func (h *MemoryHistory) Abandon(_ context.Context) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var n int64
+	now := time.Now()
+	for _, run := range h.runs {
+		if run.Status == RunRunning {
+			message := abandonedError
+			run.Status, run.Error, run.FinishedAt = RunFailed, &message, &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (h *MemoryHistory) List(_ context.Context, task string, limit int) ([]*Run, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	runs := []*Run{}
+	for _, run := range slices.Backward(h.runs) {
+		if task != "" && run.Task != task {
+			continue
+		}
+		found := *run
+		runs = append(runs, &found)
+		if len(runs) == limit {
+			break
+		}
+	}
+	return runs, nil
+}
+
+func (h *MemoryHistory) Latest(_ context.Context) (map[string]*Run, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	latest := make(map[string]*Run)
+	for _, run := range h.runs {
+		found := *run
+		latest[run.Task] = &found
+	}
+	return latest, nil
+}
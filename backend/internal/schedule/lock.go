@@ -0,0 +1,115 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Lock elects the one replica that runs scheduled tasks
+type Lock interface {
+	// TryAcquire takes the lock if no one holds it, without waiting
+	TryAcquire(ctx context.Context) (bool, error)
+	// Check returns an error once a held lock has been lost
+	Check(ctx context.Context) error
+	// Release gives up the lock; it is safe to call when not holding it
+	Release(ctx context.Context) error
+}
+
+// LeaderLockID is the advisory lock key held by the leading scheduler. It
+// must differ from the migration lock's key.
+const LeaderLockID int64 = 0x7363686564756c65 // "schedule"
+
+var errLockLost = errors.New("leader lock connection lost")
+
+// ============================================
+// POSTGRES ADVISORY LOCK
+// ============================================
+
+// PostgresLock holds a session-level advisory lock on a connection of its
+// own. Postgres drops the lock when that session ends, so a replica that
+// crashes or loses its connection hands leadership on without any cleanup.
+type PostgresLock struct {
+	DB  *sql.DB
+	Key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func NewPostgresLock(db *sql.DB) *PostgresLock {
+	return &PostgresLock{DB: db, Key: LeaderLockID}
+}
+
+func (l *PostgresLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return true, nil
+	}
+
+	conn, err := l.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.Key).Scan(&acquired); err != nil {
+		// The lock may have been granted before the error
+		l.conn = conn
+		l.discard()
+		return false, fmt.Errorf("acquiring leader lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Check runs a query on the lock's session. database/sql never reconnects
+// a *sql.Conn, so a query that succeeds is on the session holding the lock.
+func (l *PostgresLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return errLockLost
+	}
+	if _, err := l.conn.ExecContext(ctx, `SELECT 1`); err != nil {
+		l.discard()
+		return fmt.Errorf("%w: %w", errLockLost, err)
+	}
+	return nil
+}
+
+func (l *PostgresLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	if _, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.Key); err != nil {
+		l.discard()
+		return err
+	}
+	l.conn.Close()
+	l.conn = nil
+	return nil
+}
+
+// discard closes the lock's connection instead of returning it to the pool.
+// A session that might still hold the lock must end, or whichever request
+// borrowed it next would keep the lock without knowing.
+func (l *PostgresLock) discard() {
+	l.conn.Raw(func(any) error { return driver.ErrBadConn })
+	l.conn.Close()
+	l.conn = nil
+}
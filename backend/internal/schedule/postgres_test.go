@@ -0,0 +1,106 @@
+//go:build integration
+
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/VJ-2303/placement-profiling-system/internal/data"
+	"github.com/VJ-2303/placement-profiling-system/internal/migrate"
+	"github.com/VJ-2303/placement-profiling-system/migrations"
+)
+
+// TestPostgresLeader needs a scratch database:
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags integration ./internal/schedule/
+func TestPostgresLeader(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := data.OpenDB(dsn, data.PoolConfig{MaxOpenConns: 10, MaxIdleConns: 10, MaxIdleTime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	list, err := migrate.Load(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrate.New(db, list).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("one replica holds the lock at a time", func(t *testing.T) {
+		// A key of its own keeps a running server from interfering
+		key := time.Now().UnixNano()
+		first := &PostgresLock{DB: db, Key: key}
+		second := &PostgresLock{DB: db, Key: key}
+
+		if ok, err := first.TryAcquire(ctx); err != nil || !ok {
+			t.Fatalf("first replica: %v %v", ok, err)
+		}
+		if ok, err := second.TryAcquire(ctx); err != nil || ok {
+			t.Fatalf("second replica took a held lock: %v %v", ok, err)
+		}
+		if err := first.Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := first.Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := first.Check(ctx); !errors.Is(err, errLockLost) {
+			t.Errorf("check after release: got %v, want errLockLost", err)
+		}
+		if ok, err := second.TryAcquire(ctx); err != nil || !ok {
+			t.Fatalf("second replica after release: %v %v", ok, err)
+		}
+		second.Release(ctx)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		h := NewPostgresHistory(db)
+		task := fmt.Sprintf("test.%d", time.Now().UnixNano())
+
+		ok, err := h.Start(ctx, task, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Finish(ctx, ok, "3 rows", nil); err != nil {
+			t.Fatal(err)
+		}
+		failed, err := h.Start(ctx, task, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Finish(ctx, failed, "", errors.New("boom")); err != nil {
+			t.Fatal(err)
+		}
+
+		runs, err := h.List(ctx, task, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) != 2 || runs[0].ID != failed || *runs[0].Error != "boom" || runs[0].Summary != nil ||
+			runs[1].Status != RunSucceeded || *runs[1].Summary != "3 rows" {
+			t.Errorf("runs: %+v", runs)
+		}
+
+		latest, err := h.Latest(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if latest[task] == nil || latest[task].ID != failed {
+			t.Errorf("latest run of %s: %+v", task, latest[task])
+		}
+	})
+}
@@ -0,0 +1,307 @@
+// Package schedule runs recurring maintenance tasks on cron schedules. Every
+// replica runs a Scheduler, but only the one holding a Postgres advisory lock
+// runs tasks; the others keep trying to take the lock so a new leader
+// appears when the current one stops. Each run is recorded in a History.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Task is a named piece of recurring work. Run returns a short summary of
+// what it did, which is kept in the run history.
+type Task struct {
+	Name        string
+	Spec        string // cron expression, read in the scheduler's location
+	Description string
+	Run         func(ctx context.Context) (string, error)
+}
+
+// TaskStatus describes a registered task for the admin API
+type TaskStatus struct {
+	Name        string    `json:"name"`
+	Spec        string    `json:"spec"`
+	Description string    `json:"description"`
+	NextRun     time.Time `json:"next_run"`
+	Running     bool      `json:"running"`
+	LastRun     *Run      `json:"last_run"`
+}
+
+// entry is a registered task and its timing
+type entry struct {
+	Task
+	cron    *Cron
+	next    time.Time
+	running bool
+}
+
+// ============================================
+// SCHEDULER
+// ============================================
+
+type Scheduler struct {
+	Lock     Lock
+	History  History
+	Location *time.Location
+	Logger   *slog.Logger
+	// LeaderCheck is how often a follower tries to take the lock, and the
+	// longest the leader goes without checking it still holds it
+	LeaderCheck time.Duration
+
+	name string
+	now  func() time.Time
+
+	mu     sync.Mutex
+	tasks  []*entry
+	leader bool
+	// leaderCtx is cancelled by stop when leadership ends, taking the
+	// running tasks with it
+	leaderCtx context.Context
+	stop      context.CancelFunc
+	running   sync.WaitGroup
+}
+
+func New(lock Lock, history History, loc *time.Location, logger *slog.Logger) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		Lock:        lock,
+		History:     history,
+		Location:    loc,
+		Logger:      logger,
+		LeaderCheck: 15 * time.Second,
+		name:        fmt.Sprintf("%s-%d", host, os.Getpid()),
+		now:         time.Now,
+	}
+}
+
+// Add registers a task before Run is called. It fails when the spec does not
+// parse or the name is taken.
+func (s *Scheduler) Add(task Task) error {
+	cron, err := ParseCron(task.Spec)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", task.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.tasks {
+		if e.Name == task.Name {
+			return fmt.Errorf("task %s is already registered", task.Name)
+		}
+	}
+	s.tasks = append(s.tasks, &entry{Task: task, cron: cron})
+	return nil
+}
+
+// Instance names this process in the run history
+func (s *Scheduler) Instance() string {
+	return s.name
+}
+
+// IsLeader reports whether this process currently runs the tasks
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader
+}
+
+// Status lists the registered tasks with their next and most recent runs
+func (s *Scheduler) Status(ctx context.Context) ([]TaskStatus, error) {
+	latest, err := s.History.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().In(s.Location)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]TaskStatus, 0, len(s.tasks))
+	for _, e := range s.tasks {
+		tasks = append(tasks, TaskStatus{
+			Name:        e.Name,
+			Spec:        e.Spec,
+			Description: e.Description,
+			NextRun:     e.cron.Next(now),
+			Running:     e.running,
+			LastRun:     latest[e.Name],
+		})
+	}
+	return tasks, nil
+}
+
+// Run competes for leadership and, while leading, runs tasks as they fall
+// due. It blocks until ctx is cancelled, then cancels running tasks, waits
+// for them and releases the lock. Runs that fall due while no replica leads
+// are skipped rather than made up.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.Logger.Info("scheduler starting", "instance", s.name, "tasks", len(s.tasks), "timezone", s.Location.String())
+
+	for {
+		wait := s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			s.stepDown()
+			if err := s.Lock.Release(context.Background()); err != nil {
+				s.Logger.Error("releasing scheduler lock failed", "error", err)
+			}
+			s.Logger.Info("scheduler stopped")
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// tick confirms or seeks leadership, starts any due tasks and returns how
+// long to wait before the next tick
+func (s *Scheduler) tick(ctx context.Context) time.Duration {
+	if !s.lead(ctx) {
+		return s.LeaderCheck
+	}
+
+	now := s.now().In(s.Location)
+	wait := s.LeaderCheck
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.tasks {
+		if e.next.IsZero() {
+			continue // nothing matches, e.g. February 30th
+		}
+		if !now.Before(e.next) {
+			s.start(e)
+			e.next = e.cron.Next(now)
+		}
+		if d := e.next.Sub(now); !e.next.IsZero() && d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// lead reports whether this process leads, taking the lock if it is free
+func (s *Scheduler) lead(ctx context.Context) bool {
+	if s.IsLeader() {
+		err := s.Lock.Check(ctx)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		s.Logger.Warn("scheduler leadership lost", "error", err)
+		s.stepDown()
+	}
+
+	acquired, err := s.Lock.TryAcquire(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.Logger.Error("taking scheduler lock failed", "error", err)
+		}
+		return false
+	}
+	if !acquired {
+		return false
+	}
+
+	// Runs still marked running were started by a leader that is gone
+	if n, err := s.History.Abandon(ctx); err != nil {
+		s.Logger.Error("closing abandoned runs failed", "error", err)
+	} else if n > 0 {
+		s.Logger.Warn("closed runs left by a previous leader", "runs", n)
+	}
+
+	now := s.now().In(s.Location)
+	leaderCtx, stop := context.WithCancel(ctx)
+
+	s.mu.Lock()
+	s.leader = true
+	s.stop = stop
+	s.leaderCtx = leaderCtx
+	for _, e := range s.tasks {
+		e.next = e.cron.Next(now)
+	}
+	s.mu.Unlock()
+
+	s.Logger.Info("scheduler leadership acquired", "instance", s.name)
+	return true
+}
+
+// stepDown cancels the leader's running tasks and waits for them to return
+func (s *Scheduler) stepDown() {
+	s.mu.Lock()
+	if s.stop != nil {
+		s.stop()
+	}
+	s.leader = false
+	s.stop = nil
+	s.mu.Unlock()
+
+	s.running.Wait()
+}
+
+// start runs a due task in the background unless its previous run is still
+// going. The caller holds s.mu.
+func (s *Scheduler) start(e *entry) {
+	if e.running {
+		s.Logger.Warn("scheduled task skipped; its previous run has not finished", "task", e.Name)
+		return
+	}
+
+	e.running = true
+	s.running.Add(1)
+	go func(ctx context.Context) {
+		defer s.running.Done()
+		s.runTask(ctx, e)
+
+		s.mu.Lock()
+		e.running = false
+		s.mu.Unlock()
+	}(s.leaderCtx)
+}
+
+// runTask runs one task and records the run
+func (s *Scheduler) runTask(ctx context.Context, e *entry) {
+	log := s.Logger.With("task", e.Name)
+	// Recording the outcome must not be skipped because the run was cancelled
+	store := context.WithoutCancel(ctx)
+
+	id, err := s.History.Start(store, e.Name, s.name)
+	if err != nil {
+		log.Error("recording task start failed", "error", err)
+	}
+
+	start := time.Now()
+	summary, runErr := s.call(ctx, e)
+	log = log.With("duration_ms", time.Since(start).Milliseconds())
+
+	if runErr != nil {
+		log.Error("scheduled task failed", "error", runErr)
+	} else {
+		log.Info("scheduled task finished", "summary", summary)
+	}
+
+	if id != 0 {
+		if err := s.History.Finish(store, id, summary, runErr); err != nil {
+			log.Error("recording task outcome failed", "error", err)
+		}
+	}
+}
+
+// call runs the task, turning a panic into an error
+func (s *Scheduler) call(ctx context.Context, e *entry) (summary string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			s.Logger.Error("scheduled task panicked", "task", e.Name, "panic", p, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	return e.Run(ctx)
+}
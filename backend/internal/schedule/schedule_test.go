@@ -0,0 +1,189 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLock is free unless another replica is marked as holding it
+type fakeLock struct {
+	mu     sync.Mutex
+	held   bool
+	others bool
+}
+
+func (l *fakeLock) TryAcquire(context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.others {
+		return false, nil
+	}
+	l.held = true
+	return true, nil
+}
+
+func (l *fakeLock) Check(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.held {
+		return errLockLost
+	}
+	return nil
+}
+
+func (l *fakeLock) Release(context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = false
+	return nil
+}
+
+// takeOver hands the lock to another replica, as if this one's session had died
+func (l *fakeLock) takeOver() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held, l.others = false, true
+}
+
+// newTestScheduler returns a scheduler on a fake clock starting at 10:17:30 UTC
+func newTestScheduler(lock Lock) (*Scheduler, *MemoryHistory, *time.Time) {
+	history := NewMemoryHistory()
+	s := New(lock, history, time.UTC, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2026, 3, 4, 10, 17, 30, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, history, &now
+}
+
+func runs(t *testing.T, h *MemoryHistory, task string) []*Run {
+	t.Helper()
+	list, err := h.List(context.Background(), task, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+func TestSchedulerRunsDueTasks(t *testing.T) {
+	s, history, now := newTestScheduler(&fakeLock{})
+	s.LeaderCheck = time.Minute
+	ctx := context.Background()
+
+	s.Add(Task{Name: "ok", Spec: "* * * * *", Run: func(context.Context) (string, error) { return "3 rows", nil }})
+	s.Add(Task{Name: "fails", Spec: "* * * * *", Run: func(context.Context) (string, error) { return "", errors.New("database is down") }})
+	s.Add(Task{Name: "panics", Spec: "* * * * *", Run: func(context.Context) (string, error) { panic("nil map") }})
+	s.Add(Task{Name: "daily", Spec: "@daily", Run: func(context.Context) (string, error) { return "", nil }})
+
+	if err := s.Add(Task{Name: "ok", Spec: "@hourly"}); err == nil {
+		t.Error("adding a second task called ok should fail")
+	}
+	if err := s.Add(Task{Name: "bad", Spec: "every minute"}); err == nil {
+		t.Error("adding a task with an invalid spec should fail")
+	}
+
+	// Taking the lock schedules the first runs for 10:18; nothing is due yet
+	if wait := s.tick(ctx); wait != 30*time.Second {
+		t.Errorf("first tick waits %v, want 30s until 10:18", wait)
+	}
+	if !s.IsLeader() {
+		t.Fatal("scheduler did not take the free lock")
+	}
+
+	*now = now.Add(30 * time.Second)
+	s.tick(ctx)
+	s.running.Wait()
+
+	if got := runs(t, history, "ok"); len(got) != 1 || got[0].Status != RunSucceeded || *got[0].Summary != "3 rows" {
+		t.Errorf("ok runs: %+v", got)
+	}
+	if got := runs(t, history, "fails"); len(got) != 1 || got[0].Status != RunFailed || *got[0].Error != "database is down" {
+		t.Errorf("fails runs: %+v", got)
+	}
+	if got := runs(t, history, "panics"); len(got) != 1 || got[0].Status != RunFailed || *got[0].Error != "panic: nil map" {
+		t.Errorf("panics runs: %+v", got)
+	}
+	if got := runs(t, history, "daily"); len(got) != 0 {
+		t.Errorf("daily task ran at 10:18: %+v", got)
+	}
+
+	status, err := s.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != 4 || status[0].LastRun == nil || !status[3].NextRun.Equal(time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("status: %+v", status)
+	}
+}
+
+func TestSchedulerSkipsOverlappingRuns(t *testing.T) {
+	s, history, now := newTestScheduler(&fakeLock{})
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	s.Add(Task{Name: "slow", Spec: "* * * * *", Run: func(context.Context) (string, error) {
+		<-release
+		return "", nil
+	}})
+
+	s.tick(ctx)
+	for range 2 {
+		*now = now.Add(time.Minute)
+		s.tick(ctx)
+	}
+	close(release)
+	s.running.Wait()
+
+	if got := runs(t, history, "slow"); len(got) != 1 {
+		t.Errorf("got %d runs, want 1 while the first was still going", len(got))
+	}
+}
+
+func TestSchedulerLeadership(t *testing.T) {
+	lock := &fakeLock{others: true}
+	s, history, now := newTestScheduler(lock)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	s.Add(Task{Name: "long", Spec: "* * * * *", Run: func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}})
+
+	// A run left behind by a leader that crashed
+	history.Start(ctx, "long", "crashed-replica")
+
+	// Another replica leads, so nothing runs here
+	*now = now.Add(time.Minute)
+	if wait := s.tick(ctx); wait != s.LeaderCheck || s.IsLeader() {
+		t.Fatalf("follower tick: wait %v, leader %v", wait, s.IsLeader())
+	}
+
+	// The lock comes free: this replica leads and closes the crashed run
+	lock.mu.Lock()
+	lock.others = false
+	lock.mu.Unlock()
+	s.tick(ctx)
+	if got := runs(t, history, "long"); len(got) != 1 || got[0].Status != RunFailed {
+		t.Errorf("abandoned run: %+v", got)
+	}
+
+	*now = now.Add(time.Minute)
+	s.tick(ctx)
+	<-started
+
+	// Losing the lock cancels the running task before anything else runs
+	lock.takeOver()
+	s.tick(ctx)
+	if s.IsLeader() {
+		t.Error("scheduler still leads after losing the lock")
+	}
+	got := runs(t, history, "long")
+	if len(got) != 2 || got[0].Status != RunFailed || *got[0].Error != context.Canceled.Error() {
+		t.Errorf("interrupted run: %+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+// Package validator collects per-field errors for request bodies. Handlers
+// describe each field with a rule method and hand the resulting map to the
+// 422 response, so a client learns about every bad field in one round trip.
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// MobileRX matches a ten digit Indian mobile number with an optional
+	// +91 or 0 prefix
+	MobileRX = regexp.MustCompile(`^(\+91[ -]?|0)?[6-9][0-9]{9}$`)
+	// PhoneRX is looser for company lines, which may be landlines or abroad
+	PhoneRX   = regexp.MustCompile(`^\+?[0-9][0-9 -]{5,18}[0-9]$`)
+	PincodeRX = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+	AadhaarRX = regexp.MustCompile(`^[2-9][0-9]{11}$`)
+	EmailRX   = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+)
+
+// ============================================
+// VALIDATOR
+// ============================================
+
+// Validator maps JSON field names to the first problem found with each
+type Validator struct {
+	Errors map[string]string
+}
+
+func New() *Validator {
+	return &Validator{Errors: make(map[string]string)}
+}
+
+// Valid reports whether no rule has failed
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// AddError records message for key unless the key already has an error
+func (v *Validator) AddError(key, message string) {
+	if _, exists := v.Errors[key]; !exists {
+		v.Errors[key] = message
+	}
+}
+
+// Check adds message for key when ok is false. It is the escape hatch for
+// cross-field rules the typed methods below do not cover.
+func (v *Validator) Check(ok bool, key, message string) {
+	if !ok {
+		v.AddError(key, message)
+	}
+}
+
+// ============================================
+// FIELD RULES
+// ============================================
+//
+// The rules take pointers because most inputs are partial updates: a nil
+// field is left alone, and so is a blank string since HTML forms send "" for
+// every untouched input. Use Required for the fields that must be present.
+
+// Required rejects a missing or blank value
+func (v *Validator) Required(key string, value *string) {
+	v.Check(!Blank(value), key, "must be provided")
+}
+
+// MaxLength caps a string at n characters, matching its VARCHAR column
+func (v *Validator) MaxLength(key string, value *string, n int) {
+	if Blank(value) {
+		return
+	}
+	v.Check(utf8.RuneCountInString(*value) <= n, key, fmt.Sprintf("must not be more than %d characters", n))
+}
+
+// OneOf restricts a string to the permitted values, e.g. a Postgres enum
+func (v *Validator) OneOf(key string, value *string, permitted ...string) {
+	if Blank(value) {
+		return
+	}
+	v.Check(slices.Contains(permitted, *value), key, "must be one of "+strings.Join(permitted, ", "))
+}
+
+// Matches checks a string against rx, reporting message on a mismatch
+func (v *Validator) Matches(key string, value *string, rx *regexp.Regexp, message string) {
+	if Blank(value) {
+		return
+	}
+	v.Check(rx.MatchString(*value), key, message)
+}
+
+// Email checks the shape of an email address
+func (v *Validator) Email(key string, value *string) {
+	v.Matches(key, value, EmailRX, "must be a valid email address")
+	v.MaxLength(key, value, 255)
+}
+
+// URL accepts absolute http and https URLs only
+func (v *Validator) URL(key string, value *string) {
+	if Blank(value) {
+		return
+	}
+	v.Check(IsURL(*value), key, "must be a valid http or https URL")
+}
+
+// Date accepts a calendar date in YYYY-MM-DD form
+func (v *Validator) Date(key string, value *string) {
+	if Blank(value) {
+		return
+	}
+	_, err := time.Parse(time.DateOnly, *value)
+	v.Check(err == nil, key, "must be a date in YYYY-MM-DD form")
+}
+
+// IntRange bounds an integer, inclusive at both ends
+func (v *Validator) IntRange(key string, value *int, min, max int) {
+	if value == nil {
+		return
+	}
+	v.Check(*value >= min && *value <= max, key, fmt.Sprintf("must be between %d and %d", min, max))
+}
+
+// FloatRange bounds a number, inclusive at both ends
+func (v *Validator) FloatRange(key string, value *float64, min, max float64) {
+	if value == nil {
+		return
+	}
+	v.Check(*value >= min && *value <= max, key, fmt.Sprintf("must be between %g and %g", min, max))
+}
+
+// ============================================
+// PREDICATES
+// ============================================
+
+// Blank reports whether an optional string is missing or only whitespace
+func Blank(value *string) bool {
+	return value == nil || strings.TrimSpace(*value) == ""
+}
+
+// IsURL reports whether value is an absolute http or https URL with a host
+func IsURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
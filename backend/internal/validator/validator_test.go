@@ -0,0 +1,75 @@
+package validator
+
+import "testing"
+
+func ptr(s string) *string { return &s }
+
+func TestFieldRules(t *testing.T) {
+	v := New()
+	v.MaxLength("name", ptr("abcdef"), 5)
+	v.OneOf("gender", ptr("unknown"), "male", "female", "other")
+	v.Matches("mobile", ptr("12345"), MobileRX, "bad mobile")
+	v.URL("site", ptr("javascript:alert(1)"))
+	v.Date("dob", ptr("2001-02-30"))
+	v.FloatRange("cgpa", func() *float64 { f := 10.5; return &f }(), 0, 10)
+	v.IntRange("backlogs", func() *int { n := -1; return &n }(), 0, 100)
+
+	for _, key := range []string{"name", "gender", "mobile", "site", "dob", "cgpa", "backlogs"} {
+		if _, ok := v.Errors[key]; !ok {
+			t.Errorf("%s: expected an error", key)
+		}
+	}
+
+	// The first failure for a key is the one reported
+	v.AddError("name", "second")
+	if v.Errors["name"] == "second" {
+		t.Error("AddError replaced an existing message")
+	}
+}
+
+func TestFieldRulesSkipMissingValues(t *testing.T) {
+	v := New()
+	v.MaxLength("name", nil, 5)
+	v.OneOf("gender", ptr(""), "male")
+	v.Matches("mobile", ptr("  "), MobileRX, "bad mobile")
+	v.URL("site", nil)
+	v.Date("dob", ptr(""))
+	v.FloatRange("cgpa", nil, 0, 10)
+	v.IntRange("backlogs", nil, 0, 100)
+
+	if !v.Valid() {
+		t.Errorf("missing values were rejected: %v", v.Errors)
+	}
+
+	v.Required("name", ptr(" "))
+	if v.Valid() {
+		t.Error("Required accepted a blank value")
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(string) bool
+		good  []string
+		bad   []string
+	}{
+		{"mobile", MobileRX.MatchString, []string{"9876543210", "+91 9876543210", "09876543210"}, []string{"1234567890", "98765", "98765432101"}},
+		{"pincode", PincodeRX.MatchString, []string{"600001"}, []string{"060001", "60001", "6000011"}},
+		{"aadhaar", AadhaarRX.MatchString, []string{"234567890123"}, []string{"123456789012", "23456789012"}},
+		{"email", EmailRX.MatchString, []string{"a.b@example.co.in"}, []string{"a@", "a@b", "@example.com"}},
+		{"url", IsURL, []string{"https://github.com/someone"}, []string{"github.com/someone", "ftp://example.com", "https://"}},
+	}
+	for _, tt := range tests {
+		for _, s := range tt.good {
+			if !tt.check(s) {
+				t.Errorf("%s: %q was rejected", tt.name, s)
+			}
+		}
+		for _, s := range tt.bad {
+			if tt.check(s) {
+				t.Errorf("%s: %q was accepted", tt.name, s)
+			}
+		}
+	}
+}
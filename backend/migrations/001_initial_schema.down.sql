@@ -0,0 +1,28 @@
+-- Drops the whole application schema and every row in it.
+-- Only useful on a scratch database; take a backup before running it anywhere else.
+
+DROP VIEW IF EXISTS v_placement_stats;
+DROP VIEW IF EXISTS v_student_full_profile;
+
+DROP TABLE IF EXISTS activity_logs;
+DROP TABLE IF EXISTS student_resumes;
+DROP TABLE IF EXISTS placements;
+DROP TABLE IF EXISTS companies;
+DROP TABLE IF EXISTS student_aspirations;
+DROP TABLE IF EXISTS student_achievements;
+DROP TABLE IF EXISTS student_skills;
+DROP TABLE IF EXISTS skills;
+DROP TABLE IF EXISTS student_academics;
+DROP TABLE IF EXISTS student_family_details;
+DROP TABLE IF EXISTS student_personal_details;
+DROP TABLE IF EXISTS students;
+DROP TABLE IF EXISTS admins;
+DROP TABLE IF EXISTS batches;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TYPE IF EXISTS gender;
+DROP TYPE IF EXISTS residence_type;
+DROP TYPE IF EXISTS skill_category;
+DROP TYPE IF EXISTS proficiency_level;
+DROP TYPE IF EXISTS placement_status;
@@ -0,0 +1,2 @@
+-- Nothing to undo: the constraint this migration adds to older databases is
+-- part of student_achievements as created by 001_initial_schema.sql
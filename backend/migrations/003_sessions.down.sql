@@ -0,0 +1,3 @@
+-- Signs everyone out; refresh tokens have nothing left to rotate against
+
+DROP TABLE IF EXISTS sessions;
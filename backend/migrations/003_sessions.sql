@@ -0,0 +1,24 @@
+-- Sessions: server-side record of every login so tokens can be revoked
+-- Each session holds the hash of its current refresh token. Refreshing
+-- rotates the token; presenting a rotated-out token revokes the session.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_type VARCHAR(20) NOT NULL, -- 'student' or 'admin'
+    user_id INTEGER NOT NULL,
+
+    refresh_token_hash BYTEA NOT NULL UNIQUE,
+    previous_token_hash BYTEA,
+
+    user_agent TEXT,
+    ip_address VARCHAR(64),
+
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_type, user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token ON sessions(previous_token_hash);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires ON sessions(expires_at);
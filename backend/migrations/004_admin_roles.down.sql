@@ -0,0 +1,8 @@
+-- Every admin gets back full write access once roles are gone
+
+DROP INDEX IF EXISTS idx_students_department;
+ALTER TABLE students DROP COLUMN IF EXISTS department;
+
+ALTER TABLE admins DROP CONSTRAINT IF EXISTS admins_role_check;
+ALTER TABLE admins DROP COLUMN IF EXISTS department;
+ALTER TABLE admins DROP COLUMN IF EXISTS role;
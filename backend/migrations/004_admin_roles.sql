@@ -0,0 +1,36 @@
+-- Role-based permissions for admins
+-- `designation` stays a free-text display title; `role` drives authorization.
+-- Department coordinators only see students of their own department.
+
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS role VARCHAR(50);
+ALTER TABLE admins ADD COLUMN IF NOT EXISTS department VARCHAR(100);
+
+-- Existing admins had full write access; keep that as placement coordinators
+-- and make the placement officer the super-admin.
+UPDATE admins
+SET role = CASE WHEN designation = 'Placement Officer' THEN 'super_admin' ELSE 'placement_coordinator' END
+WHERE role IS NULL;
+
+ALTER TABLE admins ALTER COLUMN role SET DEFAULT 'faculty_viewer';
+ALTER TABLE admins ALTER COLUMN role SET NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'admins_role_check'
+    ) THEN
+        ALTER TABLE admins ADD CONSTRAINT admins_role_check CHECK (
+            role IN ('super_admin', 'placement_coordinator', 'department_coordinator', 'faculty_viewer')
+        );
+    END IF;
+END $$;
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS department VARCHAR(100);
+
+CREATE INDEX IF NOT EXISTS idx_students_department ON students(department);
+
+-- Make sure at least one super-admin exists
+UPDATE admins SET role = 'super_admin'
+WHERE id = (SELECT MIN(id) FROM admins WHERE is_active = true)
+  AND NOT EXISTS (SELECT 1 FROM admins WHERE role = 'super_admin' AND is_active = true);
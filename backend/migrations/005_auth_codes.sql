@@ -0,0 +1,14 @@
+-- One-time login codes
+-- The OAuth callback redirects to the frontend with a short-lived code
+-- instead of the tokens themselves; the frontend exchanges it once via
+-- POST /auth/exchange. Only the hash of the code is stored.
+
+CREATE TABLE IF NOT EXISTS auth_codes (
+    code_hash BYTEA PRIMARY KEY,
+    user_type VARCHAR(20) NOT NULL, -- 'student' or 'admin'
+    user_id INTEGER NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_codes_expires ON auth_codes(expires_at);
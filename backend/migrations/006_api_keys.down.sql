@@ -0,0 +1,3 @@
+-- Revokes every API key; service accounts need new keys if this is re-applied
+
+DROP TABLE IF EXISTS api_keys;
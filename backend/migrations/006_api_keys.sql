@@ -0,0 +1,23 @@
+-- API keys for service accounts such as the college ERP
+-- Keys are shown once on creation; only a SHA-256 hash is stored. The
+-- prefix is kept in clear so admins can tell keys apart. Scopes are the
+-- same permission strings admin roles use (e.g. "students:read").
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash BYTEA NOT NULL UNIQUE,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    rate_limit_per_minute INTEGER NOT NULL DEFAULT 60 CHECK (rate_limit_per_minute > 0),
+
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(64),
+
+    created_by INTEGER REFERENCES admins(id) ON DELETE SET NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_created ON api_keys(created_at);
@@ -0,0 +1,30 @@
+-- Folds every institution back into one. The global uniqueness constraints
+-- cannot be restored while two institutions share a batch year, skill name,
+-- roll number or register number; remove the duplicates first.
+
+ALTER TABLE admins DROP CONSTRAINT IF EXISTS admins_role_check;
+UPDATE admins SET role = 'super_admin' WHERE role = 'group_admin';
+ALTER TABLE admins ADD CONSTRAINT admins_role_check CHECK (
+    role IN ('super_admin', 'placement_coordinator', 'department_coordinator', 'faculty_viewer')
+);
+
+ALTER TABLE batches DROP CONSTRAINT IF EXISTS batches_institution_year_key;
+ALTER TABLE skills DROP CONSTRAINT IF EXISTS skills_institution_name_key;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_institution_roll_no_key;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_institution_register_no_key;
+
+ALTER TABLE batches ADD CONSTRAINT batches_year_key UNIQUE (year);
+ALTER TABLE skills ADD CONSTRAINT skills_name_key UNIQUE (name);
+ALTER TABLE students ADD CONSTRAINT students_roll_no_key UNIQUE (roll_no);
+ALTER TABLE students ADD CONSTRAINT students_register_no_key UNIQUE (register_no);
+
+ALTER TABLE activity_logs DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE companies DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE skills DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE students DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE admins DROP COLUMN IF EXISTS institution_id;
+ALTER TABLE batches DROP COLUMN IF EXISTS institution_id;
+
+DROP TABLE IF EXISTS institution_domains;
+DROP TABLE IF EXISTS institutions;
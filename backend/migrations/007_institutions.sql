@@ -0,0 +1,94 @@
+-- Multi-institution tenancy
+-- Every college in the group is an institution with its own students, admins,
+-- batches, skills, companies and API keys. Students are assigned to an
+-- institution from their email domain when they first sign in.
+-- Existing data is moved to the seeded KCT institution. If ALLOWED_DOMAIN was
+-- not kct.ac.in, update institution_domains after running this migration.
+
+CREATE TABLE IF NOT EXISTS institutions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+
+    -- Branding shown in the frontend
+    logo_url TEXT,
+    primary_color VARCHAR(20),
+
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Email domains that sign in to each institution; a domain belongs to one institution
+CREATE TABLE IF NOT EXISTS institution_domains (
+    domain VARCHAR(255) PRIMARY KEY,
+    institution_id INTEGER NOT NULL REFERENCES institutions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_institution_domains_institution ON institution_domains(institution_id);
+
+INSERT INTO institutions (code, name)
+VALUES ('kct', 'Kumaraguru College of Technology')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO institution_domains (domain, institution_id)
+SELECT 'kct.ac.in', id FROM institutions WHERE code = 'kct'
+ON CONFLICT (domain) DO NOTHING;
+
+-- ============================================
+-- TENANT COLUMNS
+-- ============================================
+
+DO $$
+DECLARE
+    tbl TEXT;
+    default_id INTEGER;
+BEGIN
+    SELECT id INTO default_id FROM institutions WHERE code = 'kct';
+
+    FOREACH tbl IN ARRAY ARRAY['batches', 'admins', 'students', 'skills', 'companies', 'api_keys'] LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS institution_id INTEGER REFERENCES institutions(id)', tbl);
+        EXECUTE format('UPDATE %I SET institution_id = $1 WHERE institution_id IS NULL', tbl) USING default_id;
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN institution_id SET NOT NULL', tbl);
+        EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(institution_id)', 'idx_' || tbl || '_institution', tbl);
+    END LOOP;
+END $$;
+
+-- Audit entries keep the institution they happened in; NULL for group-level actions
+ALTER TABLE activity_logs ADD COLUMN IF NOT EXISTS institution_id INTEGER REFERENCES institutions(id);
+CREATE INDEX IF NOT EXISTS idx_activity_logs_institution ON activity_logs(institution_id, created_at);
+
+-- ============================================
+-- PER-INSTITUTION UNIQUENESS
+-- ============================================
+
+ALTER TABLE batches DROP CONSTRAINT IF EXISTS batches_year_key;
+ALTER TABLE skills DROP CONSTRAINT IF EXISTS skills_name_key;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_roll_no_key;
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_register_no_key;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'batches_institution_year_key') THEN
+        ALTER TABLE batches ADD CONSTRAINT batches_institution_year_key UNIQUE (institution_id, year);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'skills_institution_name_key') THEN
+        ALTER TABLE skills ADD CONSTRAINT skills_institution_name_key UNIQUE (institution_id, name);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'students_institution_roll_no_key') THEN
+        ALTER TABLE students ADD CONSTRAINT students_institution_roll_no_key UNIQUE (institution_id, roll_no);
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'students_institution_register_no_key') THEN
+        ALTER TABLE students ADD CONSTRAINT students_institution_register_no_key UNIQUE (institution_id, register_no);
+    END IF;
+END $$;
+
+-- ============================================
+-- GROUP ADMINS
+-- ============================================
+
+-- Group admins manage institutions and report across all of them
+ALTER TABLE admins DROP CONSTRAINT IF EXISTS admins_role_check;
+ALTER TABLE admins ADD CONSTRAINT admins_role_check CHECK (
+    role IN ('group_admin', 'super_admin', 'placement_coordinator', 'department_coordinator', 'faculty_viewer')
+);
@@ -0,0 +1,3 @@
+-- Switch RATE_LIMIT_BACKEND back to memory before reverting this
+
+DROP TABLE IF EXISTS rate_limit_buckets;
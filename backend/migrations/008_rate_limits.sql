@@ -0,0 +1,11 @@
+-- Token buckets shared by every API instance when RATE_LIMIT_BACKEND=postgres
+-- Rows are rewritten on each request and can be deleted at any time; a
+-- missing bucket simply starts full again.
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
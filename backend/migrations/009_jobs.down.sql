@@ -0,0 +1,3 @@
+-- Queued and dead jobs are lost; let the workers drain the queue first
+
+DROP TABLE IF EXISTS jobs;
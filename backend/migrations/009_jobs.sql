@@ -0,0 +1,36 @@
+-- Background job queue
+-- Workers claim due jobs with SELECT ... FOR UPDATE SKIP LOCKED, so every
+-- replica can poll this table without two of them taking the same job. A
+-- job still running when its lease runs out (locked_at older than JOB_LEASE)
+-- is assumed lost with its worker and is claimed again. Jobs that use up
+-- max_attempts are kept as 'dead' until an admin retries them.
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    institution_id INTEGER REFERENCES institutions(id) ON DELETE CASCADE,
+    kind VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    result JSONB,
+
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'running', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5 CHECK (max_attempts > 0),
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_by VARCHAR(255),
+    locked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+
+    -- Who enqueued the job: an admin, an API key or the system
+    created_by_type VARCHAR(20),
+    created_by_id BIGINT,
+
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Due jobs, in the order workers take them
+CREATE INDEX IF NOT EXISTS idx_jobs_due ON jobs(run_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs(locked_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_institution ON jobs(institution_id, created_at);
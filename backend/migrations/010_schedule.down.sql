@@ -0,0 +1,4 @@
+-- Run history and dashboard snapshots are lost
+
+DROP TABLE IF EXISTS dashboard_snapshots;
+DROP TABLE IF EXISTS schedule_runs;
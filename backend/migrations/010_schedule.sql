@@ -0,0 +1,29 @@
+-- Scheduled maintenance
+-- The scheduler runs on every replica, but only the one holding a Postgres
+-- advisory lock runs tasks. Each run is recorded in schedule_runs so admins
+-- can see what ran, where and how it ended.
+
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    id BIGSERIAL PRIMARY KEY,
+    task VARCHAR(100) NOT NULL,
+    -- Host and process of the replica that ran the task
+    instance VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'succeeded', 'failed')),
+    summary TEXT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_task ON schedule_runs(task, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_schedule_runs_started ON schedule_runs(started_at DESC);
+
+-- One copy of the dashboard stats per institution per day, for trend charts
+CREATE TABLE IF NOT EXISTS dashboard_snapshots (
+    institution_id INTEGER NOT NULL REFERENCES institutions(id) ON DELETE CASCADE,
+    taken_on DATE NOT NULL,
+    stats JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (institution_id, taken_on)
+);
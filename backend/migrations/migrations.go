@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL schema migrations so the api binary can
+// apply them itself. NNN_name.sql moves the schema up to version NNN and the
+// optional NNN_name.down.sql reverts it.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
@@ -3,36 +3,42 @@
 // ============================================
 
 // Handle OAuth callback parameters
-function handleAuthCallback() {
+// The backend redirects with a one-time code, which is exchanged for the session
+async function handleAuthCallback() {
     const params = new URLSearchParams(window.location.search);
 
-    // Check for successful auth callback
-    if (params.has('token')) {
-        const token = params.get('token');
-        const role = params.get('role') || 'student';
-        const name = decodeURIComponent(params.get('name') || '');
-        const email = decodeURIComponent(params.get('email') || '');
-        const userId = params.get('user_id');
-
-        // Store credentials
-        localStorage.setItem('token', token);
-        localStorage.setItem('user', JSON.stringify({
-            id: userId,
-            name: name,
-            email: email,
-            role: role
-        }));
-
-        // Clear URL params
+    if (params.has('code')) {
+        const code = params.get('code');
+
+        // Drop the code from the address bar and history straight away
         window.history.replaceState({}, document.title, window.location.pathname);
 
-        // Redirect based on role
-        if (role === 'admin') {
-            window.location.href = 'admin-dashboard.html';
-        } else {
-            window.location.href = 'profile.html';
+        try {
+            const response = await fetch(`${API_BASE_URL}/auth/exchange`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ code })
+            });
+            const result = await response.json();
+            if (!response.ok) {
+                return { error: result.error || 'Login failed. Please try again.' };
+            }
+
+            const role = result.role || 'student';
+            localStorage.setItem('token', result.token);
+            localStorage.setItem('refresh_token', result.refresh_token);
+            localStorage.setItem('user', JSON.stringify({ role: role }));
+
+            // Redirect based on role
+            if (role === 'admin') {
+                window.location.href = 'admin-dashboard.html';
+            } else {
+                window.location.href = 'profile.html';
+            }
+            return true;
+        } catch (error) {
+            return { error: 'Login failed. Please try again.' };
         }
-        return true;
     }
 
     // Check for error
@@ -47,15 +53,38 @@ function handleAuthCallback() {
 }
 
 // Initiate login flow
-function login(role = 'student') {
-    window.location.href = `${API_BASE_URL}/auth/login?role=${role}`;
+function login(role = 'student', provider = 'microsoft') {
+    window.location.href = `${API_BASE_URL}/auth/login/${encodeURIComponent(provider)}?role=${role}`;
+}
+
+// Add a sign-in button for every provider besides Microsoft
+async function loadLoginProviders(containerId) {
+    const container = document.getElementById(containerId);
+    if (!container) return;
+
+    try {
+        const response = await fetch(`${API_BASE_URL}/auth/providers`);
+        if (!response.ok) return;
+
+        const data = await response.json();
+        (data.providers || [])
+            .filter(p => p.name !== 'microsoft')
+            .forEach(p => {
+                const button = document.createElement('button');
+                button.className = 'btn-hover w-full flex items-center justify-center gap-3 bg-white hover:bg-gray-50 text-gray-800 border border-gray-200 py-3 px-6 rounded-xl font-semibold';
+                button.textContent = `Sign in with ${p.name.charAt(0).toUpperCase()}${p.name.slice(1)}`;
+                button.title = (p.allowed_domains || []).map(d => `@${d}`).join(', ');
+                button.onclick = () => login('student', p.name);
+                container.appendChild(button);
+            });
+    } catch (error) {
+        console.error('Failed to load sign-in providers:', error);
+    }
 }
 
 // Logout user
 function logout() {
-    localStorage.removeItem('token');
-    localStorage.removeItem('user');
-    window.location.href = 'index.html';
+    return utils.logout();
 }
 
 // Verify token is still valid
@@ -73,9 +102,12 @@ async function verifyAuth() {
             return data.user;
         }
 
-        // Token invalid
-        localStorage.removeItem('token');
-        localStorage.removeItem('user');
+        // Token expired - try the refresh token before giving up
+        if (response.status === 401 && await api.refreshSession()) {
+            return verifyAuth();
+        }
+
+        utils.clearSession();
         return null;
     } catch (error) {
         console.error('Auth verification failed:', error);
@@ -17,7 +17,7 @@ const api = {
         };
     },
 
-    async request(method, endpoint, data = null) {
+    async request(method, endpoint, data = null, retried = false) {
         const options = {
             method,
             headers: this.getHeaders()
@@ -30,8 +30,11 @@ const api = {
         const response = await fetch(`${this.baseUrl}${endpoint}`, options);
 
         if (response.status === 401) {
-            localStorage.removeItem('token');
-            localStorage.removeItem('user');
+            // Access tokens are short-lived - try once to refresh before giving up
+            if (!retried && await this.refreshSession()) {
+                return this.request(method, endpoint, data, true);
+            }
+            utils.clearSession();
             window.location.href = 'index.html';
             throw new Error('Unauthorized');
         }
@@ -45,6 +48,28 @@ const api = {
         return result;
     },
 
+    // Exchange the stored refresh token for a new token pair
+    async refreshSession() {
+        const refreshToken = localStorage.getItem('refresh_token');
+        if (!refreshToken) return false;
+
+        try {
+            const response = await fetch(`${this.baseUrl}/auth/refresh`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ refresh_token: refreshToken })
+            });
+            if (!response.ok) return false;
+
+            const result = await response.json();
+            localStorage.setItem('token', result.token);
+            localStorage.setItem('refresh_token', result.refresh_token);
+            return true;
+        } catch (error) {
+            return false;
+        }
+    },
+
     get: function(endpoint) { return this.request('GET', endpoint); },
     post: function(endpoint, data) { return this.request('POST', endpoint, data); },
     put: function(endpoint, data) { return this.request('PUT', endpoint, data); },
@@ -85,9 +110,22 @@ const utils = {
         return !!localStorage.getItem('token');
     },
 
-    logout() {
+    clearSession() {
         localStorage.removeItem('token');
+        localStorage.removeItem('refresh_token');
         localStorage.removeItem('user');
+    },
+
+    async logout() {
+        const token = localStorage.getItem('token');
+        if (token) {
+            // Revoke the session server-side; ignore failures so logout always works
+            await fetch(`${API_BASE_URL}/auth/logout`, {
+                method: 'POST',
+                headers: { 'Authorization': `Bearer ${token}` }
+            }).catch(() => {});
+        }
+        this.clearSession();
         window.location.href = 'index.html';
     },
 